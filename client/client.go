@@ -0,0 +1,457 @@
+// Package client is the Go client of the Open Asset Gateway. It wraps
+// every route of the REST API with typed methods, streams the events of
+// /listen, and batches writes into imports.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0ppliger/oam-broker/wire"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// Client calls the gateway at a base URL, such as
+// "https://localhost:443".
+type Client struct {
+	base   *url.URL
+	http   *http.Client
+	header http.Header
+}
+
+type Option func(c *Client)
+
+// WithHTTPClient sets the HTTP client used for requests, for instance to
+// trust the certificate of the gateway. http.DefaultClient otherwise.
+func WithHTTPClient(http_client *http.Client) Option {
+	return func(c *Client) {
+		c.http = http_client
+	}
+}
+
+// WithHeader adds a header to every request, such as credentials.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+func New(base_url string, opts ...Option) (*Client, error) {
+	base, err := url.Parse(strings.TrimSuffix(base_url, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid gateway URL: %s", base_url)
+	}
+
+	c := &Client{
+		base:   base,
+		http:   http.DefaultClient,
+		header: make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Error is a response of the gateway with an error status. The gateway
+// answers errors with a plain text message.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// request describes a call to the gateway.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        io.Reader
+	contentType string
+}
+
+// send performs the request, turning error statuses into an *Error. The
+// caller closes the body of the response.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	u := *c.base
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+
+	r, err := http.NewRequestWithContext(ctx, req.method, u.String(), req.body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.header {
+		r.Header[key] = values
+	}
+	for key, values := range req.header {
+		r.Header[key] = values
+	}
+	if req.contentType != "" {
+		r.Header.Set("Content-Type", req.contentType)
+	}
+
+	resp, err := c.http.Do(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return nil, &Error{resp.StatusCode, strings.TrimSpace(string(message))}
+	}
+	return resp, nil
+}
+
+// call performs the request and decodes the JSON response in out. It
+// returns the ETag of the response.
+func (c *Client) call(ctx context.Context, req request, out any) (string, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return "", err
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+func jsonBody(v any) (io.Reader, error) {
+	json_encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(json_encoded), nil
+}
+
+func ifMatchHeader(if_match string) http.Header {
+	if if_match == "" {
+		return nil
+	}
+	return http.Header{"If-Match": {if_match}}
+}
+
+// PatchOperation is an operation of a JSON Patch (RFC 6902).
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// JSONPatch is sent as a JSON Patch by the Patch methods. Any other
+// patch is sent as a JSON Merge Patch (RFC 7396).
+type JSONPatch []PatchOperation
+
+func get[T any](c *Client, ctx context.Context, route, id string) (T, string, error) {
+	var out T
+	etag, err := c.call(ctx, request{method: "GET", path: "/" + route + "/" + url.PathEscape(id)}, &out)
+	return out, etag, err
+}
+
+func create[T any](c *Client, ctx context.Context, route string, obj T) (T, string, error) {
+	var out T
+	body, err := jsonBody(obj)
+	if err != nil {
+		return out, "", err
+	}
+	etag, err := c.call(ctx, request{method: "POST", path: "/emit/" + route, body: body}, &out)
+	return out, etag, err
+}
+
+func update[T any](c *Client, ctx context.Context, route, id string, obj T, if_match string) (T, string, error) {
+	var out T
+	body, err := jsonBody(obj)
+	if err != nil {
+		return out, "", err
+	}
+	etag, err := c.call(ctx, request{
+		method: "PUT",
+		path:   "/emit/" + route + "/" + url.PathEscape(id),
+		header: ifMatchHeader(if_match),
+		body:   body,
+	}, &out)
+	return out, etag, err
+}
+
+func patch[T any](c *Client, ctx context.Context, route, id string, p any, if_match string) (T, string, error) {
+	var out T
+	body, err := jsonBody(p)
+	if err != nil {
+		return out, "", err
+	}
+	content_type := MergePatchType
+	if _, ok := p.(JSONPatch); ok {
+		content_type = JSONPatchType
+	}
+	etag, err := c.call(ctx, request{
+		method:      "PATCH",
+		path:        "/emit/" + route + "/" + url.PathEscape(id),
+		header:      ifMatchHeader(if_match),
+		body:        body,
+		contentType: content_type,
+	}, &out)
+	return out, etag, err
+}
+
+func remove[T any](c *Client, ctx context.Context, route, id string, if_match string, query url.Values) (T, error) {
+	var out T
+	_, err := c.call(ctx, request{
+		method: "DELETE",
+		path:   "/emit/" + route + "/" + url.PathEscape(id),
+		query:  query,
+		header: ifMatchHeader(if_match),
+	}, &out)
+	return out, err
+}
+
+// The get and write methods return the object as stored along with its
+// ETag, which can be given back as if_match to make the next write fail
+// with 412 if the object was modified in between. An empty if_match
+// always writes.
+
+func (c *Client) GetEntity(ctx context.Context, id string) (wire.Entity, string, error) {
+	return get[wire.Entity](c, ctx, "entity", id)
+}
+
+func (c *Client) CreateEntity(ctx context.Context, entity wire.Entity) (wire.Entity, string, error) {
+	return create(c, ctx, "entity", entity)
+}
+
+func (c *Client) UpdateEntity(ctx context.Context, id string, entity wire.Entity, if_match string) (wire.Entity, string, error) {
+	return update(c, ctx, "entity", id, entity, if_match)
+}
+
+func (c *Client) PatchEntity(ctx context.Context, id string, p any, if_match string) (wire.Entity, string, error) {
+	return patch[wire.Entity](c, ctx, "entity", id, p, if_match)
+}
+
+// DeleteEntity fails with 409 when the entity has edges: see
+// DeleteEntityCascade.
+func (c *Client) DeleteEntity(ctx context.Context, id string, if_match string) (wire.Entity, error) {
+	return remove[wire.Entity](c, ctx, "entity", id, if_match, nil)
+}
+
+// DeleteEntityCascade deletes an entity along with its edges and tags.
+func (c *Client) DeleteEntityCascade(ctx context.Context, id string, if_match string) (wire.Cascade, error) {
+	return remove[wire.Cascade](c, ctx, "entity", id, if_match, url.Values{"cascade": {"true"}})
+}
+
+// PreviewCascade lists what DeleteEntityCascade would delete.
+func (c *Client) PreviewCascade(ctx context.Context, id string) (wire.Cascade, error) {
+	return remove[wire.Cascade](c, ctx, "entity", id, "", url.Values{"cascade": {"preview"}})
+}
+
+func (c *Client) GetEdge(ctx context.Context, id string) (wire.Edge, string, error) {
+	return get[wire.Edge](c, ctx, "edge", id)
+}
+
+func (c *Client) CreateEdge(ctx context.Context, edge wire.Edge) (wire.Edge, string, error) {
+	return create(c, ctx, "edge", edge)
+}
+
+func (c *Client) UpdateEdge(ctx context.Context, id string, edge wire.Edge, if_match string) (wire.Edge, string, error) {
+	return update(c, ctx, "edge", id, edge, if_match)
+}
+
+func (c *Client) PatchEdge(ctx context.Context, id string, p any, if_match string) (wire.Edge, string, error) {
+	return patch[wire.Edge](c, ctx, "edge", id, p, if_match)
+}
+
+func (c *Client) DeleteEdge(ctx context.Context, id string, if_match string) (wire.Edge, error) {
+	return remove[wire.Edge](c, ctx, "edge", id, if_match, nil)
+}
+
+func (c *Client) GetEntityTag(ctx context.Context, id string) (wire.EntityTag, string, error) {
+	return get[wire.EntityTag](c, ctx, "entity_tag", id)
+}
+
+func (c *Client) CreateEntityTag(ctx context.Context, tag wire.EntityTag) (wire.EntityTag, string, error) {
+	return create(c, ctx, "entity_tag", tag)
+}
+
+func (c *Client) UpdateEntityTag(ctx context.Context, id string, tag wire.EntityTag, if_match string) (wire.EntityTag, string, error) {
+	return update(c, ctx, "entity_tag", id, tag, if_match)
+}
+
+func (c *Client) PatchEntityTag(ctx context.Context, id string, p any, if_match string) (wire.EntityTag, string, error) {
+	return patch[wire.EntityTag](c, ctx, "entity_tag", id, p, if_match)
+}
+
+func (c *Client) DeleteEntityTag(ctx context.Context, id string, if_match string) (wire.EntityTag, error) {
+	return remove[wire.EntityTag](c, ctx, "entity_tag", id, if_match, nil)
+}
+
+func (c *Client) GetEdgeTag(ctx context.Context, id string) (wire.EdgeTag, string, error) {
+	return get[wire.EdgeTag](c, ctx, "edge_tag", id)
+}
+
+func (c *Client) CreateEdgeTag(ctx context.Context, tag wire.EdgeTag) (wire.EdgeTag, string, error) {
+	return create(c, ctx, "edge_tag", tag)
+}
+
+func (c *Client) UpdateEdgeTag(ctx context.Context, id string, tag wire.EdgeTag, if_match string) (wire.EdgeTag, string, error) {
+	return update(c, ctx, "edge_tag", id, tag, if_match)
+}
+
+func (c *Client) PatchEdgeTag(ctx context.Context, id string, p any, if_match string) (wire.EdgeTag, string, error) {
+	return patch[wire.EdgeTag](c, ctx, "edge_tag", id, p, if_match)
+}
+
+func (c *Client) DeleteEdgeTag(ctx context.Context, id string, if_match string) (wire.EdgeTag, error) {
+	return remove[wire.EdgeTag](c, ctx, "edge_tag", id, if_match, nil)
+}
+
+// GraphQuery selects a part of the graph. Zero fields are left to the
+// defaults of the gateway.
+type GraphQuery struct {
+	// Root restricts exports to the entities within Depth hops of this
+	// entity.
+	Root       string
+	Depth      int
+	AssetTypes []oam.AssetType
+	Relations  []string
+	Limit      int
+	Since      time.Time
+	Until      time.Time
+}
+
+func (q GraphQuery) values() url.Values {
+	values := url.Values{}
+	if q.Root != "" {
+		values.Set("root", q.Root)
+	}
+	if q.Depth > 0 {
+		values.Set("depth", strconv.Itoa(q.Depth))
+	}
+	for _, atype := range q.AssetTypes {
+		values.Add("asset_types", string(atype))
+	}
+	for _, relation := range q.Relations {
+		values.Add("relations", relation)
+	}
+	if q.Limit > 0 {
+		values.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if !q.Since.IsZero() {
+		values.Set("since", q.Since.Format(time.RFC3339))
+	}
+	if !q.Until.IsZero() {
+		values.Set("until", q.Until.Format(time.RFC3339))
+	}
+	return values
+}
+
+// Graph returns the neighbourhood of an entity, with the tags of its
+// objects when tags is set. q.Root is ignored.
+func (c *Client) Graph(ctx context.Context, id string, q GraphQuery, tags bool) (*wire.Subgraph, error) {
+	q.Root = ""
+	query := q.values()
+	if tags {
+		query.Set("tags", "true")
+	}
+
+	var out wire.Subgraph
+	_, err := c.call(ctx, request{method: "GET", path: "/entity/" + url.PathEscape(id) + "/graph", query: query}, &out)
+	return &out, err
+}
+
+// Paths returns the shortest paths between two entities, following the
+// given relations only when any is given. Zero max_depth and limit are
+// left to the defaults of the gateway.
+func (c *Client) Paths(ctx context.Context, from, to string, relations []string, max_depth, limit int) (wire.PathResult, error) {
+	query := GraphQuery{Relations: relations}.values()
+	if max_depth > 0 {
+		query.Set("max_depth", strconv.Itoa(max_depth))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var out wire.PathResult
+	_, err := c.call(ctx, request{
+		method: "GET",
+		path:   "/entity/" + url.PathEscape(from) + "/path/" + url.PathEscape(to),
+		query:  query,
+	}, &out)
+	return out, err
+}
+
+// Types returns the JSON Schema of every type the gateway accepts.
+func (c *Client) Types(ctx context.Context) (wire.TypeCatalog, error) {
+	var out wire.TypeCatalog
+	_, err := c.call(ctx, request{method: "GET", path: "/types"}, &out)
+	return out, err
+}
+
+// OpenAPI returns the OpenAPI document of the gateway.
+func (c *Client) OpenAPI(ctx context.Context) (json.RawMessage, error) {
+	var out json.RawMessage
+	_, err := c.call(ctx, request{method: "GET", path: "/openapi.json"}, &out)
+	return out, err
+}
+
+// ExportReader reads an exported graph.
+type ExportReader struct {
+	io.ReadCloser
+	resp *http.Response
+}
+
+// Truncated tells whether the graph was cut at GraphQuery.Limit. It is
+// only known once the graph is read to the end.
+func (er *ExportReader) Truncated() bool {
+	return er.resp.Trailer.Get("X-Graph-Truncated") == "true"
+}
+
+// Export streams the graph selected by q in one of the export formats
+// of the gateway: "ndjson", "json", "graphml" or "cypher". The caller
+// closes the returned reader.
+func (c *Client) Export(ctx context.Context, format string, q GraphQuery) (*ExportReader, error) {
+	query := q.values()
+	if format != "" {
+		query.Set("format", format)
+	}
+
+	resp, err := c.send(ctx, request{method: "GET", path: "/export", query: query})
+	if err != nil {
+		return nil, err
+	}
+	return &ExportReader{ReadCloser: resp.Body, resp: resp}, nil
+}
+
+// Import uploads a graph in one of the import formats of the gateway:
+// "ndjson", "graphml" or "sqlite". It returns the import job, which
+// runs in the background.
+func (c *Client) Import(ctx context.Context, format string, r io.Reader) (wire.Job, error) {
+	query := url.Values{}
+	if format != "" {
+		query.Set("format", format)
+	}
+
+	var out wire.Job
+	_, err := c.call(ctx, request{method: "POST", path: "/import", query: query, body: r}, &out)
+	return out, err
+}
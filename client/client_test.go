@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0ppliger/oam-broker/gateway"
+	"github.com/0ppliger/oam-broker/memory"
+	"github.com/0ppliger/oam-broker/wire"
+	oam_dns "github.com/owasp-amass/open-asset-model/dns"
+)
+
+// newTestServer serves a gateway over an in-memory repository, through
+// wrap when given.
+func newTestServer(t *testing.T, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+
+	g, err := gateway.New(gateway.WithRepository(memory.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var handler http.Handler = g
+	if wrap != nil {
+		handler = wrap(g)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestClient(t *testing.T, base_url string, opts ...Option) *Client {
+	t.Helper()
+
+	c, err := New(base_url, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func fqdn(name string) wire.Entity {
+	return wire.Entity{Type: "FQDN", Asset: &oam_dns.FQDN{Name: name}}
+}
+
+// expectStatus checks that err is an *Error with the given status.
+func expectStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	var status_error *Error
+	if !errors.As(err, &status_error) || status_error.StatusCode != status {
+		t.Errorf("got %v, expected %d", err, status)
+	}
+}
+
+func TestClientObjects(t *testing.T) {
+	c := newTestClient(t, newTestServer(t, nil).URL)
+	ctx := context.Background()
+
+	created, etag, err := c.CreateEntity(ctx, fqdn("www.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, got_etag, err := c.GetEntity(ctx, created.ID)
+	if err != nil || got_etag != etag || got.Asset.(*oam_dns.FQDN).Name != "www.example.com" {
+		t.Fatalf("got %+v with %s, %v", got, got_etag, err)
+	}
+
+	_, _, err = c.UpdateEntity(ctx, created.ID, fqdn("app.example.com"), `"stale"`)
+	expectStatus(t, err, http.StatusPreconditionFailed)
+
+	updated, _, err := c.UpdateEntity(ctx, created.ID, fqdn("app.example.com"), etag)
+	if err != nil || updated.Asset.(*oam_dns.FQDN).Name != "app.example.com" {
+		t.Fatalf("updated %+v, %v", updated, err)
+	}
+
+	patched, _, err := c.PatchEntity(ctx, created.ID, JSONPatch{{Op: "replace", Path: "/asset/name", Value: "api.example.com"}}, "")
+	if err != nil || patched.Asset.(*oam_dns.FQDN).Name != "api.example.com" {
+		t.Fatalf("patched %+v, %v", patched, err)
+	}
+
+	if _, err := c.DeleteEntity(ctx, created.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = c.GetEntity(ctx, created.ID)
+	expectStatus(t, err, http.StatusNotFound)
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/0ppliger/oam-broker/wire"
+)
+
+const (
+	defaultBatchSize     = 500
+	defaultFlushInterval = time.Second
+)
+
+// Emitter batches objects into NDJSON imports, instead of one request
+// per object. Objects are deduplicated by the gateway like with
+// /emit. Within a batch, edges and tags can reference the entities and
+// edges sent before them by the IDs they were given; objects of earlier
+// batches must be referenced by the IDs the gateway stored them under.
+type Emitter struct {
+	Client *Client
+	// BatchSize is the number of objects sent per import, 500 by
+	// default. Emitting blocks while a full batch is sent.
+	BatchSize int
+	// FlushInterval is how long objects wait for their batch to fill
+	// before being sent anyway, 1s by default.
+	FlushInterval time.Duration
+	// OnFlush is called with the import job of every batch, or with
+	// the error that prevented sending it.
+	OnFlush func(job wire.Job, err error)
+
+	mutex  sync.Mutex
+	batch  bytes.Buffer
+	count  int
+	timer  *time.Timer
+	closed bool
+}
+
+func (e *Emitter) Entity(entity wire.Entity) error {
+	return e.emit(wire.EntityRecord, entity)
+}
+
+func (e *Emitter) Edge(edge wire.Edge) error {
+	return e.emit(wire.EdgeRecord, edge)
+}
+
+func (e *Emitter) EntityTag(tag wire.EntityTag) error {
+	return e.emit(wire.EntityTagRecord, tag)
+}
+
+func (e *Emitter) EdgeTag(tag wire.EdgeTag) error {
+	return e.emit(wire.EdgeTagRecord, tag)
+}
+
+// ErrEmitterClosed is returned when emitting after Close.
+var ErrEmitterClosed = errors.New("emitter closed")
+
+func (e *Emitter) emit(kind string, obj any) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(wire.Record{Kind: kind, Data: data})
+	if err != nil {
+		return err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.closed {
+		return ErrEmitterClosed
+	}
+
+	e.batch.Write(line)
+	e.batch.WriteByte('\n')
+	e.count++
+
+	batch_size := e.BatchSize
+	if batch_size <= 0 {
+		batch_size = defaultBatchSize
+	}
+	if e.count >= batch_size {
+		_, err := e.flush(context.Background())
+		return err
+	}
+
+	if e.timer == nil {
+		interval := e.FlushInterval
+		if interval <= 0 {
+			interval = defaultFlushInterval
+		}
+		e.timer = time.AfterFunc(interval, func() {
+			e.mutex.Lock()
+			defer e.mutex.Unlock()
+			e.flush(context.Background())
+		})
+	}
+	return nil
+}
+
+// flush sends the current batch. The mutex is held.
+func (e *Emitter) flush(ctx context.Context) (wire.Job, error) {
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	if e.count == 0 {
+		return wire.Job{}, nil
+	}
+
+	body := bytes.NewReader(bytes.Clone(e.batch.Bytes()))
+	e.batch.Reset()
+	e.count = 0
+
+	job, err := e.Client.Import(ctx, "ndjson", body)
+	if e.OnFlush != nil {
+		e.OnFlush(job, err)
+	}
+	return job, err
+}
+
+// Flush sends the objects emitted so far, and returns the import job of
+// their batch, or a zero Job when there were none.
+func (e *Emitter) Flush(ctx context.Context) (wire.Job, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.flush(ctx)
+}
+
+// Close sends the last batch. Emitting afterwards fails.
+func (e *Emitter) Close(ctx context.Context) (wire.Job, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.closed = true
+	return e.flush(ctx)
+}
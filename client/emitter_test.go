@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/0ppliger/oam-broker/wire"
+)
+
+// flushes collects the jobs of the batches flushed by an emitter.
+func flushes(t *testing.T, e *Emitter) <-chan wire.Job {
+	jobs := make(chan wire.Job, 10)
+	e.OnFlush = func(job wire.Job, err error) {
+		if err != nil {
+			t.Errorf("flush: %v", err)
+		}
+		jobs <- job
+	}
+	return jobs
+}
+
+// imported waits for an import job and returns its result.
+func imported(t *testing.T, c *Client, job wire.Job) wire.ImportResult {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if job, err := c.WaitJob(ctx, job.ID, 10*time.Millisecond); err != nil || job.State != wire.JobSucceeded {
+		t.Fatalf("job %+v, %v", job, err)
+	}
+	var result wire.ImportResult
+	if err := c.JobResult(ctx, job.ID, &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestEmitterFlushesFullBatches(t *testing.T) {
+	c := newTestClient(t, newTestServer(t, nil).URL)
+	e := &Emitter{Client: c, BatchSize: 2, FlushInterval: time.Hour}
+	jobs := flushes(t, e)
+
+	for i := range 3 {
+		if err := e.Entity(fqdn(fmt.Sprintf("%d.example.com", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The full batch is sent while emitting, the rest waits.
+	if len(jobs) != 1 {
+		t.Fatalf("%d batches sent", len(jobs))
+	}
+	if result := imported(t, c, <-jobs); result.Created != 2 {
+		t.Errorf("first batch %+v", result)
+	}
+
+	job, err := e.Close(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := imported(t, c, job); result.Created != 1 {
+		t.Errorf("last batch %+v", result)
+	}
+}
+
+func TestEmitterFlushesOnTimer(t *testing.T) {
+	c := newTestClient(t, newTestServer(t, nil).URL)
+	e := &Emitter{Client: c, FlushInterval: 20 * time.Millisecond}
+	jobs := flushes(t, e)
+
+	if err := e.Entity(fqdn("www.example.com")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case job := <-jobs:
+		if result := imported(t, c, job); result.Created != 1 {
+			t.Errorf("batch %+v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch not sent")
+	}
+}
+
+func TestEmitterClose(t *testing.T) {
+	c := newTestClient(t, newTestServer(t, nil).URL)
+	e := &Emitter{Client: c, FlushInterval: time.Hour}
+	jobs := flushes(t, e)
+
+	if err := e.Entity(fqdn("www.example.com")); err != nil {
+		t.Fatal(err)
+	}
+	job, err := e.Close(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flushed := <-jobs; flushed.ID != job.ID {
+		t.Errorf("flushed %s, closed with %s", flushed.ID, job.ID)
+	}
+	if result := imported(t, c, job); result.Created != 1 {
+		t.Errorf("last batch %+v", result)
+	}
+
+	if err := e.Entity(fqdn("app.example.com")); !errors.Is(err, ErrEmitterClosed) {
+		t.Errorf("emitted after close: %v", err)
+	}
+	// Nothing is left to send.
+	if job, err := e.Close(context.Background()); err != nil || job.ID != "" {
+		t.Errorf("closed again with %+v, %v", job, err)
+	}
+}
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/0ppliger/oam-broker/wire"
+)
+
+// Event is an event of /listen. Data holds the object the event is
+// about: a wire.Entity for entity events, a wire.Job for job events,
+// and so on.
+type Event struct {
+	ID   string
+	Type wire.EventType
+	Data json.RawMessage
+}
+
+// Decode decodes the data of the event in out.
+func (e Event) Decode(out any) error {
+	return json.Unmarshal(e.Data, out)
+}
+
+// Changes returns the changes carried by update events.
+func (e Event) Changes() ([]wire.Change, error) {
+	var data struct {
+		Changes []wire.Change `json:"changes"`
+	}
+	err := json.Unmarshal(e.Data, &data)
+	return data.Changes, err
+}
+
+// ErrStopped can be returned by the handler given to Subscriber.Run to
+// stop it without error.
+var ErrStopped = errors.New("subscription stopped")
+
+// Subscriber streams the events of /listen, reconnecting whenever the
+// stream breaks. The gateway replays the events missed in between,
+// identified by the ID of the last event received, as long as it still
+// holds them.
+type Subscriber struct {
+	Client *Client
+	// Types restricts the events handled to these types, all when
+	// empty.
+	Types []wire.EventType
+	// LastEventID resumes the stream after this event. It is updated
+	// as events are received.
+	LastEventID string
+	// OnError is called with the error that broke the stream before
+	// each reconnection.
+	OnError func(err error)
+	// MinBackoff and MaxBackoff bound the delay before reconnecting,
+	// doubled after each failed attempt. 1s and 30s by default.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Run calls handle for each event until ctx is done or handle fails. It
+// returns nil when stopped by ctx or by ErrStopped. It also returns the
+// errors of the gateway reconnecting cannot fix, such as 401 or 404, as
+// an *Error.
+func (s *Subscriber) Run(ctx context.Context, handle func(Event) error) error {
+	min_backoff, max_backoff := s.MinBackoff, s.MaxBackoff
+	if min_backoff <= 0 {
+		min_backoff = time.Second
+	}
+	if max_backoff < min_backoff {
+		max_backoff = max(30*time.Second, min_backoff)
+	}
+
+	wanted := make(map[wire.EventType]bool)
+	for _, t := range s.Types {
+		wanted[t] = true
+	}
+
+	backoff := min_backoff
+	for {
+		received, err := s.stream(ctx, func(e Event) error {
+			if len(wanted) > 0 && !wanted[e.Type] {
+				return nil
+			}
+			return handle(e)
+		})
+
+		var handler_error handlerError
+		var status_error *Error
+		switch {
+		case ctx.Err() != nil, errors.Is(err, ErrStopped):
+			return nil
+		case errors.As(err, &handler_error):
+			return handler_error.err
+		case errors.As(err, &status_error) && !retryable(status_error.StatusCode):
+			return err
+		}
+
+		if received {
+			backoff = min_backoff
+		}
+		if s.OnError != nil {
+			s.OnError(err)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+		backoff = min(2*backoff, max_backoff)
+	}
+}
+
+// retryable tells whether a request failing with the given status may
+// succeed later: when the gateway is overloaded, limits the client or
+// fails.
+func retryable(status int) bool {
+	return status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// handlerError distinguishes the failures of the handler from those of
+// the stream.
+type handlerError struct {
+	err error
+}
+
+func (e handlerError) Error() string {
+	return e.err.Error()
+}
+
+// stream reads the event stream once, until it breaks. It reports
+// whether any event was received.
+func (s *Subscriber) stream(ctx context.Context, handle func(Event) error) (bool, error) {
+	header := http.Header{"Accept": {"text/event-stream"}}
+	if s.LastEventID != "" {
+		header.Set("Last-Event-ID", s.LastEventID)
+	}
+
+	resp, err := s.Client.send(ctx, request{method: "GET", path: "/listen", header: header})
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	received := false
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var event Event
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			if len(data) > 0 {
+				event.Data = json.RawMessage(strings.Join(data, "\n"))
+				if event.ID != "" {
+					s.LastEventID = event.ID
+				}
+				received = true
+				if err := handle(event); err != nil {
+					if errors.Is(err, ErrStopped) {
+						return received, err
+					}
+					return received, handlerError{err}
+				}
+			}
+			event, data = Event{}, nil
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Type = wire.EventType(value)
+		case "data":
+			data = append(data, value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return received, err
+	}
+	return received, errors.New("event stream closed")
+}
+
+// Subscribe streams the events of the given types, all when none is
+// given, on a channel closed once ctx is done. It reconnects whenever
+// the stream breaks.
+func (c *Client) Subscribe(ctx context.Context, types ...wire.EventType) <-chan Event {
+	out := make(chan Event)
+	s := &Subscriber{Client: c, Types: types}
+
+	go func() {
+		defer close(out)
+		s.Run(ctx, func(e Event) error {
+			select {
+			case out <- e:
+				return nil
+			case <-ctx.Done():
+				return ErrStopped
+			}
+		})
+	}()
+
+	return out
+}
@@ -0,0 +1,264 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0ppliger/oam-broker/gateway"
+	"github.com/0ppliger/oam-broker/memory"
+	"github.com/0ppliger/oam-broker/wire"
+	oam_dns "github.com/owasp-amass/open-asset-model/dns"
+)
+
+// listenRequests records the requests to /listen before passing them on.
+type listenRequests struct {
+	mutex    sync.Mutex
+	requests []*http.Request
+	at       []time.Time
+	cancels  []context.CancelFunc
+	// failing is the number of requests still to answer with 503.
+	failing int
+}
+
+// drop ends the streams served so far.
+func (lr *listenRequests) drop() {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+
+	for _, cancel := range lr.cancels {
+		cancel()
+	}
+}
+
+func (lr *listenRequests) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/listen" {
+			lr.mutex.Lock()
+			lr.requests = append(lr.requests, r)
+			lr.at = append(lr.at, time.Now())
+			failing := lr.failing > 0
+			lr.failing--
+			ctx, cancel := context.WithCancel(r.Context())
+			lr.cancels = append(lr.cancels, cancel)
+			lr.mutex.Unlock()
+
+			if failing {
+				http.Error(w, "overloaded", http.StatusServiceUnavailable)
+				return
+			}
+			r = r.WithContext(ctx)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (lr *listenRequests) count() int {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+	return len(lr.requests)
+}
+
+// run runs the subscriber until ctx is done, passing the events on.
+func run(ctx context.Context, s *Subscriber) (<-chan Event, <-chan error) {
+	events := make(chan Event, 100)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx, func(e Event) error {
+			events <- e
+			return nil
+		})
+	}()
+	return events, done
+}
+
+// nameOf returns the name of the FQDN an entity event is about.
+func nameOf(t *testing.T, e Event) string {
+	t.Helper()
+
+	var entity wire.Entity
+	if err := e.Decode(&entity); err != nil {
+		t.Fatal(err)
+	}
+	return entity.Asset.(*oam_dns.FQDN).Name
+}
+
+func TestSubscriberResumes(t *testing.T) {
+	var lr listenRequests
+	c := newTestClient(t, newTestServer(t, lr.wrap).URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mutex sync.Mutex
+	var errs []error
+	s := &Subscriber{
+		Client:     c,
+		Types:      []wire.EventType{wire.EntityCreated},
+		MinBackoff: 200 * time.Millisecond,
+		OnError: func(err error) {
+			mutex.Lock()
+			errs = append(errs, err)
+			mutex.Unlock()
+		},
+	}
+	events, done := run(ctx, s)
+
+	// The stream starts once the request is served, so entities are
+	// created until one of them is reported.
+	var first Event
+	for i := 0; first.ID == ""; i++ {
+		if _, _, err := c.CreateEntity(ctx, fqdn(fmt.Sprintf("%d.example.com", i))); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case first = <-events:
+		case <-time.After(50 * time.Millisecond):
+			if i == 100 {
+				t.Fatal("no event")
+			}
+		}
+	}
+
+	// The entity created while the stream is broken is replayed once
+	// the subscriber reconnects.
+	lr.drop()
+	if _, _, err := c.CreateEntity(ctx, fqdn("missed.example.com")); err != nil {
+		t.Fatal(err)
+	}
+	timeout := time.After(5 * time.Second)
+	for missed := false; !missed; {
+		select {
+		case e := <-events:
+			missed = nameOf(t, e) == "missed.example.com"
+		case <-timeout:
+			t.Fatal("missed event not replayed")
+		}
+	}
+
+	lr.mutex.Lock()
+	if len(lr.requests) != 2 || lr.requests[0].Header.Get("Last-Event-ID") != "" || lr.requests[1].Header.Get("Last-Event-ID") == "" {
+		t.Errorf("%d requests to /listen", len(lr.requests))
+	}
+	lr.mutex.Unlock()
+	mutex.Lock()
+	if len(errs) != 1 {
+		t.Errorf("errors %v", errs)
+	}
+	mutex.Unlock()
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("stopped with %v", err)
+	}
+}
+
+func TestSubscriberBacksOff(t *testing.T) {
+	lr := listenRequests{failing: 4}
+	c := newTestClient(t, newTestServer(t, lr.wrap).URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := &Subscriber{
+		Client:     c,
+		MinBackoff: 20 * time.Millisecond,
+		MaxBackoff: 40 * time.Millisecond,
+		OnError: func(err error) {
+			var status_error *Error
+			if !errors.As(err, &status_error) || status_error.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("error %v", err)
+			}
+		},
+	}
+	_, done := run(ctx, s)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for lr.count() < 5 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d requests to /listen", lr.count())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("stopped with %v", err)
+	}
+
+	// The delay doubles after each failure, up to MaxBackoff.
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+	for i, backoff := range []time.Duration{20, 40, 40, 40} {
+		if gap := lr.at[i+1].Sub(lr.at[i]); gap < backoff*time.Millisecond {
+			t.Errorf("attempt %d after %s, expected %dms", i+2, gap, backoff)
+		}
+	}
+}
+
+func TestSubscriberHandlerErrors(t *testing.T) {
+	server := newTestServer(t, nil)
+	c := newTestClient(t, server.URL)
+
+	for _, handler_error := range []error{errors.New("disk full"), ErrStopped} {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		done := make(chan error, 1)
+		go func() {
+			s := &Subscriber{Client: c}
+			done <- s.Run(ctx, func(e Event) error { return handler_error })
+		}()
+
+		// Entities are created until the handler is called.
+		var err error
+		for i := 0; ; i++ {
+			c.CreateEntity(ctx, fqdn(fmt.Sprintf("%d.example.com", i)))
+			select {
+			case err = <-done:
+			case <-time.After(50 * time.Millisecond):
+				continue
+			}
+			break
+		}
+		if ctx.Err() != nil {
+			t.Fatal("handler not called")
+		}
+
+		want := handler_error
+		if errors.Is(handler_error, ErrStopped) {
+			want = nil
+		}
+		if err != want {
+			t.Errorf("handler failing with %v: Run returned %v", handler_error, err)
+		}
+	}
+}
+
+func TestSubscriberStopsOnClientErrors(t *testing.T) {
+	wss, err := gateway.NewWorkspaces([]gateway.Workspace{
+		{Name: "acme", Repository: memory.New(), Keys: []string{"acme-key"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(wss)
+	t.Cleanup(server.Close)
+
+	for path, status := range map[string]int{
+		"/workspaces/acme":    http.StatusUnauthorized,
+		"/workspaces/initech": http.StatusNotFound,
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		s := &Subscriber{Client: newTestClient(t, server.URL+path), MinBackoff: time.Millisecond}
+		err := s.Run(ctx, func(e Event) error { return nil })
+		if ctx.Err() != nil {
+			t.Fatalf("%s: retried until the deadline", path)
+		}
+		expectStatus(t, err, status)
+	}
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/0ppliger/oam-broker/wire"
+)
+
+// CreateJob starts a job of the given kind, such as "prune", with its
+// parameters. Prune jobs require an admin key, like Prune, which is also
+// needed to get, wait for and cancel them.
+func (c *Client) CreateJob(ctx context.Context, kind string, params any) (wire.Job, error) {
+	var out wire.Job
+	body, err := jsonBody(map[string]any{"kind": kind, "params": params})
+	if err != nil {
+		return out, err
+	}
+	_, err = c.call(ctx, request{method: "POST", path: "/jobs", body: body}, &out)
+	return out, err
+}
+
+// Prune starts a job deleting the objects matching filter. It requires
+// an admin key, sent with WithHeader("X-Admin-Key", key).
+func (c *Client) Prune(ctx context.Context, filter wire.PruneFilter) (wire.Job, error) {
+	var out wire.Job
+	body, err := jsonBody(filter)
+	if err != nil {
+		return out, err
+	}
+	_, err = c.call(ctx, request{method: "POST", path: "/admin/prune", body: body}, &out)
+	return out, err
+}
+
+func (c *Client) Jobs(ctx context.Context) ([]wire.Job, error) {
+	var out []wire.Job
+	_, err := c.call(ctx, request{method: "GET", path: "/jobs"}, &out)
+	return out, err
+}
+
+func (c *Client) Job(ctx context.Context, id string) (wire.Job, error) {
+	var out wire.Job
+	_, err := c.call(ctx, request{method: "GET", path: "/jobs/" + url.PathEscape(id)}, &out)
+	return out, err
+}
+
+// JobResult decodes the result of a finished job in out, such as a
+// *wire.ImportResult for an import job. It fails with 409 while the job
+// is running.
+func (c *Client) JobResult(ctx context.Context, id string, out any) error {
+	_, err := c.call(ctx, request{method: "GET", path: "/jobs/" + url.PathEscape(id) + "/result"}, out)
+	return err
+}
+
+// CancelJob requests a running job to stop.
+func (c *Client) CancelJob(ctx context.Context, id string) (wire.Job, error) {
+	var out wire.Job
+	_, err := c.call(ctx, request{method: "DELETE", path: "/jobs/" + url.PathEscape(id)}, &out)
+	return out, err
+}
+
+// WaitJob polls a job every interval until it is finished.
+func (c *Client) WaitJob(ctx context.Context, id string, interval time.Duration) (wire.Job, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.Job(ctx, id)
+		if err != nil || job.State != wire.JobRunning {
+			return job, err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return job, ctx.Err()
+		}
+	}
+}
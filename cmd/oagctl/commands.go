@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/0ppliger/oam-broker/client"
+	"github.com/0ppliger/oam-broker/wire"
+)
+
+const kinds = "entity|edge|entity_tag|edge_tag"
+
+// contentFields maps each kind to the field holding its content.
+var contentFields = map[string]string{
+	"entity":     "asset",
+	"edge":       "relation",
+	"entity_tag": "property",
+	"edge_tag":   "property",
+}
+
+// kindArgs checks that the positional arguments of a command are a kind
+// followed by the given number of other arguments.
+func kindArgs(fs *flag.FlagSet, args []string, n int) (string, error) {
+	if len(args) != n+1 {
+		fs.Usage()
+		return "", errUsage
+	}
+	if _, ok := contentFields[args[0]]; !ok {
+		fmt.Fprintf(fs.Output(), "unknown kind %q, want %s\n", args[0], kinds)
+		return "", errUsage
+	}
+	return args[0], nil
+}
+
+func emit(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("emit", kinds+" -type TYPE -asset|-relation|-property JSON [FLAGS]")
+	id := fs.String("id", "", "ID of the object, to overwrite it")
+	object_type := fs.String("type", "", "asset, relation or property type, such as FQDN")
+	contents := map[string]*string{
+		"asset":    fs.String("asset", "", "JSON of the asset of an entity"),
+		"relation": fs.String("relation", "", "JSON of the relation of an edge"),
+		"property": fs.String("property", "", "JSON of the property of a tag"),
+	}
+	from := fs.String("from", "", "ID of the entity an edge comes from")
+	to := fs.String("to", "", "ID of the entity an edge goes to")
+	entity := fs.String("entity", "", "ID of the entity of an entity tag")
+	edge := fs.String("edge", "", "ID of the edge of an edge tag")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	kind, err := kindArgs(fs, positional, 0)
+	if err != nil {
+		return err
+	}
+
+	field := contentFields[kind]
+	content := *contents[field]
+	if *object_type == "" || content == "" {
+		fmt.Fprintf(fs.Output(), "emit %s needs -type and -%s\n", kind, field)
+		return errUsage
+	}
+
+	// The object is decoded like the gateway does, so that unknown types
+	// and invalid content are reported before sending it.
+	body := map[string]any{
+		"id":   *id,
+		"type": *object_type,
+		field:  json.RawMessage(content),
+	}
+	switch kind {
+	case "edge":
+		body["from_entity"], body["to_entity"] = *from, *to
+	case "entity_tag":
+		body["entity"] = *entity
+	case "edge_tag":
+		body["edge"] = *edge
+	}
+	json_encoded, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("invalid -%s: %w", field, err)
+	}
+
+	var out any
+	var etag string
+	switch kind {
+	case "entity":
+		var input wire.Entity
+		if err = json.Unmarshal(json_encoded, &input); err == nil {
+			out, etag, err = c.CreateEntity(ctx, input)
+		}
+	case "edge":
+		var input wire.Edge
+		if err = json.Unmarshal(json_encoded, &input); err == nil {
+			out, etag, err = c.CreateEdge(ctx, input)
+		}
+	case "entity_tag":
+		var input wire.EntityTag
+		if err = json.Unmarshal(json_encoded, &input); err == nil {
+			out, etag, err = c.CreateEntityTag(ctx, input)
+		}
+	case "edge_tag":
+		var input wire.EdgeTag
+		if err = json.Unmarshal(json_encoded, &input); err == nil {
+			out, etag, err = c.CreateEdgeTag(ctx, input)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return printJSON(out, etag)
+}
+
+func get(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("get", kinds+" ID")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	kind, err := kindArgs(fs, positional, 1)
+	if err != nil {
+		return err
+	}
+	id := positional[1]
+
+	var out any
+	var etag string
+	switch kind {
+	case "entity":
+		out, etag, err = c.GetEntity(ctx, id)
+	case "edge":
+		out, etag, err = c.GetEdge(ctx, id)
+	case "entity_tag":
+		out, etag, err = c.GetEntityTag(ctx, id)
+	case "edge_tag":
+		out, etag, err = c.GetEdgeTag(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+	return printJSON(out, etag)
+}
+
+func remove(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("delete", kinds+" ID [FLAGS]")
+	if_match := fs.String("if-match", "", "only delete the object if it still has this ETag")
+	cascade := fs.Bool("cascade", false, "delete the edges and tags of an entity along with it")
+	preview := fs.Bool("preview", false, "only list what -cascade would delete")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	kind, err := kindArgs(fs, positional, 1)
+	if err != nil {
+		return err
+	}
+	id := positional[1]
+
+	if (*cascade || *preview) && kind != "entity" {
+		fmt.Fprintln(fs.Output(), "-cascade and -preview only apply to entities")
+		return errUsage
+	}
+
+	var out any
+	switch {
+	case *preview:
+		out, err = c.PreviewCascade(ctx, id)
+	case *cascade:
+		out, err = c.DeleteEntityCascade(ctx, id, *if_match)
+	case kind == "entity":
+		out, err = c.DeleteEntity(ctx, id, *if_match)
+	case kind == "edge":
+		out, err = c.DeleteEdge(ctx, id, *if_match)
+	case kind == "entity_tag":
+		out, err = c.DeleteEntityTag(ctx, id, *if_match)
+	case kind == "edge_tag":
+		out, err = c.DeleteEdgeTag(ctx, id, *if_match)
+	}
+	if err != nil {
+		return err
+	}
+	return printJSON(out, "")
+}
+
+// importFormats maps file extensions to the import format of the
+// gateway. Other files are sent as NDJSON.
+var importFormats = map[string]string{
+	".graphml": "graphml",
+	".xml":     "graphml",
+	".sqlite":  "sqlite",
+	".sqlite3": "sqlite",
+	".db":      "sqlite",
+}
+
+func importFile(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("import", "[FLAGS] FILE")
+	format := fs.String("format", "", "ndjson, graphml or sqlite, guessed from the file extension by default")
+	wait := fs.Bool("wait", false, "wait for the import to finish and print its result")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	name := positional[0]
+
+	if *format == "" {
+		*format = importFormats[strings.ToLower(filepath.Ext(name))]
+	}
+
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	job, err := c.Import(ctx, *format, r)
+	if err != nil {
+		return err
+	}
+	if !*wait {
+		return printJSON(job, "")
+	}
+
+	fmt.Fprintln(os.Stderr, "Waiting for job "+job.ID+"...")
+	job, err = c.WaitJob(ctx, job.ID, 500*time.Millisecond)
+	if err != nil {
+		return err
+	}
+	if err := printJSON(job, ""); err != nil {
+		return err
+	}
+	if job.State != wire.JobSucceeded {
+		return fmt.Errorf("import %s: %s", job.State, job.Error)
+	}
+	return nil
+}
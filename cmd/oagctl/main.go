@@ -0,0 +1,173 @@
+// Command oagctl is the command-line client of the Open Asset Gateway.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/0ppliger/oam-broker/client"
+)
+
+const usage = `usage: oagctl [-url URL] [-cacert FILE] [-insecure] [-H 'Key: Value']... COMMAND [ARGS]
+
+Commands:
+  emit KIND [FLAGS]        create an object, or refresh it if it already exists
+  get KIND ID              print an object
+  delete KIND ID [FLAGS]   delete an object
+  import [FLAGS] FILE      import a graph file, - for stdin
+  tail [FLAGS]             print the events of the gateway as they happen
+
+KIND is one of entity, edge, entity_tag or edge_tag. Run a command with
+-h for its flags.
+
+The gateway URL is $OAG_URL when -url is not given, https://localhost:443
+otherwise.
+`
+
+// errUsage reports invalid arguments, after the usage was printed.
+var errUsage = errors.New("invalid arguments")
+
+type command func(ctx context.Context, c *client.Client, args []string) error
+
+var commands = map[string]command{
+	"emit":   emit,
+	"get":    get,
+	"delete": remove,
+	"import": importFile,
+	"tail":   tail,
+}
+
+// headers is a repeatable -H flag.
+type headers []string
+
+func (h *headers) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headers) Set(value string) error {
+	if !strings.Contains(value, ":") {
+		return fmt.Errorf("invalid header %q, want 'Key: Value'", value)
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+	}
+
+	base_url := flag.String("url", os.Getenv("OAG_URL"), "URL of the gateway")
+	cacert := flag.String("cacert", "", "PEM file of the certificate authorities to trust")
+	insecure := flag.Bool("insecure", false, "do not verify the certificate of the gateway")
+	var extra headers
+	flag.Var(&extra, "H", "header to add to every request, repeatable")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	run, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "oagctl: unknown command %q\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	c, err := newClient(*base_url, *cacert, *insecure, extra)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "oagctl: "+err.Error())
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, c, flag.Args()[1:]); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "oagctl: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+func newClient(base_url, cacert string, insecure bool, extra headers) (*client.Client, error) {
+	if base_url == "" {
+		base_url = "https://localhost:443"
+	}
+
+	config := &tls.Config{InsecureSkipVerify: insecure}
+	if cacert != "" {
+		pem, err := os.ReadFile(cacert)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", cacert)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+
+	opts := []client.Option{client.WithHTTPClient(&http.Client{Transport: transport})}
+	for _, header := range extra {
+		key, value, _ := strings.Cut(header, ":")
+		opts = append(opts, client.WithHeader(strings.TrimSpace(key), strings.TrimSpace(value)))
+	}
+
+	return client.New(base_url, opts...)
+}
+
+// parseArgs parses the flags of a command wherever they are among its
+// arguments, and returns the other arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// newFlagSet returns the flag set of a command, printing its usage line
+// on errors.
+func newFlagSet(name, args_usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: oagctl %s %s\n", name, args_usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// printJSON prints v indented on stdout, and the ETag on stderr when
+// there is one.
+func printJSON(v any, etag string) error {
+	json_encoded, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(json_encoded))
+	if etag != "" {
+		fmt.Fprintln(os.Stderr, "ETag: "+etag)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/0ppliger/oam-broker/client"
+	"github.com/0ppliger/oam-broker/wire"
+)
+
+// filters is a repeatable -filter flag. Values of the same key are
+// alternatives, different keys must all match.
+type filters map[string][]string
+
+// filterKeys lists the keys -filter accepts.
+var filterKeys = map[string]string{
+	"event":         "event type, such as edge_created",
+	"kind":          "entity, edge, entity_tag, edge_tag or job",
+	"id":            "ID of the object",
+	"asset_type":    "asset type of entities",
+	"relation_type": "relation type of edges",
+	"property_type": "property type of tags",
+}
+
+func (f filters) String() string {
+	var pairs []string
+	for key, values := range f {
+		for _, value := range values {
+			pairs = append(pairs, key+"="+value)
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f filters) Set(pair string) error {
+	key, value, ok := strings.Cut(pair, "=")
+	if _, known := filterKeys[key]; !ok || !known {
+		return fmt.Errorf("invalid filter %q, want KEY=VALUE with KEY one of event, kind, id, asset_type, relation_type or property_type", pair)
+	}
+	f[key] = append(f[key], value)
+	return nil
+}
+
+// eventKind returns the kind of object an event is about. The entity
+// events keep their legacy names, which have no kind prefix.
+func eventKind(event wire.EventType) string {
+	switch event {
+	case wire.EntityCreated, wire.EntityUpdated, wire.EntityDeleted:
+		return "entity"
+	}
+	for _, kind := range []string{"entity_tag", "edge_tag", "entity", "edge", "job"} {
+		if strings.HasPrefix(string(event), kind+"_") {
+			return kind
+		}
+	}
+	return ""
+}
+
+// tailedEvent holds the fields of the data of events that are printed
+// or filtered on.
+type tailedEvent struct {
+	ID      string        `json:"id"`
+	Type    string        `json:"type"`
+	Changes []wire.Change `json:"changes"`
+	// Jobs
+	Kind  string `json:"kind"`
+	State string `json:"state"`
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+}
+
+func (f filters) match(kind string, e client.Event, data tailedEvent) bool {
+	fields := map[string]string{
+		"event": string(e.Type),
+		"kind":  kind,
+		"id":    data.ID,
+	}
+	switch kind {
+	case "entity":
+		fields["asset_type"] = data.Type
+	case "edge":
+		fields["relation_type"] = data.Type
+	case "entity_tag", "edge_tag":
+		fields["property_type"] = data.Type
+	}
+
+	for key, values := range f {
+		value, ok := fields[key]
+		if !ok {
+			return false
+		}
+		matched := false
+		for _, candidate := range values {
+			if candidate == value {
+				matched = true
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func tail(ctx context.Context, c *client.Client, args []string) error {
+	f := filters{}
+	fs := newFlagSet("tail", "[FLAGS]")
+	fs.Var(f, "filter", "only print the events matching KEY=VALUE, repeatable, with KEY one of:")
+	raw := fs.Bool("json", false, "print the events as JSON lines")
+	since := fs.String("since", "", "resume after the event with this ID")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: oagctl tail [FLAGS]")
+		fs.PrintDefaults()
+		for _, key := range []string{"event", "kind", "id", "asset_type", "relation_type", "property_type"} {
+			fmt.Fprintf(fs.Output(), "    %-14s %s\n", key, filterKeys[key])
+		}
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	subscriber := &client.Subscriber{
+		Client:      c,
+		LastEventID: *since,
+		OnError: func(err error) {
+			fmt.Fprintln(os.Stderr, "oagctl: stream broken, reconnecting: "+err.Error())
+		},
+	}
+
+	return subscriber.Run(ctx, func(e client.Event) error {
+		kind := eventKind(e.Type)
+
+		var data tailedEvent
+		json.Unmarshal(e.Data, &data)
+		if !f.match(kind, e, data) {
+			return nil
+		}
+
+		if *raw {
+			line, _ := json.Marshal(map[string]any{"id": e.ID, "event": e.Type, "data": e.Data})
+			fmt.Println(string(line))
+			return nil
+		}
+
+		fmt.Println(formatEvent(kind, e, data))
+		return nil
+	})
+}
+
+// formatEvent renders an event on a line: the time it was received, its
+// ID and type, then the object it is about. The changes of updates
+// follow, one per line.
+func formatEvent(kind string, e client.Event, data tailedEvent) string {
+	line := fmt.Sprintf("%s #%s %-18s", time.Now().Format("15:04:05"), e.ID, e.Type)
+
+	if kind == "job" {
+		line += fmt.Sprintf(" %s %s %s %d/%d", data.ID, data.Kind, data.State, data.Done, data.Total)
+		return line
+	}
+
+	var fields map[string]json.RawMessage
+	json.Unmarshal(e.Data, &fields)
+
+	line += fmt.Sprintf(" %s %s", data.Type, data.ID)
+	switch kind {
+	case "entity":
+		line += " " + string(fields["asset"])
+	case "edge":
+		line += fmt.Sprintf(" %s -> %s %s", jsonString(fields["from_entity"]), jsonString(fields["to_entity"]), fields["relation"])
+	case "entity_tag":
+		line += fmt.Sprintf(" on entity %s %s", jsonString(fields["entity"]), fields["property"])
+	case "edge_tag":
+		line += fmt.Sprintf(" on edge %s %s", jsonString(fields["edge"]), fields["property"])
+	}
+
+	for _, change := range data.Changes {
+		value, _ := json.Marshal(change.Value)
+		line += fmt.Sprintf("\n    %s %s %s", change.Op, change.Path, value)
+	}
+	return line
+}
+
+// jsonString decodes a JSON string, such as the ID of an object.
+func jsonString(raw json.RawMessage) string {
+	var s string
+	json.Unmarshal(raw, &s)
+	return s
+}
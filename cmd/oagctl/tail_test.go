@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/0ppliger/oam-broker/client"
+	"github.com/0ppliger/oam-broker/wire"
+)
+
+// The data of an event of each kind, as the gateway sends it.
+var tailedData = map[string]string{
+	"entity":     `{"id":"e1","type":"FQDN","asset":{"name":"owasp.org"}}`,
+	"edge":       `{"id":"r1","type":"BasicDNSRelation","relation":{"label":"dns_record"},"from_entity":"e1","to_entity":"e2"}`,
+	"entity_tag": `{"id":"t1","type":"SimpleProperty","property":{"property_name":"source"},"entity":"e1"}`,
+	"edge_tag":   `{"id":"t2","type":"SimpleProperty","property":{"property_name":"source"},"edge":"r1"}`,
+	"job":        `{"id":"j1","kind":"prune","state":"running","done":2,"total":5}`,
+}
+
+// What formatEvent prints about the object of each kind.
+var tailedLine = map[string]string{
+	"entity":     `FQDN e1 {"name":"owasp.org"}`,
+	"edge":       `BasicDNSRelation r1 e1 -> e2 {"label":"dns_record"}`,
+	"entity_tag": `SimpleProperty t1 on entity e1 {"property_name":"source"}`,
+	"edge_tag":   `SimpleProperty t2 on edge r1 {"property_name":"source"}`,
+	"job":        `j1 prune running 2/5`,
+}
+
+var tailedEvents = map[wire.EventType]string{
+	wire.EntityCreated:    "entity",
+	wire.EntityUpdated:    "entity",
+	wire.EntityTouched:    "entity",
+	wire.EntityDeleted:    "entity",
+	wire.EdgeCreated:      "edge",
+	wire.EdgeUpdated:      "edge",
+	wire.EdgeTouched:      "edge",
+	wire.EdgeDeleted:      "edge",
+	wire.EntityTagCreated: "entity_tag",
+	wire.EntityTagUpdated: "entity_tag",
+	wire.EntityTagTouched: "entity_tag",
+	wire.EntityTagDeleted: "entity_tag",
+	wire.EdgeTagCreated:   "edge_tag",
+	wire.EdgeTagUpdated:   "edge_tag",
+	wire.EdgeTagTouched:   "edge_tag",
+	wire.EdgeTagDeleted:   "edge_tag",
+	wire.JobProgress:      "job",
+	wire.JobFinished:      "job",
+}
+
+func tailedEventOf(t *testing.T, event wire.EventType, kind string) (client.Event, tailedEvent) {
+	t.Helper()
+
+	e := client.Event{ID: "7", Type: event, Data: json.RawMessage(tailedData[kind])}
+	var data tailedEvent
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	return e, data
+}
+
+func TestEventKind(t *testing.T) {
+	for event, kind := range tailedEvents {
+		if got := eventKind(event); got != kind {
+			t.Errorf("eventKind(%s) = %q, want %q", event, got, kind)
+		}
+	}
+	if got := eventKind("unknown"); got != "" {
+		t.Errorf("eventKind(unknown) = %q, want none", got)
+	}
+}
+
+func TestFiltersMatch(t *testing.T) {
+	for event, kind := range tailedEvents {
+		e, data := tailedEventOf(t, event, kind)
+
+		for _, tt := range []struct {
+			pairs []string
+			want  bool
+		}{
+			{nil, true},
+			{[]string{"event=" + string(event)}, true},
+			{[]string{"event=other"}, false},
+			{[]string{"event=other", "event=" + string(event)}, true},
+			{[]string{"kind=" + kind}, true},
+			{[]string{"kind=" + kind, "id=" + data.ID}, true},
+			{[]string{"kind=" + kind, "id=other"}, false},
+			{[]string{"asset_type=FQDN"}, kind == "entity"},
+			{[]string{"relation_type=BasicDNSRelation"}, kind == "edge"},
+			{[]string{"property_type=SimpleProperty"}, kind == "entity_tag" || kind == "edge_tag"},
+		} {
+			f := filters{}
+			for _, pair := range tt.pairs {
+				if err := f.Set(pair); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := f.match(kind, e, data); got != tt.want {
+				t.Errorf("%s: match(%v) = %t, want %t", event, tt.pairs, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestFormatEvent(t *testing.T) {
+	for event, kind := range tailedEvents {
+		e, data := tailedEventOf(t, event, kind)
+
+		line := formatEvent(eventKind(event), e, data)
+		if !strings.Contains(line, " #7 "+string(event)) || !strings.HasSuffix(line, " "+tailedLine[kind]) {
+			t.Errorf("%s: formatEvent = %q, want %q", event, line, tailedLine[kind])
+		}
+	}
+
+	e := client.Event{ID: "8", Type: wire.EntityUpdated, Data: json.RawMessage(
+		`{"id":"e1","type":"FQDN","asset":{"name":"owasp.org"},"changes":[{"op":"replace","path":"/asset/name","value":"owasp.org"}]}`,
+	)}
+	var data tailedEvent
+	json.Unmarshal(e.Data, &data)
+
+	line := formatEvent(eventKind(e.Type), e, data)
+	if want := tailedLine["entity"] + "\n    replace /asset/name \"owasp.org\""; !strings.HasSuffix(line, want) {
+		t.Errorf("formatEvent = %q, want the changes after %q", line, want)
+	}
+}
@@ -1,82 +0,0 @@
-package main
-
-import (
-	"net/http"
-	"fmt"
-	"sync"
-	dbe "github.com/owasp-amass/asset-db/events"
-)
-
-
-type ServerSentEvent struct {
-	Event dbe.EventType
-	Data Serializable
-}
-
-func (sse ServerSentEvent) Write(w http.ResponseWriter) {
-	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", sse.Event, sse.Data.JSON())
-}
-
-type EventBus struct {
-	subscribers map[chan ServerSentEvent]bool
-	mutex       sync.Mutex
-}
-
-func (bus *EventBus) AddSubscriber() chan ServerSentEvent {
-	ch := make(chan ServerSentEvent)
-	
-	bus.mutex.Lock()
-	bus.subscribers[ch] = true
-	bus.mutex.Unlock()
-
-	return ch
-}
-
-func (bus *EventBus) RemoveSubscriber(ch chan ServerSentEvent) {
-	bus.mutex.Lock()
-	delete(bus.subscribers, ch)
-	bus.mutex.Unlock()
-	close(ch)
-}
-
-func (bus *EventBus) Publish(event dbe.EventType, data Serializable) {
-
-	sse := ServerSentEvent{
-		Event: event,
-		Data: data,
-	}
-	
-	bus.mutex.Lock()
-	defer bus.mutex.Unlock()
-	for ch := range bus.subscribers {
-		ch <- sse
-	}
-}
-
-func (api *ApiV1) ListenEvents(w http.ResponseWriter, r *http.Request) {
-
-	ch := api.bus.AddSubscriber()
-	defer func() {
-		api.bus.RemoveSubscriber(ch)
-	}()
-	
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
-		return
-	}
-
-	for {
-		select {
-		case sse := <-ch:
-			sse.Write(w)
-			flusher.Flush()
-		case <-r.Context().Done():
-			return
-		}
-	}
-}
@@ -0,0 +1,148 @@
+// Package eventbus fans the events of the gateway out to subscribers,
+// keeping the last ones for subscribers resuming a stream.
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/0ppliger/oam-broker/wire"
+)
+
+// eventHistory is the number of past events kept for clients resuming
+// a stream with Last-Event-ID.
+const eventHistory = 1024
+
+type ServerSentEvent struct {
+	// ID increases with every event published on the bus.
+	ID    uint64
+	Event wire.EventType
+	Data  wire.Serializable
+}
+
+func (sse ServerSentEvent) Write(w http.ResponseWriter) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", sse.ID, sse.Event, sse.Data.JSON())
+}
+
+// EventBus delivers every published event to all its subscribers.
+// Publish blocks until each of them has received the event, so they
+// must keep reading from their channel until it is removed.
+type EventBus struct {
+	subscribers map[chan ServerSentEvent]bool
+	mutex       sync.Mutex
+	// history holds the last eventHistory events, oldest first.
+	history []ServerSentEvent
+	lastID  uint64
+}
+
+func New() *EventBus {
+	return &EventBus{
+		subscribers: make(map[chan ServerSentEvent]bool),
+	}
+}
+
+func (bus *EventBus) AddSubscriber() chan ServerSentEvent {
+	ch := make(chan ServerSentEvent)
+
+	bus.mutex.Lock()
+	bus.subscribers[ch] = true
+	bus.mutex.Unlock()
+
+	return ch
+}
+
+func (bus *EventBus) RemoveSubscriber(ch chan ServerSentEvent) {
+	bus.mutex.Lock()
+	delete(bus.subscribers, ch)
+	bus.mutex.Unlock()
+	close(ch)
+}
+
+func (bus *EventBus) Publish(event wire.EventType, data wire.Serializable) {
+
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	bus.lastID++
+	sse := ServerSentEvent{
+		ID:    bus.lastID,
+		Event: event,
+		Data:  data,
+	}
+
+	bus.history = append(bus.history, sse)
+	if len(bus.history) > eventHistory {
+		bus.history = bus.history[len(bus.history)-eventHistory:]
+	}
+
+	for ch := range bus.subscribers {
+		ch <- sse
+	}
+}
+
+// Resume adds a subscriber like AddSubscriber, and returns along with
+// it the events published after the one with ID last that are still in
+// the history. Events published afterwards go to the channel only, so
+// that none is missed or received twice.
+func (bus *EventBus) Resume(last uint64) (chan ServerSentEvent, []ServerSentEvent) {
+	ch := make(chan ServerSentEvent)
+
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	var missed []ServerSentEvent
+	for _, sse := range bus.history {
+		if sse.ID > last {
+			missed = append(missed, sse)
+		}
+	}
+	bus.subscribers[ch] = true
+
+	return ch, missed
+}
+
+// Subscribe returns a channel receiving the events of the given types,
+// or all events when no type is given. The channel is closed once ctx
+// is done.
+func (bus *EventBus) Subscribe(ctx context.Context, types ...wire.EventType) <-chan ServerSentEvent {
+	wanted := make(map[wire.EventType]bool)
+	for _, t := range types {
+		wanted[t] = true
+	}
+
+	ch := bus.AddSubscriber()
+	out := make(chan ServerSentEvent)
+
+	go func() {
+		defer close(out)
+		defer func() {
+			// Publish blocks on every subscriber: keep draining the
+			// channel until it is removed from the bus.
+			go func() {
+				for range ch {
+				}
+			}()
+			bus.RemoveSubscriber(ch)
+		}()
+
+		for {
+			select {
+			case sse := <-ch:
+				if len(wanted) > 0 && !wanted[sse.Event] {
+					continue
+				}
+				select {
+				case out <- sse:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
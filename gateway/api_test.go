@@ -0,0 +1,554 @@
+package gateway
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/0ppliger/oam-broker/memory"
+	oam "github.com/owasp-amass/open-asset-model"
+	oam_dns "github.com/owasp-amass/open-asset-model/dns"
+)
+
+// testGateway serves a gateway over an in-memory repository.
+type testGateway struct {
+	t   *testing.T
+	url string
+}
+
+func newTestGateway(t *testing.T, opts ...Option) *testGateway {
+	t.Helper()
+
+	g, err := New(append([]Option{WithRepository(memory.New())}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(g)
+	t.Cleanup(server.Close)
+
+	return &testGateway{t: t, url: server.URL}
+}
+
+// do sends a request and returns the status and body of the response.
+func (tg *testGateway) do(method, path, body string) (int, []byte) {
+	tg.t.Helper()
+
+	req, err := http.NewRequest(method, tg.url+path, strings.NewReader(body))
+	if err != nil {
+		tg.t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		tg.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		tg.t.Fatal(err)
+	}
+	return resp.StatusCode, out
+}
+
+// must sends a request that must succeed, and decodes its response in
+// out.
+func (tg *testGateway) must(method, path, body string, out any) {
+	tg.t.Helper()
+
+	status, response := tg.do(method, path, body)
+	if status != http.StatusOK {
+		tg.t.Fatalf("%s %s: %d %s", method, path, status, response)
+	}
+	if err := json.Unmarshal(response, out); err != nil {
+		tg.t.Fatalf("%s %s: %v in %s", method, path, err, response)
+	}
+}
+
+// status sends a request that must fail with the given status.
+func (tg *testGateway) status(method, path, body string, expected int) {
+	tg.t.Helper()
+
+	status, response := tg.do(method, path, body)
+	if status != expected {
+		tg.t.Errorf("%s %s: expected %d, got %d %s", method, path, expected, status, response)
+	}
+}
+
+func (tg *testGateway) entity(body string) Entity {
+	tg.t.Helper()
+
+	var out Entity
+	tg.must("POST", "/emit/entity", body, &out)
+	return out
+}
+
+func (tg *testGateway) edge(body string) Edge {
+	tg.t.Helper()
+
+	var out Edge
+	tg.must("POST", "/emit/edge", body, &out)
+	return out
+}
+
+func ipBody(address string) string {
+	return fmt.Sprintf(`{"type":"IPAddress","asset":{"address":%q,"type":"IPv4"}}`, address)
+}
+
+func dnsEdgeBody(from, to string, ttl int) string {
+	return fmt.Sprintf(`{"type":"BasicDNSRelation","relation":{"label":"dns_record","header":{"rr_type":1,"class":1,"ttl":%d}},"from_entity":%q,"to_entity":%q}`, ttl, from, to)
+}
+
+func propertyBody(field, id, value string) string {
+	return fmt.Sprintf(`{"type":"SimpleProperty","property":{"property_name":"source","property_value":%q},%q:%q}`, value, field, id)
+}
+
+// TestEmitLifecycle creates, reads, replaces and deletes an object of
+// every kind.
+func TestEmitLifecycle(t *testing.T) {
+	tg := newTestGateway(t)
+
+	fqdn := tg.entity(fqdnBody("www.example.com"))
+	ip := tg.entity(ipBody("192.0.2.1"))
+	edge := tg.edge(dnsEdgeBody(fqdn.ID, ip.ID, 60))
+
+	cases := []struct {
+		kind    string
+		create  string
+		replace string
+		// content is the field holding what replace changes.
+		content string
+	}{
+		{"entity", fqdnBody("api.example.com"), fqdnBody("app.example.com"), "asset"},
+		{"edge", dnsEdgeBody(fqdn.ID, ip.ID, 300), dnsEdgeBody(fqdn.ID, ip.ID, 3600), "relation"},
+		{"entity_tag", propertyBody("entity", fqdn.ID, "crawler"), propertyBody("entity", fqdn.ID, "resolver"), "property"},
+		{"edge_tag", propertyBody("edge", edge.ID, "crawler"), propertyBody("edge", edge.ID, "resolver"), "property"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.kind, func(t *testing.T) {
+			tg.t = t
+
+			var created map[string]json.RawMessage
+			tg.must("POST", "/emit/"+c.kind, c.create, &created)
+			var id string
+			json.Unmarshal(created["id"], &id)
+			if id == "" {
+				t.Fatalf("created %s has no ID", c.kind)
+			}
+
+			var read map[string]json.RawMessage
+			tg.must("GET", "/"+c.kind+"/"+id, "", &read)
+			if !reflect.DeepEqual(created, read) {
+				t.Errorf("read %v, expected %v", read, created)
+			}
+
+			var replaced map[string]json.RawMessage
+			tg.must("PUT", "/emit/"+c.kind+"/"+id, c.replace, &replaced)
+			var want map[string]json.RawMessage
+			json.Unmarshal([]byte(c.replace), &want)
+			if string(replaced["id"]) != string(created["id"]) {
+				t.Errorf("replacing changed the ID to %s", replaced["id"])
+			}
+			if !sameJSON(replaced[c.content], want[c.content]) {
+				t.Errorf("replaced %s is %s, expected %s", c.content, replaced[c.content], want[c.content])
+			}
+
+			var deleted map[string]json.RawMessage
+			tg.must("DELETE", "/emit/"+c.kind+"/"+id, "", &deleted)
+			if !sameJSON(deleted[c.content], want[c.content]) {
+				t.Errorf("deleted %s is %s, expected %s", c.content, deleted[c.content], want[c.content])
+			}
+			tg.status("GET", "/"+c.kind+"/"+id, "", http.StatusNotFound)
+			// Writes report missing objects as bad requests.
+			tg.status("DELETE", "/emit/"+c.kind+"/"+id, "", http.StatusBadRequest)
+		})
+	}
+}
+
+// sameJSON compares JSON documents regardless of their key order and
+// of the fields the gateway fills with default values.
+func sameJSON(got, want json.RawMessage) bool {
+	var got_value, want_value any
+	if json.Unmarshal(got, &got_value) != nil || json.Unmarshal(want, &want_value) != nil {
+		return false
+	}
+	return contains(got_value, want_value)
+}
+
+// contains reports whether got holds every field of want.
+func contains(got, want any) bool {
+	switch want := want.(type) {
+	case map[string]any:
+		got, ok := got.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, value := range want {
+			if !contains(got[key], value) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(got, want)
+	}
+}
+
+// zeroJSON returns the JSON of the zero value of a content struct, with
+// its label set for relations.
+func zeroJSON(t *testing.T, T reflect.Type, label string) json.RawMessage {
+	t.Helper()
+
+	content, err := json.Marshal(reflect.New(T).Interface())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label == "" {
+		return content
+	}
+
+	var fields map[string]any
+	json.Unmarshal(content, &fields)
+	fields["label"] = label
+	content, _ = json.Marshal(fields)
+	return content
+}
+
+// validRelationship finds asset types and a label between which the
+// taxonomy allows a relation type.
+func validRelationship(rtype oam.RelationType) (oam.AssetType, string, oam.AssetType, bool) {
+	var atypes []oam.AssetType
+	for atype := range assetTypes {
+		atypes = append(atypes, atype)
+	}
+	slices.Sort(atypes)
+
+	for _, from := range atypes {
+		labels := oam.GetAssetOutgoingRelations(from)
+		slices.Sort(labels)
+		for _, label := range labels {
+			for _, to := range oam.GetTransformAssetTypes(from, label, rtype) {
+				if _, ok := assetTypes[to]; ok {
+					return from, label, to, true
+				}
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+// TestEmitDecodesRegistries emits an object of every type of the three
+// registries and checks it is stored with its type and content.
+func TestEmitDecodesRegistries(t *testing.T) {
+	tg := newTestGateway(t)
+
+	roundTrip := func(t *testing.T, kind, body, content string) string {
+		tg.t = t
+
+		var created map[string]json.RawMessage
+		tg.must("POST", "/emit/"+kind, body, &created)
+
+		var read, sent map[string]json.RawMessage
+		tg.must("GET", "/"+kind+"/"+strings.Trim(string(created["id"]), `"`), "", &read)
+		json.Unmarshal([]byte(body), &sent)
+		if string(read["type"]) != string(sent["type"]) {
+			t.Errorf("stored as %s", read["type"])
+		}
+		if !sameJSON(read[content], sent[content]) {
+			t.Errorf("stored %s, sent %s", read[content], sent[content])
+		}
+		return strings.Trim(string(created["id"]), `"`)
+	}
+
+	entities := make(map[oam.AssetType]string)
+	for atype, T := range assetTypes {
+		t.Run("asset/"+string(atype), func(t *testing.T) {
+			body := fmt.Sprintf(`{"type":%q,"asset":%s}`, atype, zeroJSON(t, T, ""))
+			entities[atype] = roundTrip(t, "entity", body, "asset")
+		})
+	}
+
+	var edge string
+	for rtype, T := range relationTypes {
+		t.Run("relation/"+string(rtype), func(t *testing.T) {
+			from, label, to, ok := validRelationship(rtype)
+			if !ok {
+				t.Skipf("no relationship of the taxonomy uses %s", rtype)
+			}
+			body := fmt.Sprintf(`{"type":%q,"relation":%s,"from_entity":%q,"to_entity":%q}`, rtype, zeroJSON(t, T, label), entities[from], entities[to])
+			edge = roundTrip(t, "edge", body, "relation")
+		})
+	}
+	if edge == "" {
+		t.Fatal("no edge was created")
+	}
+
+	for ptype, T := range propertyTypes {
+		t.Run("property/"+string(ptype), func(t *testing.T) {
+			property := zeroJSON(t, T, "")
+			body := fmt.Sprintf(`{"type":%q,"property":%s,"entity":%q}`, ptype, property, entities[oam.FQDN])
+			roundTrip(t, "entity_tag", body, "property")
+			body = fmt.Sprintf(`{"type":%q,"property":%s,"edge":%q}`, ptype, property, edge)
+			roundTrip(t, "edge_tag", body, "property")
+		})
+	}
+}
+
+func TestEmitErrors(t *testing.T) {
+	tg := newTestGateway(t)
+
+	fqdn := tg.entity(fqdnBody("www.example.com"))
+	ip := tg.entity(ipBody("192.0.2.1"))
+	edge := tg.edge(dnsEdgeBody(fqdn.ID, ip.ID, 60))
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"unknown asset type", "POST", "/emit/entity", `{"type":"Spaceship","asset":{}}`, http.StatusBadRequest},
+		{"unknown relation type", "POST", "/emit/edge", fmt.Sprintf(`{"type":"Wormhole","relation":{},"from_entity":%q,"to_entity":%q}`, fqdn.ID, ip.ID), http.StatusBadRequest},
+		{"unknown entity tag property type", "POST", "/emit/entity_tag", fmt.Sprintf(`{"type":"Aura","property":{},"entity":%q}`, fqdn.ID), http.StatusBadRequest},
+		{"unknown edge tag property type", "POST", "/emit/edge_tag", fmt.Sprintf(`{"type":"Aura","property":{},"edge":%q}`, edge.ID), http.StatusBadRequest},
+		{"invalid JSON", "POST", "/emit/entity", `{"type":`, http.StatusBadRequest},
+		{"null edge", "POST", "/emit/edge", `null`, http.StatusBadRequest},
+		{"missing from entity", "POST", "/emit/edge", dnsEdgeBody("missing", ip.ID, 60), http.StatusBadRequest},
+		{"missing to entity", "POST", "/emit/edge", dnsEdgeBody(fqdn.ID, "missing", 60), http.StatusBadRequest},
+		{"relation outside the taxonomy", "POST", "/emit/edge", dnsEdgeBody(ip.ID, fqdn.ID, 60), http.StatusBadRequest},
+		{"missing tagged entity", "POST", "/emit/entity_tag", propertyBody("entity", "missing", "crawler"), http.StatusBadRequest},
+		{"missing tagged edge", "POST", "/emit/edge_tag", propertyBody("edge", "missing", "crawler"), http.StatusBadRequest},
+		{"missing replaced entity", "PUT", "/emit/entity/missing", fqdnBody("www.example.com"), http.StatusBadRequest},
+		{"missing deleted edge", "DELETE", "/emit/edge/missing", "", http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tg.t = t
+			tg.status(c.method, c.path, c.body, c.status)
+		})
+	}
+}
+
+// TestEmitStrictDecoding checks that invalid bodies are rejected with
+// the path of the offending field.
+func TestEmitStrictDecoding(t *testing.T) {
+	tg := newTestGateway(t, WithMaxBodySize(512))
+
+	fqdn := tg.entity(fqdnBody("www.example.com"))
+	ip := tg.entity(ipBody("192.0.2.1"))
+
+	cases := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		err    string
+	}{
+		{"unknown asset field", "/emit/entity", `{"type":"FQDN","asset":{"name":"a.example.com","nmae":"b"}}`, http.StatusBadRequest, "asset.nmae: unknown field"},
+		{"unknown nested field", "/emit/edge", fmt.Sprintf(`{"type":"BasicDNSRelation","relation":{"label":"dns_record","header":{"rr_tpye":1}},"from_entity":%q,"to_entity":%q}`, fqdn.ID, ip.ID), http.StatusBadRequest, "relation.header.rr_tpye: unknown field"},
+		{"unknown property field", "/emit/entity_tag", fmt.Sprintf(`{"type":"SimpleProperty","property":{"property_name":"a","extra":true},"entity":%q}`, fqdn.ID), http.StatusBadRequest, "property.extra: unknown field"},
+		{"mistyped field", "/emit/entity", `{"type":"FQDN","asset":{"name":42}}`, http.StatusBadRequest, "asset.name: expected string, got number"},
+		{"mistyped nested field", "/emit/edge", fmt.Sprintf(`{"type":"BasicDNSRelation","relation":{"label":"dns_record","header":{"rr_type":"A"}},"from_entity":%q,"to_entity":%q}`, fqdn.ID, ip.ID), http.StatusBadRequest, "relation.header.rr_type: expected int, got string"},
+		{"mistyped object field", "/emit/entity", `{"type":"FQDN","id":1,"asset":{"name":"a.example.com"}}`, http.StatusBadRequest, "id: expected string, got number"},
+		{"missing content", "/emit/entity", `{"type":"FQDN"}`, http.StatusBadRequest, "asset: missing"},
+		{"unknown type", "/emit/entity", `{"type":"Spaceship","asset":{}}`, http.StatusBadRequest, "type: unsupported asset type: Spaceship"},
+		{"trailing data", "/emit/entity", fqdnBody("a.example.com") + `{}`, http.StatusBadRequest, "invalid JSON"},
+		{"body too large", "/emit/entity", fqdnBody(strings.Repeat("a", 512) + ".example.com"), http.StatusRequestEntityTooLarge, "too large"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tg.t = t
+
+			status, response := tg.do("POST", c.path, c.body)
+			if status != c.status {
+				t.Errorf("expected %d, got %d %s", c.status, status, response)
+			}
+			if !strings.Contains(string(response), c.err) {
+				t.Errorf("expected an error mentioning %q, got %s", c.err, response)
+			}
+		})
+	}
+
+	t.Run("patch", func(t *testing.T) {
+		tg.t = t
+
+		req, _ := http.NewRequest("PATCH", tg.url+"/emit/entity/"+fqdn.ID, strings.NewReader(`{"asset":{"nmae":"b"}}`))
+		req.Header.Set("Content-Type", MergePatchType)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(response), "asset.nmae: unknown field") {
+			t.Errorf("expected 400 for an unknown field, got %d %s", resp.StatusCode, response)
+		}
+	})
+}
+
+func TestEmitLenientDecoding(t *testing.T) {
+	tg := newTestGateway(t, WithLenientDecoding())
+
+	out := tg.entity(`{"type":"FQDN","asset":{"name":"a.example.com","nmae":"b"}}`)
+	if fqdn, ok := out.Asset.(*oam_dns.FQDN); !ok || fqdn.Name != "a.example.com" {
+		t.Errorf("decoded %#v", out.Asset)
+	}
+	tg.status("POST", "/emit/entity", fqdnBody("a.example.com")+`{}`, http.StatusBadRequest)
+}
+
+// sse is an event read from /listen.
+type sse struct {
+	id    string
+	event EventType
+	data  map[string]json.RawMessage
+}
+
+// listen subscribes to the events of the gateway.
+func (tg *testGateway) listen() <-chan sse {
+	tg.t.Helper()
+
+	resp, err := http.Get(tg.url + "/listen")
+	if err != nil {
+		tg.t.Fatal(err)
+	}
+	tg.t.Cleanup(func() { resp.Body.Close() })
+
+	events := make(chan sse)
+	go func() {
+		defer close(events)
+
+		var event sse
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			field, value, _ := strings.Cut(scanner.Text(), ": ")
+			switch field {
+			case "id":
+				event.id = value
+			case "event":
+				event.event = EventType(value)
+			case "data":
+				json.Unmarshal([]byte(value), &event.data)
+			case "":
+				events <- event
+				event = sse{}
+			}
+		}
+	}()
+	return events
+}
+
+func TestListenEvents(t *testing.T) {
+	tg := newTestGateway(t)
+	events := tg.listen()
+
+	fqdn := tg.entity(fqdnBody("www.example.com"))
+	tg.entity(fqdnBody("www.example.com"))
+	var updated Entity
+	tg.must("PUT", "/emit/entity/"+fqdn.ID, fqdnBody("app.example.com"), &updated)
+	ip := tg.entity(ipBody("192.0.2.1"))
+	edge := tg.edge(dnsEdgeBody(fqdn.ID, ip.ID, 60))
+	tg.edge(dnsEdgeBody(fqdn.ID, ip.ID, 60))
+	var deleted Edge
+	tg.must("DELETE", "/emit/edge/"+edge.ID, "", &deleted)
+	var deleted_entity Entity
+	tg.must("DELETE", "/emit/entity/"+ip.ID, "", &deleted_entity)
+
+	expected := []struct {
+		event EventType
+		id    string
+	}{
+		{EntityCreated, fqdn.ID},
+		{EntityTouched, fqdn.ID},
+		{EntityUpdated, fqdn.ID},
+		{EntityCreated, ip.ID},
+		{EdgeCreated, edge.ID},
+		{EdgeTouched, edge.ID},
+		{EdgeDeleted, edge.ID},
+		{EntityDeleted, ip.ID},
+	}
+
+	for i, want := range expected {
+		got, ok := <-events
+		if !ok {
+			t.Fatalf("stream ended after %d events", i)
+		}
+		if got.id != fmt.Sprint(i+1) {
+			t.Errorf("event %d has ID %s", i, got.id)
+		}
+		if got.event != want.event {
+			t.Errorf("event %d is %s, expected %s", i, got.event, want.event)
+		}
+
+		// The changes of updates are listed next to the object.
+		data := got.data
+		if want.event == EntityUpdated {
+			if _, ok := data["changes"]; !ok {
+				t.Errorf("%s event carries no changes", got.event)
+			}
+		}
+		if string(data["id"]) != fmt.Sprintf("%q", want.id) {
+			t.Errorf("event %d is about %s, expected %s", i, data["id"], want.id)
+		}
+	}
+}
+
+func TestTagEvents(t *testing.T) {
+	tg := newTestGateway(t)
+	fqdn := tg.entity(fqdnBody("www.example.com"))
+	ip := tg.entity(ipBody("192.0.2.1"))
+	edge := tg.edge(dnsEdgeBody(fqdn.ID, ip.ID, 60))
+	events := tg.listen()
+
+	var entity_tag EntityTag
+	tg.must("POST", "/emit/entity_tag", propertyBody("entity", fqdn.ID, "dns"), &entity_tag)
+	tg.must("POST", "/emit/entity_tag", propertyBody("entity", fqdn.ID, "dns"), &entity_tag)
+	tg.must("PUT", "/emit/entity_tag/"+entity_tag.ID, propertyBody("entity", fqdn.ID, "whois"), &entity_tag)
+	tg.must("DELETE", "/emit/entity_tag/"+entity_tag.ID, "", &entity_tag)
+
+	var edge_tag EdgeTag
+	tg.must("POST", "/emit/edge_tag", propertyBody("edge", edge.ID, "dns"), &edge_tag)
+	tg.must("POST", "/emit/edge_tag", propertyBody("edge", edge.ID, "dns"), &edge_tag)
+	tg.must("PUT", "/emit/edge_tag/"+edge_tag.ID, propertyBody("edge", edge.ID, "whois"), &edge_tag)
+	tg.must("DELETE", "/emit/edge_tag/"+edge_tag.ID, "", &edge_tag)
+
+	expected := []struct {
+		event   EventType
+		id      string
+		changes string
+	}{
+		{EntityTagCreated, entity_tag.ID, ""},
+		{EntityTagTouched, entity_tag.ID, ""},
+		{EntityTagUpdated, entity_tag.ID, `[{"op":"replace","path":"/property/property_value","value":"whois"}]`},
+		{EntityTagDeleted, entity_tag.ID, ""},
+		{EdgeTagCreated, edge_tag.ID, ""},
+		{EdgeTagTouched, edge_tag.ID, ""},
+		{EdgeTagUpdated, edge_tag.ID, `[{"op":"replace","path":"/property/property_value","value":"whois"}]`},
+		{EdgeTagDeleted, edge_tag.ID, ""},
+	}
+
+	for i, want := range expected {
+		got, ok := <-events
+		if !ok {
+			t.Fatalf("stream ended after %d events", i)
+		}
+		if got.event != want.event {
+			t.Errorf("event %d is %s, expected %s", i, got.event, want.event)
+		}
+		if string(got.data["id"]) != fmt.Sprintf("%q", want.id) {
+			t.Errorf("event %d is about %s, expected %s", i, got.data["id"], want.id)
+		}
+		if changes := string(got.data["changes"]); changes != want.changes {
+			t.Errorf("event %d has changes %s, expected %s", i, changes, want.changes)
+		}
+	}
+}
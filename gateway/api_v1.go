@@ -0,0 +1,28 @@
+package gateway
+
+import (
+	"context"
+	
+	"github.com/sirupsen/logrus"
+)
+
+type ApiV1 struct {
+	ctx context.Context
+	store Repository
+	bus *EventBus
+	logger *logrus.Logger
+	// tailing is set when a StoreWatcher publishes creations and
+	// updates, so handlers must not publish them a second time.
+	tailing bool
+	locks keyedMutex
+	jobs JobManager
+	// prefix is the path the gateway is mounted under, for the links
+	// it returns.
+	prefix string
+	// lenient accepts fields the content of objects does not have.
+	lenient bool
+	// admin_keys are required by the admin operations, such as pruning.
+	admin_keys keySet
+	// openAssetDB opens the Amass SQLite files imported, when set.
+	openAssetDB func(path string) (Repository, error)
+}
@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	// DefaultMaxBodySize bounds the request bodies of the routes without
+	// a limit of their own.
+	DefaultMaxBodySize = 1 << 20
+	// DefaultImportMaxBodySize bounds the files sent to /import.
+	DefaultImportMaxBodySize = 1 << 30
+)
+
+// limitBody bounds the body of the requests to handler to max bytes.
+// Reading past it fails with an *http.MaxBytesError, answered with 413
+// by errorStatus.
+func limitBody(handler http.Handler, max int64) http.Handler {
+	if max <= 0 {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, max)
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// decodable is implemented by the objects of the wire package, whose
+// content is decoded according to their type.
+type decodable interface {
+	Decode(data []byte, strict bool) error
+}
+
+// decodeObject decodes obj from data, rejecting the fields its content
+// does not have unless the gateway is lenient.
+func (api *ApiV1) decodeObject(data []byte, obj decodable) error {
+	return obj.Decode(data, !api.lenient)
+}
+
+// readObject decodes obj from the body of r. Data after the object is
+// rejected.
+func (api *ApiV1) readObject(r *http.Request, obj decodable) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return api.decodeObject(body, obj)
+}
+
+// readJSON decodes the body of r in v, rejecting data after the value.
+func readJSON(r *http.Request, v any) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"fmt"
+	"time"
+)
+
+// ConnectedError is returned when deleting an entity that still has
+// edges without cascading.
+type ConnectedError struct {
+	Edges int
+}
+
+func (e *ConnectedError) Error() string {
+	return fmt.Sprintf("entity has %d edges, delete with ?cascade=true", e.Edges)
+}
+
+// removeEntityCascade deletes the entity with the given ID, and when
+// cascading, its edges and tags, publishing a delete event for each of
+// them. They are collected under the lock of the entity, which writes
+// connecting objects to it hold too, so that none is left behind. The
+// cascade is returned with the entity as it was before deletion.
+func (api *ApiV1) removeEntityCascade(id string, if_match string, cascading bool) (Cascade, error) {
+	unlock := api.locks.Lock("entity:" + id)
+	defer unlock()
+
+	cascade, err := api.findCascade(id)
+	if err != nil {
+		return cascade, err
+	}
+
+	if len(cascade.Edges) > 0 && !cascading {
+		return cascade, &ConnectedError{Edges: len(cascade.Edges)}
+	}
+
+	// Checked before anything gets deleted, and again on the entity.
+	if !ifMatch(if_match, cascade.Entity) {
+		return cascade, ErrPreconditionFailed
+	}
+
+	if err := api.removeCascade(cascade); err != nil {
+		return cascade, fmt.Errorf("failed to delete connected objects: %w", err)
+	}
+
+	out, outcome, err := api.removeLockedEntity(id, if_match)
+	if err != nil {
+		return cascade, err
+	}
+	cascade.Entity = EntityFromStore(out)
+
+	// Deletions leave nothing behind for the store watcher to
+	// observe, so they are always published from here.
+	api.bus.Publish(outcome.EntityEvent(), cascade.Entity)
+
+	return cascade, nil
+}
+
+// findCascade collects the entity with the given ID and every object
+// connected to it. What is found may change unless the lock of the
+// entity is held.
+func (api *ApiV1) findCascade(id string) (Cascade, error) {
+	cascade := Cascade{
+		Edges:      []Edge{},
+		EntityTags: []EntityTag{},
+		EdgeTags:   []EdgeTag{},
+	}
+
+	entity, err := api.store.FindEntityById(api.ctx, id)
+	if err != nil {
+		return cascade, fmt.Errorf("cannot find entity: %w", err)
+	}
+	cascade.Entity = EntityFromStore(entity)
+
+	// The store reports empty results as errors.
+	in, _ := api.store.IncomingEdges(api.ctx, entity, time.Time{})
+	out, _ := api.store.OutgoingEdges(api.ctx, entity, time.Time{})
+
+	seen := make(map[string]bool)
+	for _, edge := range append(in, out...) {
+		// Self-referencing edges are both incoming and outgoing.
+		if seen[edge.ID] {
+			continue
+		}
+		seen[edge.ID] = true
+		cascade.Edges = append(cascade.Edges, EdgeFromStore(edge))
+
+		tags, _ := api.store.GetEdgeTags(api.ctx, edge, time.Time{})
+		for _, tag := range tags {
+			cascade.EdgeTags = append(cascade.EdgeTags, EdgeTagFromStore(tag))
+		}
+	}
+
+	tags, _ := api.store.GetEntityTags(api.ctx, entity, time.Time{})
+	for _, tag := range tags {
+		cascade.EntityTags = append(cascade.EntityTags, EntityTagFromStore(tag))
+	}
+
+	return cascade, nil
+}
+
+// removeCascade deletes the edges and tags of a cascade, publishing a
+// delete event for each of them. The entity itself is left to the
+// caller.
+func (api *ApiV1) removeCascade(cascade Cascade) error {
+	for _, tag := range cascade.EdgeTags {
+		out, err := api.removeEdgeTag(tag.ID, "")
+		if err != nil {
+			return err
+		}
+		api.bus.Publish(EdgeTagDeleted, EdgeTagFromStore(out))
+	}
+
+	for _, edge := range cascade.Edges {
+		out, outcome, err := api.removeEdge(edge.ID, "")
+		if err != nil {
+			return err
+		}
+		api.bus.Publish(outcome.EdgeEvent(), EdgeFromStore(out))
+	}
+
+	for _, tag := range cascade.EntityTags {
+		out, err := api.removeEntityTag(tag.ID, "")
+		if err != nil {
+			return err
+		}
+		api.bus.Publish(EntityTagDeleted, EntityTagFromStore(out))
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0ppliger/oam-broker/memory"
+	"github.com/owasp-amass/asset-db/repository"
+	dbt "github.com/owasp-amass/asset-db/types"
+	oam_dns "github.com/owasp-amass/open-asset-model/dns"
+	"github.com/owasp-amass/open-asset-model/network"
+)
+
+func TestDeleteCascade(t *testing.T) {
+	tg := newTestGateway(t)
+	fqdn := tg.entity(fqdnBody("www.example.com"))
+	ip := tg.entity(ipBody("192.0.2.1"))
+	lone := tg.entity(fqdnBody("lone.example.com"))
+	edge := tg.edge(dnsEdgeBody(fqdn.ID, ip.ID, 60))
+	var entity_tag EntityTag
+	tg.must("POST", "/emit/entity_tag", propertyBody("entity", ip.ID, "dns"), &entity_tag)
+	var edge_tag EdgeTag
+	tg.must("POST", "/emit/edge_tag", propertyBody("edge", edge.ID, "dns"), &edge_tag)
+	events := tg.listen()
+
+	tg.status("DELETE", "/emit/entity/"+ip.ID+"?cascade=maybe", "", http.StatusBadRequest)
+
+	status, body := tg.do("DELETE", "/emit/entity/"+ip.ID, "")
+	if status != http.StatusConflict || !strings.Contains(string(body), "entity has 1 edges") {
+		t.Errorf("delete of a connected entity: %d %s", status, body)
+	}
+	tg.status("DELETE", "/emit/entity/"+ip.ID+"?cascade=false", "", http.StatusConflict)
+
+	var preview Cascade
+	tg.must("DELETE", "/emit/entity/"+ip.ID+"?cascade=preview", "", &preview)
+	if preview.Entity.ID != ip.ID || len(preview.Edges) != 1 || len(preview.EntityTags) != 1 || len(preview.EdgeTags) != 1 {
+		t.Errorf("preview: %+v", preview)
+	}
+
+	// Neither the conflict nor the preview deleted anything.
+	var still Entity
+	tg.must("GET", "/entity/"+ip.ID, "", &still)
+
+	var deleted Cascade
+	tg.must("DELETE", "/emit/entity/"+ip.ID+"?cascade=true", "", &deleted)
+	if deleted.Entity.ID != ip.ID || len(deleted.Edges) != 1 || deleted.Edges[0].ID != edge.ID {
+		t.Errorf("cascade: %+v", deleted)
+	}
+	for _, path := range []string{"/entity/" + ip.ID, "/edge/" + edge.ID, "/entity_tag/" + entity_tag.ID, "/edge_tag/" + edge_tag.ID} {
+		tg.status("GET", path, "", http.StatusNotFound)
+	}
+
+	// An entity without edges needs no cascade.
+	var deleted_lone Entity
+	tg.must("DELETE", "/emit/entity/"+lone.ID, "", &deleted_lone)
+
+	expected := []struct {
+		event EventType
+		id    string
+	}{
+		{EdgeTagDeleted, edge_tag.ID},
+		{EdgeDeleted, edge.ID},
+		{EntityTagDeleted, entity_tag.ID},
+		{EntityDeleted, ip.ID},
+		{EntityDeleted, lone.ID},
+	}
+	for i, want := range expected {
+		got, ok := <-events
+		if !ok {
+			t.Fatalf("stream ended after %d events", i)
+		}
+		if got.event != want.event || string(got.data["id"]) != fmt.Sprintf("%q", want.id) {
+			t.Errorf("event %d is %s %s, expected %s %q", i, got.event, got.data["id"], want.event, want.id)
+		}
+	}
+}
+
+// slowCascadeStore delays the lookup of incoming edges, done when
+// collecting a cascade, for edges to be created meanwhile.
+type slowCascadeStore struct {
+	repository.Repository
+}
+
+func (ss slowCascadeStore) IncomingEdges(ctx context.Context, entity *dbt.Entity, since time.Time, labels ...string) ([]*dbt.Edge, error) {
+	edges, err := ss.Repository.IncomingEdges(ctx, entity, since, labels...)
+	time.Sleep(20 * time.Millisecond)
+	return edges, err
+}
+
+// TestDeleteCascadeUnderConcurrency connects entities to an entity while
+// it is deleted: every edge created must be reported deleted.
+func TestDeleteCascadeUnderConcurrency(t *testing.T) {
+	const n = 20
+
+	store := slowCascadeStore{memory.New()}
+	api := newTestApi(store)
+
+	ip, err := store.CreateEntity(api.ctx, &dbt.Entity{Asset: &network.IPAddress{Address: netip.MustParseAddr("192.0.2.1"), Type: "IPv4"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sources []string
+	for i := 0; i < n; i++ {
+		out, err := store.CreateEntity(api.ctx, &dbt.Entity{Asset: &oam_dns.FQDN{Name: fmt.Sprintf("www-%d.example.com", i)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, out.ID)
+	}
+
+	ch := api.bus.AddSubscriber()
+	deleted := make(map[string]bool)
+	collected := make(chan struct{})
+	go func() {
+		for sse := range ch {
+			if sse.Event == EdgeDeleted {
+				deleted[sse.Data.(Edge).ID] = true
+			}
+		}
+		close(collected)
+	}()
+
+	var mutex sync.Mutex
+	var created []string
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(time.Duration(i) * time.Millisecond)
+
+			req := httptest.NewRequest("POST", "/", strings.NewReader(dnsEdgeBody(sources[i], ip.ID, 60)))
+			rec := httptest.NewRecorder()
+			api.CreateEdge(rec, req)
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var edge Edge
+			if err := json.Unmarshal(rec.Body.Bytes(), &edge); err != nil {
+				t.Error(err)
+				return
+			}
+			mutex.Lock()
+			created = append(created, edge.ID)
+			mutex.Unlock()
+		}()
+	}
+
+	req := httptest.NewRequest("DELETE", "/?cascade=true", nil)
+	req.SetPathValue("id", ip.ID)
+	rec := httptest.NewRecorder()
+	api.DeleteEntity(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", rec.Code, rec.Body.String())
+	}
+	wg.Wait()
+
+	api.bus.RemoveSubscriber(ch)
+	<-collected
+
+	for _, id := range created {
+		if !deleted[id] {
+			t.Errorf("edge %s deleted without event", id)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package gateway
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ignoredPaths are refreshed on every write and do not make an update
+// meaningful on their own.
+var ignoredPaths = map[string]bool{
+	"/last_seen": true,
+}
+
+// Diff returns the changes between two serialized objects, leaving out
+// the ignored paths. An empty result means the write only touched the
+// object.
+func Diff(before, after Serializable) []Change {
+	var a, b any
+	if err := json.Unmarshal(before.JSON(), &a); err != nil {
+		return nil
+	}
+	if err := json.Unmarshal(after.JSON(), &b); err != nil {
+		return nil
+	}
+	return diffValues("", a, b, nil)
+}
+
+func diffValues(path string, a, b any, changes []Change) []Change {
+	if ignoredPaths[path] {
+		return changes
+	}
+
+	a_obj, a_ok := a.(map[string]any)
+	b_obj, b_ok := b.(map[string]any)
+	if !a_ok || !b_ok {
+		if !reflect.DeepEqual(a, b) {
+			changes = append(changes, Change{Op: "replace", Path: path, Value: b})
+		}
+		return changes
+	}
+
+	keys := make([]string, 0, len(a_obj)+len(b_obj))
+	for k := range a_obj {
+		keys = append(keys, k)
+	}
+	for k := range b_obj {
+		if _, ok := a_obj[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		child := path + "/" + escapePointer(k)
+		if ignoredPaths[child] {
+			continue
+		}
+
+		a_val, in_a := a_obj[k]
+		b_val, in_b := b_obj[k]
+		switch {
+		case !in_b:
+			changes = append(changes, Change{Op: "remove", Path: child})
+		case !in_a:
+			changes = append(changes, Change{Op: "add", Path: child, Value: b_val})
+		default:
+			changes = diffValues(child, a_val, b_val, changes)
+		}
+	}
+	return changes
+}
+
+// escapePointer escapes a key for use as a JSON Pointer (RFC 6901)
+// reference token.
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// Changed decorates the payload of an update event with the changes
+// made to the object.
+type Changed struct {
+	Data    Serializable
+	Changes []Change
+}
+
+func (c Changed) JSON() []byte {
+	data := c.Data.JSON()
+	changes, err := json.Marshal(c.Changes)
+	if err != nil || len(data) < 2 || data[len(data)-1] != '}' {
+		return data
+	}
+
+	out := append([]byte{}, data[:len(data)-1]...)
+	if len(data) > 2 {
+		out = append(out, ',')
+	}
+	out = append(out, `"changes":`...)
+	out = append(out, changes...)
+	return append(out, '}')
+}
@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	oam_dns "github.com/owasp-amass/open-asset-model/dns"
+	"github.com/owasp-amass/open-asset-model/general"
+)
+
+// rawJSON serializes a document given as JSON.
+type rawJSON string
+
+func (r rawJSON) JSON() []byte {
+	return []byte(r)
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	dnsEdge := func(ttl int, seen time.Time) Serializable {
+		return Edge{
+			ID:         "1",
+			CreatedAt:  now,
+			LastSeen:   seen,
+			Type:       "BasicDNSRelation",
+			Relation:   &oam_dns.BasicDNSRelation{Name: "dns_record", Header: oam_dns.RRHeader{RRType: 1, Class: 1, TTL: ttl}},
+			FromEntity: "2",
+			ToEntity:   "3",
+		}
+	}
+	tag := func(value string, seen time.Time) Serializable {
+		return EntityTag{
+			ID:        "1",
+			CreatedAt: now,
+			LastSeen:  seen,
+			Type:      "SimpleProperty",
+			Property:  &general.SimpleProperty{PropertyName: "source", PropertyValue: value},
+			Entity:    "2",
+		}
+	}
+
+	cases := []struct {
+		name          string
+		before, after Serializable
+		changes       []Change
+	}{
+		{"entity asset", Entity{ID: "1", CreatedAt: now, LastSeen: now, Type: "FQDN", Asset: &oam_dns.FQDN{Name: "a.example.com"}},
+			Entity{ID: "1", CreatedAt: now, LastSeen: later, Type: "FQDN", Asset: &oam_dns.FQDN{Name: "b.example.com"}},
+			[]Change{{Op: "replace", Path: "/asset/name", Value: "b.example.com"}}},
+		{"edge relation", dnsEdge(60, now), dnsEdge(300, later),
+			[]Change{{Op: "replace", Path: "/relation/header/ttl", Value: float64(300)}}},
+		{"edge endpoint", dnsEdge(60, now), Edge{ID: "1", CreatedAt: now, LastSeen: now, Type: "BasicDNSRelation",
+			Relation: &oam_dns.BasicDNSRelation{Name: "dns_record", Header: oam_dns.RRHeader{RRType: 1, Class: 1, TTL: 60}}, FromEntity: "2", ToEntity: "4"},
+			[]Change{{Op: "replace", Path: "/to_entity", Value: "4"}}},
+		{"edge touched", dnsEdge(60, now), dnsEdge(60, later), nil},
+		{"tag property", tag("dns", now), tag("whois", later),
+			[]Change{{Op: "replace", Path: "/property/property_value", Value: "whois"}}},
+		{"tag touched", tag("dns", now), tag("dns", later), nil},
+		{"null member", rawJSON(`{"a":1}`), rawJSON(`{"a":1,"b":null}`),
+			[]Change{{Op: "add", Path: "/b", Value: nil}}},
+		{"null value", rawJSON(`{"a":1}`), rawJSON(`{"a":null}`),
+			[]Change{{Op: "replace", Path: "/a", Value: nil}}},
+		{"removed member", rawJSON(`{"a":1,"b":2}`), rawJSON(`{"a":1}`),
+			[]Change{{Op: "remove", Path: "/b"}}},
+		{"escaped key", rawJSON(`{"a/b~c":1}`), rawJSON(`{"a/b~c":2}`),
+			[]Change{{Op: "replace", Path: "/a~1b~0c", Value: float64(2)}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Diff(c.before, c.after); !reflect.DeepEqual(got, c.changes) {
+				t.Errorf("got %#v, expected %#v", got, c.changes)
+			}
+		})
+	}
+}
+
+func TestChangeJSON(t *testing.T) {
+	cases := []struct {
+		change Change
+		json   string
+	}{
+		{Change{Op: "add", Path: "/a", Value: nil}, `{"op":"add","path":"/a","value":null}`},
+		{Change{Op: "replace", Path: "/a", Value: nil}, `{"op":"replace","path":"/a","value":null}`},
+		{Change{Op: "replace", Path: "/a", Value: "b"}, `{"op":"replace","path":"/a","value":"b"}`},
+		{Change{Op: "remove", Path: "/a"}, `{"op":"remove","path":"/a"}`},
+	}
+	for _, c := range cases {
+		got, err := json.Marshal(c.change)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != c.json {
+			t.Errorf("%s %v: got %s, expected %s", c.change.Op, c.change.Value, got, c.json)
+		}
+	}
+}
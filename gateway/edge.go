@@ -0,0 +1,202 @@
+package gateway
+
+import (
+	"net/http"
+	"time"
+)
+
+func (api *ApiV1) CreateEdge(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		http.Error(w, "no body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var input Edge
+
+	if err := api.readObject(r, &input); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	created_edge, err := api.createEdge(input)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, created_edge)
+}
+
+func (api *ApiV1) GetEdge(w http.ResponseWriter, r *http.Request) {
+	out, err := api.store.FindEdgeById(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Cannot find edge: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeObject(w, EdgeFromStore(out))
+}
+
+func (api *ApiV1) DeleteEdge(w http.ResponseWriter, r *http.Request) {
+	deleted_edge, err := api.deleteEdge(r.PathValue("id"), r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, deleted_edge)
+}
+
+func (api *ApiV1) UpdateEdge(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		http.Error(w, "no body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var input Edge
+
+	if err := api.readObject(r, &input); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	updated_edge, err := api.updateEdge(r.PathValue("id"), input, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, updated_edge)
+}
+
+func (api *ApiV1) PatchEdge(w http.ResponseWriter, r *http.Request) {
+	patch, err := requestPatch(r)
+	if err != nil {
+		http.Error(w, "invalid patch: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	updated_edge, err := api.patchEdge(r.PathValue("id"), patch, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, updated_edge)
+}
+
+// createEdge writes input as a new edge, or over the edge with the same
+// content between the same entities.
+func (api *ApiV1) createEdge(input Edge) (Edge, error) {
+	from_entity, err := api.store.FindEntityById(api.ctx, input.FromEntity)
+	if err != nil {
+		return Edge{}, serviceError(http.StatusBadRequest, "Cannot find from entity: ", err)
+	}
+
+	to_entity, err := api.store.FindEntityById(api.ctx, input.ToEntity)
+	if err != nil {
+		return Edge{}, serviceError(http.StatusBadRequest, "Cannot find to entity: ", err)
+	}
+
+	out, outcome, changes, err := api.upsertEdge(input.ToStore(from_entity, to_entity), "")
+	if err != nil {
+		return Edge{}, serviceError(http.StatusBadRequest, "Failed to upsert asset: ", err)
+	}
+	created_edge := EdgeFromStore(out)
+
+	if !api.tailing {
+		api.bus.Publish(outcome.EdgeEvent(), eventData(created_edge, changes))
+	}
+
+	return created_edge, nil
+}
+
+// updateEdge writes input over the existing edge with the given ID.
+func (api *ApiV1) updateEdge(id string, input Edge, if_match string) (Edge, error) {
+	if _, err := api.store.FindEdgeById(api.ctx, id); err != nil {
+		return Edge{}, serviceError(http.StatusBadRequest, "Cannot find to edge: ", err)
+	}
+
+	return api.replaceEdge(id, input, if_match)
+}
+
+// patchEdge applies patch to the edge with the given ID.
+func (api *ApiV1) patchEdge(id string, patch Patch, if_match string) (Edge, error) {
+	for {
+		out, err := api.store.FindEdgeById(api.ctx, id)
+		if err != nil {
+			return Edge{}, serviceError(http.StatusBadRequest, "Cannot find edge: ", err)
+		}
+		patched := EdgeFromStore(out)
+
+		json_body, err := patch.Apply(patched.JSON())
+		if err != nil {
+			return Edge{}, serviceError(http.StatusBadRequest, "invalid patch: ", err)
+		}
+
+		var input Edge
+
+		if err := api.decodeObject(json_body, &input); err != nil {
+			return Edge{}, serviceError(http.StatusBadRequest, "invalid JSON: ", err)
+		}
+
+		// The patched document carries the stored LastSeen, which the
+		// store would otherwise keep.
+		input.LastSeen = time.Time{}
+
+		// Applied again over a version written in between, as in
+		// patchEntity.
+		expected := if_match
+		if expected == "" {
+			expected = ETag(patched)
+		}
+
+		updated_edge, err := api.replaceEdge(id, input, expected)
+		if if_match == "" && errorStatus(err) == http.StatusPreconditionFailed {
+			continue
+		}
+		return updated_edge, err
+	}
+}
+
+// replaceEdge writes input over the existing edge with the given ID.
+func (api *ApiV1) replaceEdge(id string, input Edge, if_match string) (Edge, error) {
+	from_entity, err := api.store.FindEntityById(api.ctx, input.FromEntity)
+	if err != nil {
+		return Edge{}, serviceError(http.StatusBadRequest, "Cannot find from entity: ", err)
+	}
+
+	to_entity, err := api.store.FindEntityById(api.ctx, input.ToEntity)
+	if err != nil {
+		return Edge{}, serviceError(http.StatusBadRequest, "Cannot find to entity: ", err)
+	}
+
+	input.ID = id
+
+	out, outcome, changes, err := api.upsertEdge(input.ToStore(from_entity, to_entity), if_match)
+	if err != nil {
+		return Edge{}, serviceError(errorStatus(err), "Failed to upsert asset: ", err)
+	}
+	updated_edge := EdgeFromStore(out)
+
+	if !api.tailing {
+		api.bus.Publish(outcome.EdgeEvent(), eventData(updated_edge, changes))
+	}
+
+	return updated_edge, nil
+}
+
+// deleteEdge deletes the edge with the given ID.
+func (api *ApiV1) deleteEdge(id string, if_match string) (Edge, error) {
+	out, outcome, err := api.removeEdge(id, if_match)
+	if err != nil {
+		return Edge{}, serviceError(errorStatus(err), "", err)
+	}
+	deleted_edge := EdgeFromStore(out)
+
+	api.bus.Publish(outcome.EdgeEvent(), deleted_edge)
+
+	return deleted_edge, nil
+}
@@ -0,0 +1,198 @@
+package gateway
+
+import (
+	"net/http"
+	"time"
+)
+
+func (api *ApiV1) CreateEdgeTag(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		http.Error(w, "no body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var input EdgeTag
+
+	if err := api.readObject(r, &input); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	created_edge_tag, err := api.createEdgeTag(input)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, created_edge_tag)
+}
+
+func (api *ApiV1) GetEdgeTag(w http.ResponseWriter, r *http.Request) {
+	out, err := api.store.FindEdgeTagById(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Cannot find edge tag: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeObject(w, EdgeTagFromStore(out))
+}
+
+func (api *ApiV1) DeleteEdgeTag(w http.ResponseWriter, r *http.Request) {
+	deleted_edge_tag, err := api.deleteEdgeTag(r.PathValue("id"), r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, deleted_edge_tag)
+}
+
+func (api *ApiV1) UpdateEdgeTag(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		http.Error(w, "no body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var input EdgeTag
+
+	if err := api.readObject(r, &input); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	updated_edge_tag, err := api.updateEdgeTag(r.PathValue("id"), input, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, updated_edge_tag)
+}
+
+func (api *ApiV1) PatchEdgeTag(w http.ResponseWriter, r *http.Request) {
+	patch, err := requestPatch(r)
+	if err != nil {
+		http.Error(w, "invalid patch: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	updated_edge_tag, err := api.patchEdgeTag(r.PathValue("id"), patch, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, updated_edge_tag)
+}
+
+// createEdgeTag writes input as a new edge tag, or over the tag of its edge with
+// the same property.
+func (api *ApiV1) createEdgeTag(input EdgeTag) (EdgeTag, error) {
+	edge, err := api.store.FindEdgeById(api.ctx, input.Edge)
+	if err != nil {
+		return EdgeTag{}, serviceError(http.StatusBadRequest, "Cannot find to edge: ", err)
+	}
+
+	edge_tag := input.ToStore()
+	edge_tag.Edge = edge
+
+	out, outcome, changes, err := api.upsertEdgeTag(edge_tag, "")
+	if err != nil {
+		return EdgeTag{}, serviceError(http.StatusBadRequest, "Failed to upsert asset: ", err)
+	}
+	created_edge_tag := EdgeTagFromStore(out)
+
+	if !api.tailing {
+		api.bus.Publish(outcome.EdgeTagEvent(), eventData(created_edge_tag, changes))
+	}
+
+	return created_edge_tag, nil
+}
+
+// updateEdgeTag writes input over the existing edge tag with the given ID.
+func (api *ApiV1) updateEdgeTag(id string, input EdgeTag, if_match string) (EdgeTag, error) {
+	if _, err := api.store.FindEdgeTagById(api.ctx, id); err != nil {
+		return EdgeTag{}, serviceError(http.StatusBadRequest, "Cannot find to edge tag: ", err)
+	}
+
+	return api.replaceEdgeTag(id, input, if_match)
+}
+
+// patchEdgeTag applies patch to the edge tag with the given ID.
+func (api *ApiV1) patchEdgeTag(id string, patch Patch, if_match string) (EdgeTag, error) {
+	for {
+		out, err := api.store.FindEdgeTagById(api.ctx, id)
+		if err != nil {
+			return EdgeTag{}, serviceError(http.StatusBadRequest, "Cannot find edge tag: ", err)
+		}
+		patched := EdgeTagFromStore(out)
+
+		json_body, err := patch.Apply(patched.JSON())
+		if err != nil {
+			return EdgeTag{}, serviceError(http.StatusBadRequest, "invalid patch: ", err)
+		}
+
+		var input EdgeTag
+
+		if err := api.decodeObject(json_body, &input); err != nil {
+			return EdgeTag{}, serviceError(http.StatusBadRequest, "invalid JSON: ", err)
+		}
+
+		// The patched document carries the stored LastSeen, which the
+		// store would otherwise keep.
+		input.LastSeen = time.Time{}
+
+		// Applied again over a version written in between, as in
+		// patchEntity.
+		expected := if_match
+		if expected == "" {
+			expected = ETag(patched)
+		}
+
+		updated_edge_tag, err := api.replaceEdgeTag(id, input, expected)
+		if if_match == "" && errorStatus(err) == http.StatusPreconditionFailed {
+			continue
+		}
+		return updated_edge_tag, err
+	}
+}
+
+// replaceEdgeTag writes input over the existing edge tag with the
+// given ID.
+func (api *ApiV1) replaceEdgeTag(id string, input EdgeTag, if_match string) (EdgeTag, error) {
+	edge, err := api.store.FindEdgeById(api.ctx, input.Edge)
+	if err != nil {
+		return EdgeTag{}, serviceError(http.StatusBadRequest, "Cannot find to edge: ", err)
+	}
+
+	input.ID = id
+	edge_tag := input.ToStore()
+	edge_tag.Edge = edge
+
+	out, outcome, changes, err := api.upsertEdgeTag(edge_tag, if_match)
+	if err != nil {
+		return EdgeTag{}, serviceError(errorStatus(err), "Failed to upsert asset: ", err)
+	}
+	updated_edge_tag := EdgeTagFromStore(out)
+
+	if !api.tailing {
+		api.bus.Publish(outcome.EdgeTagEvent(), eventData(updated_edge_tag, changes))
+	}
+
+	return updated_edge_tag, nil
+}
+
+// deleteEdgeTag deletes the edge tag with the given ID.
+func (api *ApiV1) deleteEdgeTag(id string, if_match string) (EdgeTag, error) {
+	out, err := api.removeEdgeTag(id, if_match)
+	if err != nil {
+		return EdgeTag{}, serviceError(errorStatus(err), "", err)
+	}
+	deleted_edge_tag := EdgeTagFromStore(out)
+
+	api.bus.Publish(EdgeTagDeleted, deleted_edge_tag)
+
+	return deleted_edge_tag, nil
+}
@@ -0,0 +1,221 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+func (api *ApiV1) CreateEntity(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		http.Error(w, "no body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	json_body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Error reading request body: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	api.logger.Debug("CreateEntity: Receive body: ", string(json_body))
+
+	var input Entity
+
+	if err := api.decodeObject(json_body, &input); err != nil {
+		api.logger.Info("invalid JSON: " + err.Error())
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	api.logger.Debug("CreateEntity: Parse JSON: ", input)
+
+	created_entity, err := api.createEntity(input)
+	if err != nil {
+		api.logger.Info(err.Error())
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, created_entity)
+}
+
+func (api *ApiV1) GetEntity(w http.ResponseWriter, r *http.Request) {
+	out, err := api.store.FindEntityById(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Cannot find entity: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeObject(w, EntityFromStore(out))
+}
+
+// DeleteEntity refuses to delete an entity connected to others by edges
+// unless ?cascade=true is given, in which case the edges and tags are
+// deleted along with it. ?cascade=preview only lists them.
+func (api *ApiV1) DeleteEntity(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	mode := r.URL.Query().Get("cascade")
+	if mode != "" && mode != "true" && mode != "false" && mode != "preview" {
+		http.Error(w, "invalid cascade: "+mode, http.StatusBadRequest)
+		return
+	}
+
+	if mode == "preview" {
+		cascade, err := api.findCascade(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("ETag", ETag(cascade.Entity))
+		w.Write(cascade.JSON())
+		return
+	}
+
+	cascade, err := api.deleteEntity(id, r.Header.Get("If-Match"), mode == "true")
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	if mode == "true" {
+		w.Header().Set("ETag", ETag(cascade.Entity))
+		w.Write(cascade.JSON())
+		return
+	}
+
+	writeObject(w, cascade.Entity)
+}
+
+func (api *ApiV1) UpdateEntity(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		http.Error(w, "no body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var input Entity
+
+	if err := api.readObject(r, &input); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	updated_entity, err := api.updateEntity(r.PathValue("id"), input, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, updated_entity)
+}
+
+func (api *ApiV1) PatchEntity(w http.ResponseWriter, r *http.Request) {
+	patch, err := requestPatch(r)
+	if err != nil {
+		http.Error(w, "invalid patch: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	updated_entity, err := api.patchEntity(r.PathValue("id"), patch, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, updated_entity)
+}
+
+// createEntity writes input as a new entity, or over the entity with
+// the same content.
+func (api *ApiV1) createEntity(input Entity) (Entity, error) {
+	out, outcome, changes, err := api.upsertEntity(input.ToStore(), "")
+	if err != nil {
+		return Entity{}, serviceError(http.StatusBadRequest, "Failed to upsert asset: ", err)
+	}
+	created_entity := EntityFromStore(out)
+
+	if !api.tailing {
+		api.bus.Publish(outcome.EntityEvent(), eventData(created_entity, changes))
+	}
+
+	return created_entity, nil
+}
+
+// updateEntity writes input over the existing entity with the given ID.
+func (api *ApiV1) updateEntity(id string, input Entity, if_match string) (Entity, error) {
+	if _, err := api.store.FindEntityById(api.ctx, id); err != nil {
+		return Entity{}, serviceError(http.StatusBadRequest, "Cannot find to entity: ", err)
+	}
+
+	return api.replaceEntity(id, input, if_match)
+}
+
+// patchEntity applies patch to the entity with the given ID.
+func (api *ApiV1) patchEntity(id string, patch Patch, if_match string) (Entity, error) {
+	for {
+		out, err := api.store.FindEntityById(api.ctx, id)
+		if err != nil {
+			return Entity{}, serviceError(http.StatusBadRequest, "Cannot find entity: ", err)
+		}
+		patched := EntityFromStore(out)
+
+		json_body, err := patch.Apply(patched.JSON())
+		if err != nil {
+			return Entity{}, serviceError(http.StatusBadRequest, "invalid patch: ", err)
+		}
+
+		var input Entity
+
+		if err := api.decodeObject(json_body, &input); err != nil {
+			return Entity{}, serviceError(http.StatusBadRequest, "invalid JSON: ", err)
+		}
+
+		// The patched document carries the stored LastSeen, which the
+		// store would otherwise keep.
+		input.LastSeen = time.Time{}
+
+		// Without If-Match, the patch is only written over the version it
+		// was applied to, and applied again over a version written in
+		// between, for no write to be lost.
+		expected := if_match
+		if expected == "" {
+			expected = ETag(patched)
+		}
+
+		updated_entity, err := api.replaceEntity(id, input, expected)
+		if if_match == "" && errorStatus(err) == http.StatusPreconditionFailed {
+			continue
+		}
+		return updated_entity, err
+	}
+}
+
+// replaceEntity writes input over the existing entity with the given ID.
+func (api *ApiV1) replaceEntity(id string, input Entity, if_match string) (Entity, error) {
+	input.ID = id
+
+	out, outcome, changes, err := api.upsertEntity(input.ToStore(), if_match)
+	if err != nil {
+		return Entity{}, serviceError(errorStatus(err), "Failed to upsert asset: ", err)
+	}
+	updated_entity := EntityFromStore(out)
+
+	if !api.tailing {
+		api.bus.Publish(outcome.EntityEvent(), eventData(updated_entity, changes))
+	}
+
+	return updated_entity, nil
+}
+
+// deleteEntity deletes the entity with the given ID, along with its
+// edges and tags when cascading.
+func (api *ApiV1) deleteEntity(id string, if_match string, cascading bool) (Cascade, error) {
+	cascade, err := api.removeEntityCascade(id, if_match, cascading)
+	if err != nil {
+		return Cascade{}, serviceError(errorStatus(err), "", err)
+	}
+	return cascade, nil
+}
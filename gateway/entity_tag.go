@@ -0,0 +1,198 @@
+package gateway
+
+import (
+	"net/http"
+	"time"
+)
+
+func (api *ApiV1) CreateEntityTag(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		http.Error(w, "no body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var input EntityTag
+
+	if err := api.readObject(r, &input); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	created_entity_tag, err := api.createEntityTag(input)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, created_entity_tag)
+}
+
+func (api *ApiV1) GetEntityTag(w http.ResponseWriter, r *http.Request) {
+	out, err := api.store.FindEntityTagById(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Cannot find entity tag: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeObject(w, EntityTagFromStore(out))
+}
+
+func (api *ApiV1) DeleteEntityTag(w http.ResponseWriter, r *http.Request) {
+	deleted_entity_tag, err := api.deleteEntityTag(r.PathValue("id"), r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, deleted_entity_tag)
+}
+
+func (api *ApiV1) UpdateEntityTag(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		http.Error(w, "no body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var input EntityTag
+
+	if err := api.readObject(r, &input); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	updated_entity_tag, err := api.updateEntityTag(r.PathValue("id"), input, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, updated_entity_tag)
+}
+
+func (api *ApiV1) PatchEntityTag(w http.ResponseWriter, r *http.Request) {
+	patch, err := requestPatch(r)
+	if err != nil {
+		http.Error(w, "invalid patch: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	updated_entity_tag, err := api.patchEntityTag(r.PathValue("id"), patch, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	writeObject(w, updated_entity_tag)
+}
+
+// createEntityTag writes input as a new entity tag, or over the tag of its entity with
+// the same property.
+func (api *ApiV1) createEntityTag(input EntityTag) (EntityTag, error) {
+	entity, err := api.store.FindEntityById(api.ctx, input.Entity)
+	if err != nil {
+		return EntityTag{}, serviceError(http.StatusBadRequest, "Cannot find entity tag: ", err)
+	}
+
+	entity_tag := input.ToStore()
+	entity_tag.Entity = entity
+
+	out, outcome, changes, err := api.upsertEntityTag(entity_tag, "")
+	if err != nil {
+		return EntityTag{}, serviceError(http.StatusBadRequest, "Failed to upsert entity: ", err)
+	}
+	created_entity_tag := EntityTagFromStore(out)
+
+	if !api.tailing {
+		api.bus.Publish(outcome.EntityTagEvent(), eventData(created_entity_tag, changes))
+	}
+
+	return created_entity_tag, nil
+}
+
+// updateEntityTag writes input over the existing entity tag with the given ID.
+func (api *ApiV1) updateEntityTag(id string, input EntityTag, if_match string) (EntityTag, error) {
+	if _, err := api.store.FindEntityTagById(api.ctx, id); err != nil {
+		return EntityTag{}, serviceError(http.StatusBadRequest, "Cannot find entity tag: ", err)
+	}
+
+	return api.replaceEntityTag(id, input, if_match)
+}
+
+// patchEntityTag applies patch to the entity tag with the given ID.
+func (api *ApiV1) patchEntityTag(id string, patch Patch, if_match string) (EntityTag, error) {
+	for {
+		out, err := api.store.FindEntityTagById(api.ctx, id)
+		if err != nil {
+			return EntityTag{}, serviceError(http.StatusBadRequest, "Cannot find entity tag: ", err)
+		}
+		patched := EntityTagFromStore(out)
+
+		json_body, err := patch.Apply(patched.JSON())
+		if err != nil {
+			return EntityTag{}, serviceError(http.StatusBadRequest, "invalid patch: ", err)
+		}
+
+		var input EntityTag
+
+		if err := api.decodeObject(json_body, &input); err != nil {
+			return EntityTag{}, serviceError(http.StatusBadRequest, "invalid JSON: ", err)
+		}
+
+		// The patched document carries the stored LastSeen, which the
+		// store would otherwise keep.
+		input.LastSeen = time.Time{}
+
+		// Applied again over a version written in between, as in
+		// patchEntity.
+		expected := if_match
+		if expected == "" {
+			expected = ETag(patched)
+		}
+
+		updated_entity_tag, err := api.replaceEntityTag(id, input, expected)
+		if if_match == "" && errorStatus(err) == http.StatusPreconditionFailed {
+			continue
+		}
+		return updated_entity_tag, err
+	}
+}
+
+// replaceEntityTag writes input over the existing entity tag with the
+// given ID.
+func (api *ApiV1) replaceEntityTag(id string, input EntityTag, if_match string) (EntityTag, error) {
+	entity, err := api.store.FindEntityById(api.ctx, input.Entity)
+	if err != nil {
+		return EntityTag{}, serviceError(http.StatusBadRequest, "Cannot find entity: ", err)
+	}
+
+	input.ID = id
+	entity_tag := input.ToStore()
+	entity_tag.Entity = entity
+
+	out, outcome, changes, err := api.upsertEntityTag(entity_tag, if_match)
+	if err != nil {
+		return EntityTag{}, serviceError(errorStatus(err), "Failed to update entity: ", err)
+	}
+	updated_entity_tag := EntityTagFromStore(out)
+
+	if !api.tailing {
+		api.bus.Publish(outcome.EntityTagEvent(), eventData(updated_entity_tag, changes))
+	}
+
+	return updated_entity_tag, nil
+}
+
+// deleteEntityTag deletes the entity tag with the given ID.
+func (api *ApiV1) deleteEntityTag(id string, if_match string) (EntityTag, error) {
+	out, err := api.removeEntityTag(id, if_match)
+	if err != nil {
+		return EntityTag{}, serviceError(errorStatus(err), "", err)
+	}
+	deleted_entity_tag := EntityTagFromStore(out)
+
+	api.bus.Publish(EntityTagDeleted, deleted_entity_tag)
+
+	return deleted_entity_tag, nil
+}
@@ -0,0 +1,322 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0ppliger/oam-broker/eventbus"
+	"github.com/owasp-amass/asset-db/repository"
+	dbt "github.com/owasp-amass/asset-db/types"
+	oam "github.com/owasp-amass/open-asset-model"
+	oam_dns "github.com/owasp-amass/open-asset-model/dns"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeStore implements the entity operations of repository.Repository
+// the way the asset store does: upserts by ID, otherwise deduplicated
+// by content. GetLastEvent is left unimplemented on purpose: handlers
+// must not rely on it.
+type fakeStore struct {
+	repository.Repository
+
+	mutex    sync.Mutex
+	entities map[string]*dbt.Entity
+	next_id  int
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{entities: make(map[string]*dbt.Entity)}
+}
+
+func (fs *fakeStore) CreateEntity(ctx context.Context, input *dbt.Entity) (*dbt.Entity, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	now := time.Now()
+
+	if input.ID == "" {
+		for _, e := range fs.entities {
+			if sameContent(e.Asset, input.Asset) {
+				input.ID = e.ID
+			}
+		}
+	}
+
+	if prev, ok := fs.entities[input.ID]; ok {
+		updated := *prev
+		updated.Asset = input.Asset
+		updated.LastSeen = now
+		fs.entities[input.ID] = &updated
+		out := updated
+		return &out, nil
+	}
+
+	fs.next_id++
+	created := &dbt.Entity{
+		ID:        fmt.Sprint(fs.next_id),
+		CreatedAt: now,
+		LastSeen:  now,
+		Asset:     input.Asset,
+	}
+	fs.entities[created.ID] = created
+	out := *created
+	return &out, nil
+}
+
+func (fs *fakeStore) FindEntityById(ctx context.Context, id string) (*dbt.Entity, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	e, ok := fs.entities[id]
+	if !ok {
+		return nil, errors.New("entity not found")
+	}
+	out := *e
+	return &out, nil
+}
+
+func (fs *fakeStore) FindEntitiesByContent(ctx context.Context, asset oam.Asset, since time.Time) ([]*dbt.Entity, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	for _, e := range fs.entities {
+		if sameContent(e.Asset, asset) {
+			out := *e
+			return []*dbt.Entity{&out}, nil
+		}
+	}
+	return nil, errors.New("no entities found")
+}
+
+func (fs *fakeStore) DeleteEntity(ctx context.Context, id string) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if _, ok := fs.entities[id]; !ok {
+		return errors.New("entity not found")
+	}
+	delete(fs.entities, id)
+	return nil
+}
+
+// The fake store holds no edges or tags, and reports empty results
+// as errors like the real one.
+func (fs *fakeStore) IncomingEdges(ctx context.Context, entity *dbt.Entity, since time.Time, labels ...string) ([]*dbt.Edge, error) {
+	return nil, errors.New("zero edges found")
+}
+
+func (fs *fakeStore) OutgoingEdges(ctx context.Context, entity *dbt.Entity, since time.Time, labels ...string) ([]*dbt.Edge, error) {
+	return nil, errors.New("zero edges found")
+}
+
+func (fs *fakeStore) GetEntityTags(ctx context.Context, entity *dbt.Entity, since time.Time, names ...string) ([]*dbt.EntityTag, error) {
+	return nil, errors.New("zero tags found")
+}
+
+func newTestApi(store repository.Repository) *ApiV1 {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	return &ApiV1{
+		ctx:    context.Background(),
+		store:  store,
+		bus:    eventbus.New(),
+		logger: logger,
+	}
+}
+
+func fqdnBody(name string) string {
+	return fmt.Sprintf(`{"type":"FQDN","asset":{"name":%q}}`, name)
+}
+
+func TestEntityEventsUnderConcurrency(t *testing.T) {
+	const n = 50
+
+	store := newFakeStore()
+	api := newTestApi(store)
+
+	seed := func(name string) string {
+		out, err := store.CreateEntity(api.ctx, &dbt.Entity{Asset: &oam_dns.FQDN{Name: name}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out.ID
+	}
+
+	var to_update, to_delete []string
+	for i := 0; i < n; i++ {
+		to_update = append(to_update, seed(fmt.Sprintf("update-%d.example.com", i)))
+		seed(fmt.Sprintf("touch-%d.example.com", i))
+		to_delete = append(to_delete, seed(fmt.Sprintf("delete-%d.example.com", i)))
+	}
+
+	ch := api.bus.AddSubscriber()
+	received := make(map[string][]EventType)
+	collected := make(chan struct{})
+	go func() {
+		for sse := range ch {
+			data := sse.Data
+			if changed, ok := data.(Changed); ok {
+				if sse.Event != EntityUpdated {
+					t.Errorf("%s event carries changes", sse.Event)
+				}
+				data = changed.Data
+			} else if sse.Event == EntityUpdated {
+				t.Errorf("%s event carries no changes", sse.Event)
+			}
+
+			entity := data.(Entity)
+			received[entity.ID] = append(received[entity.ID], sse.Event)
+		}
+		close(collected)
+	}()
+
+	var mutex sync.Mutex
+	expected := make(map[string]EventType)
+
+	do := func(handler http.HandlerFunc, method, id, body string, event EventType) {
+		req := httptest.NewRequest(method, "/", strings.NewReader(body))
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+
+		handler(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s %s: %d %s", method, id, rec.Code, rec.Body.String())
+			return
+		}
+
+		var out Entity
+		if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+			t.Error(err)
+			return
+		}
+
+		mutex.Lock()
+		expected[out.ID] = event
+		mutex.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			do(api.CreateEntity, "POST", "", fqdnBody(fmt.Sprintf("new-%d.example.com", i)), EntityCreated)
+		}()
+		go func() {
+			defer wg.Done()
+			do(api.UpdateEntity, "PUT", to_update[i], fqdnBody(fmt.Sprintf("updated-%d.example.com", i)), EntityUpdated)
+		}()
+		go func() {
+			defer wg.Done()
+			// Re-emitting known content only refreshes LastSeen.
+			do(api.CreateEntity, "POST", "", fqdnBody(fmt.Sprintf("touch-%d.example.com", i)), EntityTouched)
+		}()
+		go func() {
+			defer wg.Done()
+			do(api.DeleteEntity, "DELETE", to_delete[i], "", EntityDeleted)
+		}()
+	}
+	wg.Wait()
+
+	api.bus.RemoveSubscriber(ch)
+	<-collected
+
+	if len(expected) != 4*n {
+		t.Fatalf("expected %d distinct entities, got %d", 4*n, len(expected))
+	}
+
+	for id, event := range expected {
+		got := received[id]
+		if len(got) != 1 || got[0] != event {
+			t.Errorf("entity %s: expected [%s], got %v", id, event, got)
+		}
+	}
+}
+
+// slowLookupStore delays the lookups by content, so that concurrent
+// writes land between the lookup and the write that follows it.
+type slowLookupStore struct {
+	*fakeStore
+}
+
+func (ss slowLookupStore) FindEntitiesByContent(ctx context.Context, asset oam.Asset, since time.Time) ([]*dbt.Entity, error) {
+	found, err := ss.fakeStore.FindEntitiesByContent(ctx, asset, since)
+	time.Sleep(10 * time.Millisecond)
+	return found, err
+}
+
+// TestEntityLocksAcrossRoutes replaces entities with the content that
+// is emitted at the same time: the emit, deduplicated into the replaced
+// entity or not, must not report it as created.
+func TestEntityLocksAcrossRoutes(t *testing.T) {
+	const n = 50
+
+	store := slowLookupStore{newFakeStore()}
+	api := newTestApi(store)
+
+	var ids []string
+	for i := 0; i < n; i++ {
+		out, err := store.CreateEntity(api.ctx, &dbt.Entity{Asset: &oam_dns.FQDN{Name: fmt.Sprintf("old-%d.example.com", i)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, out.ID)
+	}
+
+	ch := api.bus.AddSubscriber()
+	created := make(map[string]bool)
+	collected := make(chan struct{})
+	go func() {
+		for sse := range ch {
+			if sse.Event == EntityCreated {
+				created[sse.Data.(Entity).ID] = true
+			}
+		}
+		close(collected)
+	}()
+
+	do := func(handler http.HandlerFunc, method, id, body string) {
+		req := httptest.NewRequest(method, "/", strings.NewReader(body))
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s %s: %d %s", method, id, rec.Code, rec.Body.String())
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		body := fqdnBody(fmt.Sprintf("new-%d.example.com", i))
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			do(api.UpdateEntity, "PUT", ids[i], body)
+		}()
+		go func() {
+			defer wg.Done()
+			do(api.CreateEntity, "POST", "", body)
+		}()
+	}
+	wg.Wait()
+
+	api.bus.RemoveSubscriber(ch)
+	<-collected
+
+	for _, id := range ids {
+		if created[id] {
+			t.Errorf("entity %s reported as created", id)
+		}
+	}
+}
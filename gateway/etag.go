@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrPreconditionFailed is returned by store operations when the
+// If-Match header of the request does not match the stored object.
+var ErrPreconditionFailed = errors.New("precondition failed: the object was modified")
+
+// ETag identifies a version of a stored object. Its serialization holds
+// both the content and LastSeen, so any write yields a new ETag.
+func ETag(obj Serializable) string {
+	sum := sha256.Sum256(obj.JSON())
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ifMatch reports whether the If-Match header value if_match allows a
+// write over current. An empty header always does.
+func ifMatch(if_match string, current Serializable) bool {
+	if if_match == "" {
+		return true
+	}
+
+	etag := ETag(current)
+	for _, candidate := range strings.Split(if_match, ",") {
+		candidate = strings.TrimSpace(candidate)
+		// Weak tags never match under the strong comparison If-Match
+		// requires.
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// errorStatus maps errors of operations, of store operations and of
+// reading request bodies to HTTP status codes.
+func errorStatus(err error) int {
+	var service_error *ServiceError
+	if errors.As(err, &service_error) {
+		return service_error.Status
+	}
+	if errors.Is(err, ErrPreconditionFailed) {
+		return http.StatusPreconditionFailed
+	}
+	var connected *ConnectedError
+	if errors.As(err, &connected) {
+		return http.StatusConflict
+	}
+	var too_large *http.MaxBytesError
+	if errors.As(err, &too_large) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// writeObject writes obj as the response body along with its ETag.
+func writeObject(w http.ResponseWriter, obj Serializable) {
+	w.Header().Set("ETag", ETag(obj))
+	w.Write(obj.JSON())
+}
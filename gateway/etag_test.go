@@ -0,0 +1,119 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/0ppliger/oam-broker/memory"
+	"github.com/owasp-amass/asset-db/repository"
+	dbt "github.com/owasp-amass/asset-db/types"
+)
+
+// truncatingStore reads timestamps back at the microsecond, like the
+// Postgres store does.
+type truncatingStore struct {
+	repository.Repository
+}
+
+func (ts truncatingStore) FindEntityById(ctx context.Context, id string) (*dbt.Entity, error) {
+	out, err := ts.Repository.FindEntityById(ctx, id)
+	if err == nil {
+		out.CreatedAt = out.CreatedAt.Truncate(time.Microsecond)
+		out.LastSeen = out.LastSeen.Truncate(time.Microsecond)
+	}
+	return out, err
+}
+
+func (ts truncatingStore) FindEdgeById(ctx context.Context, id string) (*dbt.Edge, error) {
+	out, err := ts.Repository.FindEdgeById(ctx, id)
+	if err == nil {
+		out.CreatedAt = out.CreatedAt.Truncate(time.Microsecond)
+		out.LastSeen = out.LastSeen.Truncate(time.Microsecond)
+	}
+	return out, err
+}
+
+func newETagGateway(t *testing.T) string {
+	t.Helper()
+
+	g, err := New(WithRepository(truncatingStore{memory.New()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(g)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestETagOfWritesMatchesReads(t *testing.T) {
+	url := newETagGateway(t)
+
+	resp, _ := request(t, "POST", url+"/emit/entity", fqdnBody("www.example.com"), nil)
+	expectStatus(t, resp, http.StatusOK)
+	written := resp.Header.Get("ETag")
+
+	resp, body := request(t, "GET", url+"/entity/1", "", nil)
+	expectStatus(t, resp, http.StatusOK)
+	if read := resp.Header.Get("ETag"); read != written || read != ETag(rawJSON(body)) {
+		t.Errorf("written with ETag %s, read with %s", written, read)
+	}
+
+	// The ETag of the write is good for the next one.
+	resp, _ = request(t, "PUT", url+"/emit/entity/1", fqdnBody("app.example.com"), map[string]string{"If-Match": written})
+	expectStatus(t, resp, http.StatusOK)
+	replaced := resp.Header.Get("ETag")
+	resp, _ = request(t, "GET", url+"/entity/1", "", nil)
+	if read := resp.Header.Get("ETag"); read != replaced {
+		t.Errorf("replaced with ETag %s, read with %s", replaced, read)
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	url := newETagGateway(t)
+
+	resp, _ := request(t, "POST", url+"/emit/entity", fqdnBody("www.example.com"), nil)
+	expectStatus(t, resp, http.StatusOK)
+	stale := resp.Header.Get("ETag")
+	resp, _ = request(t, "PUT", url+"/emit/entity/1", fqdnBody("app.example.com"), nil)
+	expectStatus(t, resp, http.StatusOK)
+	current := resp.Header.Get("ETag")
+	if current == stale {
+		t.Fatal("the ETag did not change on write")
+	}
+
+	cases := []struct {
+		name     string
+		method   string
+		body     string
+		if_match string
+		status   int
+	}{
+		{"stale replace", "PUT", fqdnBody("old.example.com"), stale, http.StatusPreconditionFailed},
+		{"stale patch", "PATCH", `{"asset":{"name":"old.example.com"}}`, stale, http.StatusPreconditionFailed},
+		{"stale delete", "DELETE", "", stale, http.StatusPreconditionFailed},
+		{"weak tag", "PUT", fqdnBody("old.example.com"), "W/" + current, http.StatusPreconditionFailed},
+		{"tag in a list", "PUT", fqdnBody("app.example.com"), `"0000", ` + current, http.StatusOK},
+		{"any tag", "PUT", fqdnBody("www.example.com"), "*", http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp, body := request(t, c.method, url+"/emit/entity/1", c.body, map[string]string{"If-Match": c.if_match})
+			if resp.StatusCode != c.status {
+				t.Fatalf("expected %d, got %d %s", c.status, resp.StatusCode, body)
+			}
+			if c.status == http.StatusOK {
+				current = resp.Header.Get("ETag")
+			}
+		})
+	}
+
+	resp, _ = request(t, "DELETE", url+"/emit/entity/1", "", map[string]string{"If-Match": "*"})
+	expectStatus(t, resp, http.StatusOK)
+	resp, _ = request(t, "DELETE", url+"/emit/entity/1", "", map[string]string{"If-Match": "*"})
+	if resp.StatusCode == http.StatusOK {
+		t.Error("deleted a missing entity")
+	}
+}
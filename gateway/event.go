@@ -0,0 +1,59 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// ListenEvents streams the events of the bus. Clients reconnecting with
+// the Last-Event-ID header first receive the events they missed, as far
+// as the history goes back.
+func (api *ApiV1) ListenEvents(w http.ResponseWriter, r *http.Request) {
+	var ch chan ServerSentEvent
+	var missed []ServerSentEvent
+
+	if last_event_id := r.Header.Get("Last-Event-ID"); last_event_id != "" {
+		last, err := strconv.ParseUint(last_event_id, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid Last-Event-ID: "+last_event_id, http.StatusBadRequest)
+			return
+		}
+		ch, missed = api.bus.Resume(last)
+	} else {
+		ch = api.bus.AddSubscriber()
+	}
+	defer func() {
+		// Reconnecting clients leave often: keep draining so that a
+		// concurrent Publish does not block on the channel.
+		go func() {
+			for range ch {
+			}
+		}()
+		api.bus.RemoveSubscriber(ch)
+	}()
+	
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	for _, sse := range missed {
+		sse.Write(w)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case sse := <-ch:
+			sse.Write(w)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
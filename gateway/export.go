@@ -0,0 +1,601 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dbt "github.com/owasp-amass/asset-db/types"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+// GraphFilter selects a subgraph of the asset store.
+type GraphFilter struct {
+	// AssetTypes restricts the entities to these types. All types when
+	// empty.
+	AssetTypes []oam.AssetType
+	// Root, when set, restricts the graph to the entities within Depth
+	// hops of this entity.
+	Root  string
+	Depth int
+	// Relations restricts the edges to these labels. All labels when
+	// empty.
+	Relations []string
+	// Limit caps the number of entities, when set.
+	Limit int
+	// SkipTags leaves the tags out of the graph.
+	SkipTags bool
+	// Since and Until bound the LastSeen of every object, when set.
+	Since time.Time
+	Until time.Time
+}
+
+func (f GraphFilter) inWindow(last_seen time.Time) bool {
+	return (f.Since.IsZero() || !last_seen.Before(f.Since)) &&
+		(f.Until.IsZero() || !last_seen.After(f.Until))
+}
+
+func (f GraphFilter) hasType(atype oam.AssetType) bool {
+	if len(f.AssetTypes) == 0 {
+		return true
+	}
+	for _, t := range f.AssetTypes {
+		if t == atype {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrGraphTruncated reports that a graph was cut at GraphFilter.Limit
+// entities.
+var ErrGraphTruncated = errors.New("graph truncated")
+
+// TruncatedTrailer is the trailer of /export set to "true" when the
+// graph was cut at the limit. It is a trailer since the graph is written
+// before the cut is known.
+const TruncatedTrailer = "X-Graph-Truncated"
+
+// GraphFilterFromQuery reads a GraphFilter from the asset_types, root,
+// depth, relations, limit, since and until query parameters.
+func GraphFilterFromQuery(r *http.Request) (GraphFilter, error) {
+	query := r.URL.Query()
+	filter := GraphFilter{
+		Root:  query.Get("root"),
+		Depth: 1,
+	}
+
+	for _, value := range query["asset_types"] {
+		for _, atype := range strings.Split(value, ",") {
+			if _, ok := assetTypes[oam.AssetType(atype)]; !ok {
+				return filter, fmt.Errorf("unsupported asset type: %s", atype)
+			}
+			filter.AssetTypes = append(filter.AssetTypes, oam.AssetType(atype))
+		}
+	}
+
+	for _, value := range query["relations"] {
+		filter.Relations = append(filter.Relations, strings.Split(value, ",")...)
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 0 {
+			return filter, fmt.Errorf("invalid limit: %s", limit)
+		}
+		filter.Limit = l
+	}
+
+	if depth := query.Get("depth"); depth != "" {
+		d, err := strconv.Atoi(depth)
+		if err != nil || d < 0 {
+			return filter, fmt.Errorf("invalid depth: %s", depth)
+		}
+		filter.Depth = d
+	}
+
+	for name, t := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s: %s", name, value)
+			}
+			*t = parsed
+		}
+	}
+
+	return filter, nil
+}
+
+// walkEntities calls visit for every entity selected by filter. The
+// store returns the entities of a type at once, so the entities of one
+// type are held in memory at a time. It returns ErrGraphTruncated when
+// more than filter.Limit entities are selected.
+func (api *ApiV1) walkEntities(ctx context.Context, filter GraphFilter, visit func(*dbt.Entity) error) error {
+	if filter.Limit > 0 {
+		visited, next := 0, visit
+		visit = func(entity *dbt.Entity) error {
+			if visited == filter.Limit {
+				return ErrGraphTruncated
+			}
+			visited++
+			return next(entity)
+		}
+	}
+
+	if filter.Root != "" {
+		return api.walkFrom(ctx, filter, visit)
+	}
+
+	atypes := filter.AssetTypes
+	if len(atypes) == 0 {
+		for atype := range assetTypes {
+			atypes = append(atypes, atype)
+		}
+		sort.Slice(atypes, func(i, j int) bool { return atypes[i] < atypes[j] })
+	}
+
+	for _, atype := range atypes {
+		// The store reports empty results as errors.
+		entities, _ := api.store.FindEntitiesByType(ctx, atype, filter.Since)
+		for _, entity := range entities {
+			if !filter.inWindow(entity.LastSeen) {
+				continue
+			}
+			if err := visit(entity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walkFrom visits the entities within filter.Depth hops of the root,
+// breadth first, following edges in both directions. Every entity is
+// visited once, however many cycles lead back to it.
+func (api *ApiV1) walkFrom(ctx context.Context, filter GraphFilter, visit func(*dbt.Entity) error) error {
+	root, err := api.store.FindEntityById(ctx, filter.Root)
+	if err != nil {
+		return fmt.Errorf("cannot find root entity: %w", err)
+	}
+
+	seen := map[string]bool{root.ID: true}
+	frontier := []*dbt.Entity{root}
+
+	for depth := 0; len(frontier) > 0; depth++ {
+		var next []*dbt.Entity
+
+		for _, entity := range frontier {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if filter.hasType(entity.Asset.AssetType()) && filter.inWindow(entity.LastSeen) {
+				if err := visit(entity); err != nil {
+					return err
+				}
+			}
+
+			if depth == filter.Depth {
+				continue
+			}
+
+			in, _ := api.store.IncomingEdges(ctx, entity, filter.Since, filter.Relations...)
+			out, _ := api.store.OutgoingEdges(ctx, entity, filter.Since, filter.Relations...)
+			for _, edge := range append(in, out...) {
+				neighbour := edge.ToEntity.ID
+				if neighbour == entity.ID {
+					neighbour = edge.FromEntity.ID
+				}
+				if seen[neighbour] {
+					continue
+				}
+				seen[neighbour] = true
+
+				found, err := api.store.FindEntityById(ctx, neighbour)
+				if err != nil {
+					continue
+				}
+				next = append(next, found)
+			}
+		}
+
+		frontier = next
+	}
+	return nil
+}
+
+// GraphWriter serializes a graph as it is walked. Entities are all
+// written before edges.
+type GraphWriter interface {
+	Begin() error
+	Entity(entity Entity, tags []EntityTag) error
+	Edge(edge Edge, tags []EdgeTag) error
+	End() error
+}
+
+// exportFormats maps the format query parameter of /export to the
+// content type and writer of each format.
+var exportFormats = map[string]struct {
+	ContentType string
+	Extension   string
+	New         func(w io.Writer) GraphWriter
+}{
+	"ndjson":  {"application/x-ndjson", "ndjson", func(w io.Writer) GraphWriter { return &recordWriter{w: w} }},
+	"json":    {"application/json", "json", func(w io.Writer) GraphWriter { return &recordWriter{w: w, array: true} }},
+	"graphml": {"application/graphml+xml", "graphml", func(w io.Writer) GraphWriter { return &graphmlWriter{w: w} }},
+	"cypher":  {"text/plain; charset=utf-8", "cypher", func(w io.Writer) GraphWriter { return &cypherWriter{w: w} }},
+}
+
+// exportGraph writes the subgraph selected by filter to gw as it is
+// walked: first the entities and their tags, then the edges between
+// exported entities and their tags. Only the IDs of the exported
+// entities are kept until the edges are written. A graph truncated at filter.Limit is written whole before
+// ErrGraphTruncated is returned.
+func (api *ApiV1) exportGraph(ctx context.Context, filter GraphFilter, gw GraphWriter) error {
+	if err := gw.Begin(); err != nil {
+		return err
+	}
+
+	var ids []string
+	exported := make(map[string]bool)
+
+	err := api.walkEntities(ctx, filter, func(entity *dbt.Entity) error {
+		ids = append(ids, entity.ID)
+		exported[entity.ID] = true
+
+		var tags []EntityTag
+		if !filter.SkipTags {
+			found, _ := api.store.GetEntityTags(ctx, entity, filter.Since)
+			for _, tag := range found {
+				if filter.inWindow(tag.LastSeen) {
+					tags = append(tags, EntityTagFromStore(tag))
+				}
+			}
+		}
+
+		return gw.Entity(EntityFromStore(entity), tags)
+	})
+	truncated := errors.Is(err, ErrGraphTruncated)
+	if err != nil && !truncated {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Querying edges only needs the entity ID.
+		edges, _ := api.store.OutgoingEdges(ctx, &dbt.Entity{ID: id}, filter.Since, filter.Relations...)
+		for _, edge := range edges {
+			if !exported[edge.ToEntity.ID] || !filter.inWindow(edge.LastSeen) {
+				continue
+			}
+
+			var tags []EdgeTag
+			if !filter.SkipTags {
+				found, _ := api.store.GetEdgeTags(ctx, edge, filter.Since)
+				for _, tag := range found {
+					if filter.inWindow(tag.LastSeen) {
+						tags = append(tags, EdgeTagFromStore(tag))
+					}
+				}
+			}
+
+			if err := gw.Edge(EdgeFromStore(edge), tags); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := gw.End(); err != nil {
+		return err
+	}
+
+	if truncated {
+		return ErrGraphTruncated
+	}
+	return nil
+}
+
+func (api *ApiV1) ExportGraph(w http.ResponseWriter, r *http.Request) {
+	filter, err := GraphFilterFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "ndjson"
+	}
+
+	format, ok := exportFormats[name]
+	if !ok {
+		http.Error(w, "unsupported format: "+name, http.StatusBadRequest)
+		return
+	}
+
+	if filter.Root != "" {
+		if _, err := api.store.FindEntityById(r.Context(), filter.Root); err != nil {
+			http.Error(w, "Cannot find root entity: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", format.ContentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=export."+format.Extension)
+	w.Header().Set("Trailer", TruncatedTrailer)
+
+	// Headers are sent by now: failures can only be logged.
+	err = api.exportGraph(r.Context(), filter, format.New(w))
+	if errors.Is(err, ErrGraphTruncated) {
+		w.Header().Set(TruncatedTrailer, "true")
+	} else if err != nil {
+		api.logger.Info("ExportGraph: " + err.Error())
+	}
+}
+
+// recordWriter writes one Record per line, or a JSON array of them.
+type recordWriter struct {
+	w     io.Writer
+	array bool
+	count int
+}
+
+func (rw *recordWriter) write(kind string, data Serializable) error {
+	line, _ := json.Marshal(Record{Kind: kind, Data: data.JSON()})
+
+	var err error
+	switch {
+	case !rw.array:
+		_, err = fmt.Fprintf(rw.w, "%s\n", line)
+	case rw.count == 0:
+		_, err = fmt.Fprintf(rw.w, "\n%s", line)
+	default:
+		_, err = fmt.Fprintf(rw.w, ",\n%s", line)
+	}
+	rw.count++
+	return err
+}
+
+func (rw *recordWriter) Begin() error {
+	if rw.array {
+		_, err := io.WriteString(rw.w, "[")
+		return err
+	}
+	return nil
+}
+
+func (rw *recordWriter) Entity(entity Entity, tags []EntityTag) error {
+	if err := rw.write(EntityRecord, entity); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := rw.write(EntityTagRecord, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rw *recordWriter) Edge(edge Edge, tags []EdgeTag) error {
+	if err := rw.write(EdgeRecord, edge); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := rw.write(EdgeTagRecord, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rw *recordWriter) End() error {
+	if rw.array {
+		_, err := io.WriteString(rw.w, "\n]\n")
+		return err
+	}
+	return nil
+}
+
+// graphmlWriter writes GraphML, as read by Gephi. Assets, relations
+// and tags are kept as JSON in data attributes.
+type graphmlWriter struct {
+	w io.Writer
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func (gw *graphmlWriter) Begin() error {
+	_, err := io.WriteString(gw.w, xml.Header+`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="type" for="all" attr.name="type" attr.type="string"/>
+  <key id="label" for="all" attr.name="label" attr.type="string"/>
+  <key id="content" for="all" attr.name="content" attr.type="string"/>
+  <key id="tags" for="all" attr.name="tags" attr.type="string"/>
+  <key id="created_at" for="all" attr.name="created_at" attr.type="string"/>
+  <key id="last_seen" for="all" attr.name="last_seen" attr.type="string"/>
+  <graph id="G" edgedefault="directed">
+`)
+	return err
+}
+
+func (gw *graphmlWriter) data(key, value string) string {
+	return fmt.Sprintf("      <data key=\"%s\">%s</data>\n", key, xmlEscape(value))
+}
+
+func (gw *graphmlWriter) Entity(entity Entity, tags []EntityTag) error {
+	content, _ := json.Marshal(entity.Asset)
+	tags_json, _ := json.Marshal(append([]EntityTag{}, tags...))
+
+	_, err := fmt.Fprintf(gw.w, "    <node id=\"%s\">\n%s%s%s%s%s%s    </node>\n",
+		xmlEscape(entity.ID),
+		gw.data("type", string(entity.Type)),
+		gw.data("label", entity.Asset.Key()),
+		gw.data("content", string(content)),
+		gw.data("tags", string(tags_json)),
+		gw.data("created_at", entity.CreatedAt.Format(time.RFC3339Nano)),
+		gw.data("last_seen", entity.LastSeen.Format(time.RFC3339Nano)),
+	)
+	return err
+}
+
+func (gw *graphmlWriter) Edge(edge Edge, tags []EdgeTag) error {
+	content, _ := json.Marshal(edge.Relation)
+	tags_json, _ := json.Marshal(append([]EdgeTag{}, tags...))
+
+	_, err := fmt.Fprintf(gw.w, "    <edge id=\"%s\" source=\"%s\" target=\"%s\">\n%s%s%s%s%s%s    </edge>\n",
+		xmlEscape(edge.ID), xmlEscape(edge.FromEntity), xmlEscape(edge.ToEntity),
+		gw.data("type", string(edge.Type)),
+		gw.data("label", edge.Relation.Label()),
+		gw.data("content", string(content)),
+		gw.data("tags", string(tags_json)),
+		gw.data("created_at", edge.CreatedAt.Format(time.RFC3339Nano)),
+		gw.data("last_seen", edge.LastSeen.Format(time.RFC3339Nano)),
+	)
+	return err
+}
+
+func (gw *graphmlWriter) End() error {
+	_, err := io.WriteString(gw.w, "  </graph>\n</graphml>\n")
+	return err
+}
+
+// cypherWriter writes Cypher MERGE statements rebuilding the graph with
+// the node and relationship layout of the asset store.
+type cypherWriter struct {
+	w io.Writer
+}
+
+var cypherIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func cypherName(name string) string {
+	if cypherIdentifier.MatchString(name) {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func cypherString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`).Replace(s) + "'"
+}
+
+func cypherValue(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case string:
+		return cypherString(value)
+	case []any:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = cypherValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		// Nested objects cannot be stored as properties.
+		encoded, _ := json.Marshal(value)
+		return cypherString(string(encoded))
+	}
+}
+
+// cypherProps flattens the JSON object of content into a Cypher map
+// along with the extra properties.
+func cypherProps(content any, extra map[string]any) string {
+	props := make(map[string]any)
+
+	encoded, _ := json.Marshal(content)
+	json.Unmarshal(encoded, &props)
+	for k, v := range extra {
+		props[k] = v
+	}
+
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = cypherName(k) + ": " + cypherValue(props[k])
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+func cypherTime(t time.Time) string {
+	return "localdatetime(" + cypherString(t.UTC().Format("2006-01-02T15:04:05.999999999")) + ")"
+}
+
+func (cw *cypherWriter) Begin() error {
+	return nil
+}
+
+func (cw *cypherWriter) Entity(entity Entity, tags []EntityTag) error {
+	_, err := fmt.Fprintf(cw.w, "MERGE (n:Entity:%s {entity_id: %s}) SET n += %s, n.created_at = %s, n.updated_at = %s;\n",
+		cypherName(string(entity.Type)), cypherString(entity.ID),
+		cypherProps(entity.Asset, nil),
+		cypherTime(entity.CreatedAt), cypherTime(entity.LastSeen),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		_, err := fmt.Fprintf(cw.w, "MERGE (t:EntityTag:%s {tag_id: %s}) SET t += %s, t.entity_id = %s, t.created_at = %s, t.updated_at = %s;\n",
+			cypherName(string(tag.Type)), cypherString(tag.ID),
+			cypherProps(tag.Property, nil), cypherString(tag.Entity),
+			cypherTime(tag.CreatedAt), cypherTime(tag.LastSeen),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cw *cypherWriter) Edge(edge Edge, tags []EdgeTag) error {
+	_, err := fmt.Fprintf(cw.w, "MATCH (a:Entity {entity_id: %s}), (b:Entity {entity_id: %s}) MERGE (a)-[r:%s {edge_id: %s}]->(b) SET r += %s, r.created_at = %s, r.updated_at = %s;\n",
+		cypherString(edge.FromEntity), cypherString(edge.ToEntity),
+		cypherName(strings.ToUpper(edge.Relation.Label())), cypherString(edge.ID),
+		cypherProps(edge.Relation, map[string]any{"relation_type": string(edge.Type)}),
+		cypherTime(edge.CreatedAt), cypherTime(edge.LastSeen),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		_, err := fmt.Fprintf(cw.w, "MERGE (t:EdgeTag:%s {tag_id: %s}) SET t += %s, t.edge_id = %s, t.created_at = %s, t.updated_at = %s;\n",
+			cypherName(string(tag.Type)), cypherString(tag.ID),
+			cypherProps(tag.Property, nil), cypherString(tag.Edge),
+			cypherTime(tag.CreatedAt), cypherTime(tag.LastSeen),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cw *cypherWriter) End() error {
+	return nil
+}
@@ -0,0 +1,227 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// exportGraphFixture emits two entities joined by an edge, each with a
+// tag, and returns them.
+func exportGraphFixture(tg *testGateway) (fqdn, ip Entity, edge Edge) {
+	fqdn = tg.entity(fqdnBody("www.example.com"))
+	ip = tg.entity(ipBody("192.0.2.1"))
+	edge = tg.edge(dnsEdgeBody(fqdn.ID, ip.ID, 60))
+
+	var tag EntityTag
+	tg.must("POST", "/emit/entity_tag", propertyBody("entity", fqdn.ID, "crawler"), &tag)
+	var edge_tag EdgeTag
+	tg.must("POST", "/emit/edge_tag", propertyBody("edge", edge.ID, "crawler"), &edge_tag)
+	return fqdn, ip, edge
+}
+
+// export reads an export to the end, for its trailer to be set.
+func (tg *testGateway) export(query string) (*http.Response, []byte) {
+	tg.t.Helper()
+
+	resp, err := http.Get(tg.url + "/export?" + query)
+	if err != nil {
+		tg.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		tg.t.Fatal(err)
+	}
+	expectStatus(tg.t, resp, http.StatusOK)
+	return resp, body
+}
+
+// recordKinds returns the kinds of records in order.
+func recordKinds(t *testing.T, records []Record) []string {
+	t.Helper()
+
+	kinds := make([]string, len(records))
+	for i, record := range records {
+		kinds[i] = record.Kind
+		if !json.Valid(record.Data) {
+			t.Errorf("record %d has invalid data %s", i, record.Data)
+		}
+	}
+	return kinds
+}
+
+func TestExportFormats(t *testing.T) {
+	tg := newTestGateway(t)
+	fqdn, ip, edge := exportGraphFixture(tg)
+
+	// The entities of a type are exported in the order of the types,
+	// FQDN before IPAddress, each followed by its tags.
+	expected := strings.Join([]string{EntityRecord, EntityTagRecord, EntityRecord, EdgeRecord, EdgeTagRecord}, " ")
+
+	t.Run("ndjson", func(t *testing.T) {
+		resp, body := tg.export("format=ndjson")
+		if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+			t.Errorf("content type %s", ct)
+		}
+
+		var records []Record
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			var record Record
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatalf("%v in %s", err, scanner.Bytes())
+			}
+			records = append(records, record)
+		}
+		if got := strings.Join(recordKinds(t, records), " "); got != expected {
+			t.Errorf("exported %s, want %s", got, expected)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		_, body := tg.export("format=json")
+
+		var records []Record
+		if err := json.Unmarshal(body, &records); err != nil {
+			t.Fatalf("%v in %s", err, body)
+		}
+		if got := strings.Join(recordKinds(t, records), " "); got != expected {
+			t.Errorf("exported %s, want %s", got, expected)
+		}
+	})
+
+	t.Run("graphml", func(t *testing.T) {
+		_, body := tg.export("format=graphml")
+
+		type data struct {
+			Key   string `xml:"key,attr"`
+			Value string `xml:",chardata"`
+		}
+		var doc struct {
+			Graph struct {
+				Nodes []struct {
+					ID   string `xml:"id,attr"`
+					Data []data `xml:"data"`
+				} `xml:"node"`
+				Edges []struct {
+					ID     string `xml:"id,attr"`
+					Source string `xml:"source,attr"`
+					Target string `xml:"target,attr"`
+					Data   []data `xml:"data"`
+				} `xml:"edge"`
+			} `xml:"graph"`
+		}
+		if err := xml.Unmarshal(body, &doc); err != nil {
+			t.Fatalf("%v in %s", err, body)
+		}
+
+		if len(doc.Graph.Nodes) != 2 || doc.Graph.Nodes[0].ID != fqdn.ID || doc.Graph.Nodes[1].ID != ip.ID {
+			t.Errorf("nodes %+v", doc.Graph.Nodes)
+		}
+		if len(doc.Graph.Edges) != 1 {
+			t.Fatalf("edges %+v", doc.Graph.Edges)
+		}
+		got := doc.Graph.Edges[0]
+		if got.ID != edge.ID || got.Source != fqdn.ID || got.Target != ip.ID {
+			t.Errorf("edge %+v", got)
+		}
+		for _, d := range got.Data {
+			if d.Key == "tags" && !strings.Contains(d.Value, "crawler") {
+				t.Errorf("edge tags %s", d.Value)
+			}
+		}
+	})
+
+	t.Run("cypher", func(t *testing.T) {
+		_, body := tg.export("format=cypher")
+
+		lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+		if len(lines) != 5 {
+			t.Fatalf("%d statements:\n%s", len(lines), body)
+		}
+		for _, line := range lines {
+			if !strings.HasSuffix(line, ";") {
+				t.Errorf("unterminated statement %s", line)
+			}
+		}
+
+		for _, want := range []string{
+			"MERGE (n:Entity:FQDN {entity_id: '" + fqdn.ID + "'})",
+			"MERGE (n:Entity:IPAddress {entity_id: '" + ip.ID + "'})",
+			"MERGE (a)-[r:DNS_RECORD {edge_id: '" + edge.ID + "'}]->(b) SET r += {",
+			"t.edge_id = '" + edge.ID + "'",
+			"t.entity_id = '" + fqdn.ID + "'",
+		} {
+			if !strings.Contains(string(body), want) {
+				t.Errorf("no %s in\n%s", want, body)
+			}
+		}
+	})
+
+	tg.status("GET", "/export?format=csv", "", http.StatusBadRequest)
+}
+
+func TestExportFilters(t *testing.T) {
+	tg := newTestGateway(t)
+	fqdn, _, _ := exportGraphFixture(tg)
+	other := tg.entity(fqdnBody("www.example.org"))
+
+	count := func(body []byte, kind string) int {
+		n := 0
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			var record Record
+			json.Unmarshal(scanner.Bytes(), &record)
+			if record.Kind == kind {
+				n++
+			}
+		}
+		return n
+	}
+
+	cases := []struct {
+		name      string
+		query     string
+		entities  int
+		edges     int
+		tags      int
+		truncated bool
+	}{
+		{"whole graph", "", 3, 1, 2, false},
+		{"asset type", "asset_types=FQDN", 2, 0, 1, false},
+		{"root", "root=" + fqdn.ID, 2, 1, 2, false},
+		{"root without depth", "root=" + fqdn.ID + "&depth=0", 1, 0, 1, false},
+		{"root of a lone entity", "root=" + other.ID, 1, 0, 0, false},
+		{"limit", "limit=2", 2, 0, 1, true},
+		{"limit above the size", "limit=3", 3, 1, 2, false},
+		{"limit of a neighbourhood", "root=" + fqdn.ID + "&limit=1", 1, 0, 1, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp, body := tg.export(c.query)
+
+			if got := count(body, EntityRecord); got != c.entities {
+				t.Errorf("%d entities, want %d", got, c.entities)
+			}
+			if got := count(body, EdgeRecord); got != c.edges {
+				t.Errorf("%d edges, want %d", got, c.edges)
+			}
+			if got := count(body, EntityTagRecord) + count(body, EdgeTagRecord); got != c.tags {
+				t.Errorf("%d tags, want %d", got, c.tags)
+			}
+			if got := resp.Trailer.Get(TruncatedTrailer) == "true"; got != c.truncated {
+				t.Errorf("truncated %v, want %v", got, c.truncated)
+			}
+		})
+	}
+
+	tg.status("GET", "/export?root=unknown", "", http.StatusBadRequest)
+	tg.status("GET", "/export?limit=-1", "", http.StatusBadRequest)
+}
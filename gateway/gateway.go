@@ -0,0 +1,310 @@
+// Package gateway serves the Open Asset Gateway API over an asset
+// repository: REST routes emitting and reading objects, server-sent
+// events, GraphQL and gRPC. New returns it as an http.Handler, so that
+// it can be embedded in another service.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/0ppliger/oam-broker/eventbus"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Gateway is the HTTP handler of the gateway.
+type Gateway struct {
+	api     *ApiV1
+	handler http.Handler
+	// watch is the interval the store is polled at for events, when
+	// they are tailed from the store rather than published by the
+	// handlers.
+	watch time.Duration
+	// max_body_size bounds the request bodies, unless route_body_sizes
+	// has a limit for the route.
+	max_body_size    int64
+	route_body_sizes map[string]int64
+	// limits are enforced per client, as told by identify, when set, by
+	// limiter on both the HTTP and the gRPC API.
+	limits   *Limits
+	identify func(*http.Request) string
+	limiter  *limiter
+	// keys are the API keys verified before requests reach the gateway,
+	// those of its workspace.
+	keys keySet
+}
+
+type Option func(*Gateway)
+
+// WithRepository sets the asset repository the gateway serves. It is
+// required.
+func WithRepository(store Repository) Option {
+	return func(g *Gateway) {
+		g.api.store = store
+	}
+}
+
+// WithEventBus sets the bus the events of the gateway are published on,
+// to share it with the embedding service. A new one is created otherwise.
+func WithEventBus(bus *EventBus) Option {
+	return func(g *Gateway) {
+		g.api.bus = bus
+	}
+}
+
+// WithLogger sets the logger of the gateway. Nothing is logged otherwise.
+func WithLogger(logger *logrus.Logger) Option {
+	return func(g *Gateway) {
+		g.api.logger = logger
+	}
+}
+
+// WithContext bounds the lifetime of the jobs and of the store watcher
+// of the gateway.
+func WithContext(ctx context.Context) Option {
+	return func(g *Gateway) {
+		g.api.ctx = ctx
+	}
+}
+
+// WithPrefix mounts the gateway under a path, such as "/oag", so that
+// its routes are /oag/emit/entity and so on.
+func WithPrefix(prefix string) Option {
+	return func(g *Gateway) {
+		g.api.prefix = strings.TrimSuffix(prefix, "/")
+	}
+}
+
+// WithStoreWatcher tails the events from the repository every interval,
+// instead of having the handlers publish them, so that writes made
+// directly into the repository are streamed too.
+func WithStoreWatcher(interval time.Duration) Option {
+	return func(g *Gateway) {
+		g.watch = interval
+	}
+}
+
+// WithMaxBodySize bounds the request bodies of the routes without a
+// limit of their own, DefaultMaxBodySize by default. Larger bodies are
+// answered with 413. Zero or less disables the limit.
+func WithMaxBodySize(max int64) Option {
+	return func(g *Gateway) {
+		g.max_body_size = max
+	}
+}
+
+// WithRouteMaxBodySize bounds the request bodies of the route with the
+// given pattern, such as "POST /import".
+func WithRouteMaxBodySize(pattern string, max int64) Option {
+	return func(g *Gateway) {
+		g.route_body_sizes[pattern] = max
+	}
+}
+
+// WithLenientDecoding accepts fields that the assets, relations and
+// properties sent do not have, and ignores them. They are rejected
+// otherwise.
+func WithLenientDecoding() Option {
+	return func(g *Gateway) {
+		g.api.lenient = true
+	}
+}
+
+// WithAdminKeys enables the admin operations, POST /admin/prune and
+// prune jobs, for the requests carrying one of the keys in the
+// X-Admin-Key header. They are refused otherwise.
+func WithAdminKeys(keys ...string) Option {
+	return func(g *Gateway) {
+		g.api.admin_keys = append(g.api.admin_keys, newKeySet(keys)...)
+	}
+}
+
+// WithSQLiteImport accepts the asset databases written by Amass on
+// POST /import?format=sqlite, opening them with open, such as with the
+// sqlrepo package of asset-db.
+func WithSQLiteImport(open func(path string) (Repository, error)) Option {
+	return func(g *Gateway) {
+		g.api.openAssetDB = open
+	}
+}
+
+// WithLimits enforces rate limits, a cap on concurrent subscriptions
+// and a daily write quota on every client. Rejected requests are
+// answered with 429 and a Retry-After header.
+func WithLimits(limits Limits) Option {
+	return func(g *Gateway) {
+		g.limits = &limits
+	}
+}
+
+// WithClientIdentity sets how the clients the limits apply to are told
+// apart. By default, they are told apart by the keys the gateway
+// verifies, their admin key or the API key of their workspace, and by
+// ClientIdentity otherwise, for clients not to escape their limits by
+// making up keys.
+func WithClientIdentity(identify func(*http.Request) string) Option {
+	return func(g *Gateway) {
+		g.identify = identify
+	}
+}
+
+func New(opts ...Option) (*Gateway, error) {
+	g := &Gateway{
+		api:           &ApiV1{ctx: context.Background()},
+		max_body_size: DefaultMaxBodySize,
+		route_body_sizes: map[string]int64{
+			"POST /import": DefaultImportMaxBodySize,
+		},
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.identify == nil {
+		g.identify = g.clientIdentity
+	}
+
+	api := g.api
+	if api.store == nil {
+		return nil, errors.New("gateway: no repository")
+	}
+	if api.bus == nil {
+		api.bus = eventbus.New()
+	}
+	if api.logger == nil {
+		api.logger = logrus.New()
+		api.logger.SetOutput(io.Discard)
+	}
+
+	go api.jobs.Run(api.ctx)
+
+	if g.watch > 0 {
+		api.tailing = true
+		watcher := NewStoreWatcher(api.store, api.bus, api.logger, g.watch)
+		go watcher.Run(api.ctx)
+	}
+
+	mux := http.NewServeMux()
+	handle := func(pattern string, handler http.Handler) {
+		mux.Handle(pattern, limitBody(handler, g.bodySize(pattern)))
+	}
+	handleFunc := func(pattern string, handler http.HandlerFunc) {
+		handle(pattern, handler)
+	}
+
+	handleFunc("GET /listen", api.ListenEvents)
+
+	graphql, err := NewGraphQL(api)
+	if err != nil {
+		return nil, errors.New("gateway: unable to generate GraphQL schema: " + err.Error())
+	}
+	handle("/graphql", graphql)
+
+	handleFunc("GET /openapi.json", api.GetOpenAPI)
+	handleFunc("GET /types", api.GetTypes)
+
+	handleFunc("GET /entity/{id}", api.GetEntity)
+	handleFunc("GET /edge/{id}", api.GetEdge)
+	handleFunc("GET /entity_tag/{id}", api.GetEntityTag)
+	handleFunc("GET /edge_tag/{id}", api.GetEdgeTag)
+
+	handleFunc("GET /entity/{id}/graph", api.GetEntityGraph)
+	handleFunc("GET /entity/{id}/path/{to}", api.GetEntityPath)
+
+	handleFunc("GET /export", api.ExportGraph)
+	handleFunc("POST /import", api.ImportGraph)
+
+	handleFunc("POST /jobs", api.CreateJob)
+	handleFunc("GET /jobs", api.ListJobs)
+	handleFunc("GET /jobs/{id}", api.GetJob)
+	handleFunc("GET /jobs/{id}/result", api.GetJobResult)
+	handleFunc("DELETE /jobs/{id}", api.CancelJob)
+	handleFunc("POST /admin/prune", api.PruneAssets)
+
+	handleFunc("POST /emit/entity", api.CreateEntity)
+	handleFunc("DELETE /emit/entity/{id}", api.DeleteEntity)
+	handleFunc("PUT /emit/entity/{id}", api.UpdateEntity)
+	handleFunc("PATCH /emit/entity/{id}", api.PatchEntity)
+
+	handleFunc("POST /emit/edge", api.CreateEdge)
+	handleFunc("DELETE /emit/edge/{id}", api.DeleteEdge)
+	handleFunc("PUT /emit/edge/{id}", api.UpdateEdge)
+	handleFunc("PATCH /emit/edge/{id}", api.PatchEdge)
+
+	handleFunc("POST /emit/entity_tag", api.CreateEntityTag)
+	handleFunc("DELETE /emit/entity_tag/{id}", api.DeleteEntityTag)
+	handleFunc("PUT /emit/entity_tag/{id}", api.UpdateEntityTag)
+	handleFunc("PATCH /emit/entity_tag/{id}", api.PatchEntityTag)
+
+	handleFunc("POST /emit/edge_tag", api.CreateEdgeTag)
+	handleFunc("DELETE /emit/edge_tag/{id}", api.DeleteEdgeTag)
+	handleFunc("PUT /emit/edge_tag/{id}", api.UpdateEdgeTag)
+	handleFunc("PATCH /emit/edge_tag/{id}", api.PatchEdgeTag)
+
+	g.handler = mux
+	if g.limits != nil {
+		g.limiter = newLimiter(*g.limits, g.identify, g.bodySize("/graphql"))
+		g.handler = g.limiter.limit(g.handler)
+	}
+	if api.prefix != "" {
+		g.handler = http.StripPrefix(api.prefix, g.handler)
+	}
+	return g, nil
+}
+
+// bodySize returns the size the request bodies of the route with the
+// given pattern are bounded to.
+func (g *Gateway) bodySize(pattern string) int64 {
+	if max, ok := g.route_body_sizes[pattern]; ok {
+		return max
+	}
+	return g.max_body_size
+}
+
+// clientIdentity is the default identity of the clients of the gateway.
+func (g *Gateway) clientIdentity(r *http.Request) string {
+	if key := apiKey(r); g.keys.has(key) {
+		return "key:" + key
+	}
+	if key := r.Header.Get(AdminKeyHeader); g.api.admin_keys.has(key) {
+		return "admin:" + key
+	}
+	return ClientIdentity(r)
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.handler.ServeHTTP(w, r)
+}
+
+// Bus returns the bus the events of the gateway are published on.
+func (g *Gateway) Bus() *EventBus {
+	return g.api.bus
+}
+
+// ServeGRPC serves the gRPC API of the gateway on addr over TLS until
+// it fails.
+func (g *Gateway) ServeGRPC(addr, cert_file, key_file string) error {
+	return serveGRPC(g.grpcServer, addr, cert_file, key_file)
+}
+
+// serveGRPC serves the gRPC server made by server on addr over TLS until
+// it fails.
+func serveGRPC(server func(...grpc.ServerOption) *grpc.Server, addr, cert_file, key_file string) error {
+	creds, err := credentials.NewServerTLSFromFile(cert_file, key_file)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return server(grpc.Creds(creds)).Serve(listener)
+}
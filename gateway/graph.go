@@ -0,0 +1,63 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultGraphLimit is the number of entities a neighbourhood query
+	// returns when no limit is given.
+	defaultGraphLimit = 1000
+	// maxGraphLimit is the highest limit a neighbourhood query accepts.
+	maxGraphLimit = 10000
+)
+
+// GetEntityGraph returns the entities within depth hops of an entity,
+// along with the edges between them and, with tags=true, their tags.
+func (api *ApiV1) GetEntityGraph(w http.ResponseWriter, r *http.Request) {
+	filter, err := GraphFilterFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.Root = r.PathValue("id")
+
+	if limit := r.URL.Query().Get("limit"); limit == "" {
+		filter.Limit = defaultGraphLimit
+	} else if filter.Limit == 0 || filter.Limit > maxGraphLimit {
+		http.Error(w, "invalid limit: "+limit+", must be between 1 and "+strconv.Itoa(maxGraphLimit), http.StatusBadRequest)
+		return
+	}
+
+	filter.SkipTags = true
+	if tags := r.URL.Query().Get("tags"); tags != "" {
+		with_tags, err := strconv.ParseBool(tags)
+		if err != nil {
+			http.Error(w, "invalid tags: "+tags, http.StatusBadRequest)
+			return
+		}
+		filter.SkipTags = !with_tags
+	}
+
+	if _, err := api.store.FindEntityById(r.Context(), filter.Root); err != nil {
+		http.Error(w, "Cannot find entity: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	graph := &Subgraph{
+		Root:     filter.Root,
+		Entities: []Entity{},
+		Edges:    []Edge{},
+	}
+
+	err = api.exportGraph(r.Context(), filter, graph)
+	graph.Truncated = errors.Is(err, ErrGraphTruncated)
+	if err != nil && !graph.Truncated {
+		http.Error(w, "Failed to walk graph: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(graph.JSON())
+}
@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func simpleEdgeBody(label, from, to string) string {
+	return fmt.Sprintf(`{"type":"SimpleRelation","relation":{"label":%q},"from_entity":%q,"to_entity":%q}`, label, from, to)
+}
+
+// graphFixture emits the graph
+//
+//	a -dns_record-> b -dns_record-> c
+//	a <---node----- b
+//	a -node-> d
+//
+// with a tag on a, and returns the names of the entities by ID.
+func graphFixture(tg *testGateway) map[string]string {
+	a := tg.entity(fqdnBody("a.example.com"))
+	b := tg.entity(fqdnBody("b.example.com"))
+	c := tg.entity(ipBody("192.0.2.1"))
+	d := tg.entity(fqdnBody("d.example.com"))
+
+	tg.edge(dnsEdgeBody(a.ID, b.ID, 60))
+	tg.edge(dnsEdgeBody(b.ID, c.ID, 60))
+	tg.edge(simpleEdgeBody("node", b.ID, a.ID))
+	tg.edge(simpleEdgeBody("node", a.ID, d.ID))
+
+	var tag EntityTag
+	tg.must("POST", "/emit/entity_tag", propertyBody("entity", a.ID, "crawler"), &tag)
+
+	return map[string]string{a.ID: "a", b.ID: "b", c.ID: "c", d.ID: "d"}
+}
+
+func TestEntityGraph(t *testing.T) {
+	tg := newTestGateway(t)
+	names := graphFixture(tg)
+	var root string
+	for id, name := range names {
+		if name == "a" {
+			root = id
+		}
+	}
+
+	cases := []struct {
+		name      string
+		query     string
+		entities  string
+		edges     int
+		tags      int
+		truncated bool
+	}{
+		{"default depth", "", "a b d", 3, 0, false},
+		{"depth 0", "depth=0", "a", 0, 0, false},
+		{"depth 2", "depth=2", "a b c d", 4, 0, false},
+		{"depth beyond the graph", "depth=5", "a b c d", 4, 0, false},
+		{"relations", "depth=2&relations=dns_record", "a b c", 2, 0, false},
+		{"several relations", "depth=2&relations=dns_record&relations=node", "a b c d", 4, 0, false},
+		{"asset types", "depth=2&asset_types=FQDN", "a b d", 3, 0, false},
+		{"asset types other than the root", "depth=2&asset_types=IPAddress", "c", 0, 0, false},
+		{"tags", "depth=0&tags=true", "a", 0, 1, false},
+		{"limit", "depth=2&limit=2", "", 0, 0, true},
+		{"limit of the size", "depth=2&limit=4", "a b c d", 4, 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var graph Subgraph
+			tg.must("GET", "/entity/"+root+"/graph?"+c.query, "", &graph)
+
+			var got []string
+			for _, entity := range graph.Entities {
+				got = append(got, names[entity.ID])
+			}
+			// The root comes first, unless it is not of the asset types.
+			if len(got) == 0 || (got[0] != "a" && c.entities != "c") {
+				t.Fatalf("root is not first: %v", got)
+			}
+			slices.Sort(got)
+
+			if c.truncated {
+				// Which neighbours make it depends on the order of the
+				// edges in the store.
+				if len(got) != 2 || got[0] != "a" {
+					t.Errorf("truncated to %v", got)
+				}
+			} else if strings.Join(got, " ") != c.entities {
+				t.Errorf("entities %v, want %s", got, c.entities)
+			}
+
+			if !c.truncated && len(graph.Edges) != c.edges {
+				t.Errorf("%d edges, want %d", len(graph.Edges), c.edges)
+			}
+			for _, edge := range graph.Edges {
+				if names[edge.FromEntity] == "" || names[edge.ToEntity] == "" {
+					t.Errorf("edge %s leaves the graph", edge.ID)
+				}
+			}
+			if len(graph.EntityTags) != c.tags {
+				t.Errorf("%d tags, want %d", len(graph.EntityTags), c.tags)
+			}
+			if graph.Truncated != c.truncated {
+				t.Errorf("truncated %v, want %v", graph.Truncated, c.truncated)
+			}
+		})
+	}
+
+	for _, query := range []string{"limit=0", "limit=10001", "depth=-1", "tags=maybe", "asset_types=Planet"} {
+		tg.status("GET", "/entity/"+root+"/graph?"+query, "", http.StatusBadRequest)
+	}
+	tg.status("GET", "/entity/unknown/graph", "", http.StatusNotFound)
+}
@@ -0,0 +1,1047 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	dbt "github.com/owasp-amass/asset-db/types"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+// GraphQL serves a GraphQL API over the asset store. Its schema is
+// generated from the assetTypes, relationTypes and propertyTypes
+// registries, and its mutations call the operations of ApiV1 like the
+// REST handlers.
+type GraphQL struct {
+	api    *ApiV1
+	schema graphql.Schema
+}
+
+// graphqlInvalid matches the characters GraphQL does not accept in
+// names.
+var graphqlInvalid = regexp.MustCompile(`[^_0-9A-Za-z]`)
+
+func graphqlName(name string) string {
+	return graphqlInvalid.ReplaceAllString(name, "_")
+}
+
+var graphqlJSON = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "Any JSON value.",
+	Serialize: func(value any) any {
+		json_encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil
+		}
+
+		var out any
+		json.Unmarshal(json_encoded, &out)
+		return out
+	},
+	ParseValue: func(value any) any {
+		return value
+	},
+	ParseLiteral: parseJSONLiteral,
+})
+
+func parseJSONLiteral(value ast.Value) any {
+	switch v := value.(type) {
+	case *ast.StringValue:
+		return v.Value
+	case *ast.BooleanValue:
+		return v.Value
+	case *ast.EnumValue:
+		return v.Value
+	case *ast.IntValue:
+		n, _ := strconv.ParseInt(v.Value, 10, 64)
+		return n
+	case *ast.FloatValue:
+		f, _ := strconv.ParseFloat(v.Value, 64)
+		return f
+	case *ast.ListValue:
+		out := make([]any, 0, len(v.Values))
+		for _, item := range v.Values {
+			out = append(out, parseJSONLiteral(item))
+		}
+		return out
+	case *ast.ObjectValue:
+		out := make(map[string]any, len(v.Fields))
+		for _, field := range v.Fields {
+			out[field.Name.Value] = parseJSONLiteral(field.Value)
+		}
+		return out
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a registry in a stable order, so that
+// the generated schema does not change between runs.
+func sortedKeys[K ~string](registry map[K]reflect.Type) []K {
+	keys := make([]K, 0, len(registry))
+	for key := range registry {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// graphqlEnum generates an enum of the keys of a registry.
+func graphqlEnum[K ~string](name string, registry map[K]reflect.Type) *graphql.Enum {
+	values := graphql.EnumValueConfigMap{}
+	for _, key := range sortedKeys(registry) {
+		values[graphqlName(string(key))] = &graphql.EnumValueConfig{Value: string(key)}
+	}
+
+	return graphql.NewEnum(graphql.EnumConfig{
+		Name:   name,
+		Values: values,
+	})
+}
+
+// graphqlUnion generates an object type for every type of a registry,
+// with a field per JSON field of its struct, and their union.
+func graphqlUnion[K ~string](name string, registry map[K]reflect.Type) *graphql.Union {
+	objects := make(map[reflect.Type]*graphql.Object)
+	var members []*graphql.Object
+
+	for _, key := range sortedKeys(registry) {
+		t := registry[key]
+		object := graphql.NewObject(graphql.ObjectConfig{
+			Name:   graphqlName(string(key)),
+			Fields: graphqlFields(t),
+		})
+		objects[t] = object
+		members = append(members, object)
+	}
+
+	return graphql.NewUnion(graphql.UnionConfig{
+		Name:  name,
+		Types: members,
+		ResolveType: func(p graphql.ResolveTypeParams) *graphql.Object {
+			t := reflect.TypeOf(p.Value)
+			if t != nil && t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			return objects[t]
+		},
+	})
+}
+
+func graphqlFields(t reflect.Type) graphql.Fields {
+	fields := graphql.Fields{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		index := field.Index
+		fields[graphqlName(name)] = &graphql.Field{
+			Type: graphqlOutput(field.Type),
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return reflect.Indirect(reflect.ValueOf(p.Source)).FieldByIndex(index).Interface(), nil
+			},
+		}
+	}
+	return fields
+}
+
+// graphqlOutput maps a Go type to a GraphQL type. Structs other than
+// time.Time are left as JSON.
+func graphqlOutput(t reflect.Type) graphql.Output {
+	if t == reflect.TypeOf(time.Time{}) {
+		return graphql.DateTime
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return graphqlOutput(t.Elem())
+	case reflect.String:
+		return graphql.String
+	case reflect.Bool:
+		return graphql.Boolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return graphql.Int
+	case reflect.Float32, reflect.Float64:
+		return graphql.Float
+	case reflect.Slice, reflect.Array:
+		// Byte slices are encoded as base64 strings.
+		if t.Elem().Kind() == reflect.Uint8 {
+			return graphql.String
+		}
+		return graphql.NewList(graphqlOutput(t.Elem()))
+	}
+	return graphqlJSON
+}
+
+// stringList reads a list argument.
+func stringList(value any) []string {
+	items, _ := value.([]any)
+
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// graphqlMutations resolves the mutations of a kind of object, from
+// the fields of the object sent as arguments.
+type graphqlMutations struct {
+	create func(fields map[string]any) (any, error)
+	update func(id string, fields map[string]any, if_match string) (any, error)
+	patch  func(id string, patch Patch, if_match string) (any, error)
+	remove func(id string, if_match string, cascade bool) (any, error)
+}
+
+// resolved returns the result of an operation to a resolver, which must
+// not get a value along with an error.
+func resolved[T any](out T, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// withoutCascade adapts the delete operation of objects that hold
+// nothing to be deleted along with them.
+func withoutCascade[T any](remove func(string, string) (T, error)) func(string, string, bool) (T, error) {
+	return func(id string, if_match string, cascade bool) (T, error) {
+		return remove(id, if_match)
+	}
+}
+
+// mutationsOf maps the mutations of a kind of object to its operations.
+func mutationsOf[T any, PT interface {
+	*T
+	decodable
+}](api *ApiV1, create func(T) (T, error), update func(string, T, string) (T, error), patch func(string, Patch, string) (T, error), remove func(string, string, bool) (T, error)) graphqlMutations {
+	decode := func(fields map[string]any) (T, error) {
+		var input T
+		err := api.decodeFields(fields, PT(&input))
+		return input, err
+	}
+
+	return graphqlMutations{
+		create: func(fields map[string]any) (any, error) {
+			input, err := decode(fields)
+			if err != nil {
+				return nil, err
+			}
+			return resolved(create(input))
+		},
+		update: func(id string, fields map[string]any, if_match string) (any, error) {
+			input, err := decode(fields)
+			if err != nil {
+				return nil, err
+			}
+			return resolved(update(id, input, if_match))
+		},
+		patch: func(id string, p Patch, if_match string) (any, error) {
+			return resolved(patch(id, p, if_match))
+		},
+		remove: func(id string, if_match string, cascade bool) (any, error) {
+			return resolved(remove(id, if_match, cascade))
+		},
+	}
+}
+
+// NewGraphQL generates the GraphQL schema of the gateway.
+func NewGraphQL(api *ApiV1) (*GraphQL, error) {
+	g := &GraphQL{api: api}
+
+	assetType := graphqlEnum("AssetType", assetTypes)
+	relationType := graphqlEnum("RelationType", relationTypes)
+	propertyType := graphqlEnum("PropertyType", propertyTypes)
+
+	asset := graphqlUnion("Asset", assetTypes)
+	relation := graphqlUnion("Relation", relationTypes)
+	property := graphqlUnion("Property", propertyTypes)
+
+	names := &graphql.ArgumentConfig{
+		Type:        graphql.NewList(graphql.NewNonNull(graphql.String)),
+		Description: "Restricts the tags to these property names.",
+	}
+	relations := &graphql.ArgumentConfig{
+		Type:        graphql.NewList(graphql.NewNonNull(graphql.String)),
+		Description: "Restricts the edges to these labels.",
+	}
+	asset_types := &graphql.ArgumentConfig{
+		Type:        graphql.NewList(graphql.NewNonNull(assetType)),
+		Description: "Restricts the entities to these types.",
+	}
+
+	entity := graphql.NewObject(graphql.ObjectConfig{Name: "Entity", Fields: graphql.Fields{}})
+	edge := graphql.NewObject(graphql.ObjectConfig{Name: "Edge", Fields: graphql.Fields{}})
+	entityTag := graphql.NewObject(graphql.ObjectConfig{Name: "EntityTag", Fields: graphql.Fields{}})
+	edgeTag := graphql.NewObject(graphql.ObjectConfig{Name: "EdgeTag", Fields: graphql.Fields{}})
+
+	// Fields shared by every object of the store.
+	for _, object := range []*graphql.Object{entity, edge, entityTag, edgeTag} {
+		object.AddFieldConfig("id", &graphql.Field{
+			Type: graphql.NewNonNull(graphql.ID),
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return reflect.ValueOf(p.Source).FieldByName("ID").Interface(), nil
+			},
+		})
+		object.AddFieldConfig("created_at", &graphql.Field{
+			Type: graphql.DateTime,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return reflect.ValueOf(p.Source).FieldByName("CreatedAt").Interface(), nil
+			},
+		})
+		object.AddFieldConfig("last_seen", &graphql.Field{
+			Type: graphql.DateTime,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return reflect.ValueOf(p.Source).FieldByName("LastSeen").Interface(), nil
+			},
+		})
+		object.AddFieldConfig("etag", &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.String),
+			Description: "The ETag to send as if_match to update or delete the object.",
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return ETag(p.Source.(Serializable)), nil
+			},
+		})
+	}
+
+	entity.AddFieldConfig("type", &graphql.Field{
+		Type: graphql.NewNonNull(assetType),
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return string(p.Source.(Entity).Type), nil
+		},
+	})
+	entity.AddFieldConfig("key", &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(Entity).Asset.Key(), nil
+		},
+	})
+	entity.AddFieldConfig("asset", &graphql.Field{
+		Type: asset,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(Entity).Asset, nil
+		},
+	})
+	entity.AddFieldConfig("content", &graphql.Field{
+		Type:        graphqlJSON,
+		Description: "The asset as JSON.",
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(Entity).Asset, nil
+		},
+	})
+	entity.AddFieldConfig("tags", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(entityTag))),
+		Args: graphql.FieldConfigArgument{"names": names},
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			// The store reports empty results as errors.
+			found, _ := api.store.GetEntityTags(p.Context, p.Source.(Entity).ToStore(), time.Time{}, stringList(p.Args["names"])...)
+
+			tags := make([]EntityTag, 0, len(found))
+			for _, tag := range found {
+				tags = append(tags, EntityTagFromStore(tag))
+			}
+			return tags, nil
+		},
+	})
+	for name, incoming := range map[string]bool{"incoming": true, "outgoing": false} {
+		entity.AddFieldConfig(name, &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edge))),
+			Args: graphql.FieldConfigArgument{"relations": relations},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				var found []*dbt.Edge
+				if incoming {
+					found, _ = api.store.IncomingEdges(p.Context, p.Source.(Entity).ToStore(), time.Time{}, stringList(p.Args["relations"])...)
+				} else {
+					found, _ = api.store.OutgoingEdges(p.Context, p.Source.(Entity).ToStore(), time.Time{}, stringList(p.Args["relations"])...)
+				}
+
+				edges := make([]Edge, 0, len(found))
+				for _, e := range found {
+					edges = append(edges, EdgeFromStore(e))
+				}
+				return edges, nil
+			},
+		})
+	}
+	entity.AddFieldConfig("neighbours", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(entity))),
+		Description: "The entities linked to this one by an edge in either direction.",
+		Args:        graphql.FieldConfigArgument{"relations": relations, "asset_types": asset_types},
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			source := p.Source.(Entity)
+			filter := GraphFilter{Relations: stringList(p.Args["relations"])}
+			for _, atype := range stringList(p.Args["asset_types"]) {
+				filter.AssetTypes = append(filter.AssetTypes, oam.AssetType(atype))
+			}
+
+			in, _ := api.store.IncomingEdges(p.Context, source.ToStore(), time.Time{}, filter.Relations...)
+			out, _ := api.store.OutgoingEdges(p.Context, source.ToStore(), time.Time{}, filter.Relations...)
+
+			seen := map[string]bool{source.ID: true}
+			neighbours := []Entity{}
+			for _, e := range append(in, out...) {
+				id := e.ToEntity.ID
+				if id == source.ID {
+					id = e.FromEntity.ID
+				}
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+
+				found, err := api.store.FindEntityById(p.Context, id)
+				if err != nil || !filter.hasType(found.Asset.AssetType()) {
+					continue
+				}
+				neighbours = append(neighbours, EntityFromStore(found))
+			}
+			return neighbours, nil
+		},
+	})
+
+	edge.AddFieldConfig("type", &graphql.Field{
+		Type: graphql.NewNonNull(relationType),
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return string(p.Source.(Edge).Type), nil
+		},
+	})
+	edge.AddFieldConfig("label", &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(Edge).Relation.Label(), nil
+		},
+	})
+	edge.AddFieldConfig("relation", &graphql.Field{
+		Type: relation,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(Edge).Relation, nil
+		},
+	})
+	edge.AddFieldConfig("content", &graphql.Field{
+		Type:        graphqlJSON,
+		Description: "The relation as JSON.",
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(Edge).Relation, nil
+		},
+	})
+	edge.AddFieldConfig("from", &graphql.Field{
+		Type: entity,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return g.entity(p, p.Source.(Edge).FromEntity)
+		},
+	})
+	edge.AddFieldConfig("to", &graphql.Field{
+		Type: entity,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return g.entity(p, p.Source.(Edge).ToEntity)
+		},
+	})
+	edge.AddFieldConfig("tags", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeTag))),
+		Args: graphql.FieldConfigArgument{"names": names},
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			// Querying tags only needs the edge ID.
+			source := &dbt.Edge{ID: p.Source.(Edge).ID}
+			found, _ := api.store.GetEdgeTags(p.Context, source, time.Time{}, stringList(p.Args["names"])...)
+
+			tags := make([]EdgeTag, 0, len(found))
+			for _, tag := range found {
+				tags = append(tags, EdgeTagFromStore(tag))
+			}
+			return tags, nil
+		},
+	})
+
+	entityTag.AddFieldConfig("type", &graphql.Field{
+		Type: graphql.NewNonNull(propertyType),
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return string(p.Source.(EntityTag).Type), nil
+		},
+	})
+	entityTag.AddFieldConfig("entity", &graphql.Field{
+		Type: entity,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return g.entity(p, p.Source.(EntityTag).Entity)
+		},
+	})
+	edgeTag.AddFieldConfig("type", &graphql.Field{
+		Type: graphql.NewNonNull(propertyType),
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return string(p.Source.(EdgeTag).Type), nil
+		},
+	})
+	edgeTag.AddFieldConfig("edge", &graphql.Field{
+		Type: edge,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			found, err := api.store.FindEdgeById(p.Context, p.Source.(EdgeTag).Edge)
+			if err != nil {
+				return nil, fmt.Errorf("cannot find edge: %w", err)
+			}
+			return EdgeFromStore(found), nil
+		},
+	})
+
+	// Both kinds of tags expose their property alike.
+	for _, object := range []*graphql.Object{entityTag, edgeTag} {
+		propertyOf := func(source any) oam.Property {
+			return reflect.ValueOf(source).FieldByName("Property").Interface().(oam.Property)
+		}
+
+		object.AddFieldConfig("name", &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return propertyOf(p.Source).Name(), nil
+			},
+		})
+		object.AddFieldConfig("value", &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return propertyOf(p.Source).Value(), nil
+			},
+		})
+		object.AddFieldConfig("property", &graphql.Field{
+			Type: property,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return propertyOf(p.Source), nil
+			},
+		})
+		object.AddFieldConfig("content", &graphql.Field{
+			Type:        graphqlJSON,
+			Description: "The property as JSON.",
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return propertyOf(p.Source), nil
+			},
+		})
+	}
+
+	subgraph := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subgraph",
+		Fields: graphql.Fields{
+			"root":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"entities":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(entity)))},
+			"edges":       &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edge)))},
+			"entity_tags": &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(entityTag))},
+			"edge_tags":   &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(edgeTag))},
+			"truncated":   &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+
+	path := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Path",
+		Description: "A sequence of entities where edges[i] links entities[i] and entities[i+1].",
+		Fields: graphql.Fields{
+			"entities": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(entity)))},
+			"edges":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edge)))},
+		},
+	})
+
+	id := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"entity": &graphql.Field{
+				Type: entity,
+				Args: graphql.FieldConfigArgument{"id": id},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return g.entity(p, p.Args["id"].(string))
+				},
+			},
+			"edge": &graphql.Field{
+				Type: edge,
+				Args: graphql.FieldConfigArgument{"id": id},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					found, err := api.store.FindEdgeById(p.Context, p.Args["id"].(string))
+					if err != nil {
+						return nil, fmt.Errorf("cannot find edge: %w", err)
+					}
+					return EdgeFromStore(found), nil
+				},
+			},
+			"entity_tag": &graphql.Field{
+				Type: entityTag,
+				Args: graphql.FieldConfigArgument{"id": id},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					found, err := api.store.FindEntityTagById(p.Context, p.Args["id"].(string))
+					if err != nil {
+						return nil, fmt.Errorf("cannot find entity tag: %w", err)
+					}
+					return EntityTagFromStore(found), nil
+				},
+			},
+			"edge_tag": &graphql.Field{
+				Type: edgeTag,
+				Args: graphql.FieldConfigArgument{"id": id},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					found, err := api.store.FindEdgeTagById(p.Context, p.Args["id"].(string))
+					if err != nil {
+						return nil, fmt.Errorf("cannot find edge tag: %w", err)
+					}
+					return EdgeTagFromStore(found), nil
+				},
+			},
+			"entities": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(entity))),
+				Args: graphql.FieldConfigArgument{
+					"type":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(assetType)},
+					"since": &graphql.ArgumentConfig{Type: graphql.DateTime},
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultGraphLimit},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					limit := p.Args["limit"].(int)
+					if limit < 1 || limit > maxGraphLimit {
+						return nil, fmt.Errorf("invalid limit: %d, must be between 1 and %d", limit, maxGraphLimit)
+					}
+
+					since, _ := p.Args["since"].(time.Time)
+					found, _ := api.store.FindEntitiesByType(p.Context, oam.AssetType(p.Args["type"].(string)), since)
+
+					entities := make([]Entity, 0, min(len(found), limit))
+					for _, e := range found[:min(len(found), limit)] {
+						entities = append(entities, EntityFromStore(e))
+					}
+					return entities, nil
+				},
+			},
+			"find_entities": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(entity))),
+				Description: "Finds the entities holding an asset.",
+				Args: graphql.FieldConfigArgument{
+					"type":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(assetType)},
+					"asset": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphqlJSON)},
+					"since": &graphql.ArgumentConfig{Type: graphql.DateTime},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					json_encoded, _ := json.Marshal(map[string]any{"type": p.Args["type"], "asset": p.Args["asset"]})
+
+					var input Entity
+					if err := json.Unmarshal(json_encoded, &input); err != nil {
+						return nil, err
+					}
+
+					since, _ := p.Args["since"].(time.Time)
+					found, _ := api.store.FindEntitiesByContent(p.Context, input.Asset, since)
+
+					entities := make([]Entity, 0, len(found))
+					for _, e := range found {
+						entities = append(entities, EntityFromStore(e))
+					}
+					return entities, nil
+				},
+			},
+			"graph": &graphql.Field{
+				Type:        subgraph,
+				Description: "The neighbourhood of an entity, as returned by GET /entity/{id}/graph.",
+				Args: graphql.FieldConfigArgument{
+					"root":        id,
+					"depth":       &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+					"relations":   relations,
+					"asset_types": asset_types,
+					"limit":       &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultGraphLimit},
+					"tags":        &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					filter := GraphFilter{
+						Root:      p.Args["root"].(string),
+						Depth:     p.Args["depth"].(int),
+						Relations: stringList(p.Args["relations"]),
+						Limit:     p.Args["limit"].(int),
+						SkipTags:  !p.Args["tags"].(bool),
+					}
+					for _, atype := range stringList(p.Args["asset_types"]) {
+						filter.AssetTypes = append(filter.AssetTypes, oam.AssetType(atype))
+					}
+
+					if filter.Depth < 0 {
+						return nil, fmt.Errorf("invalid depth: %d", filter.Depth)
+					}
+					if filter.Limit < 1 || filter.Limit > maxGraphLimit {
+						return nil, fmt.Errorf("invalid limit: %d, must be between 1 and %d", filter.Limit, maxGraphLimit)
+					}
+
+					graph := &Subgraph{
+						Root:     filter.Root,
+						Entities: []Entity{},
+						Edges:    []Edge{},
+					}
+
+					err := api.exportGraph(p.Context, filter, graph)
+					graph.Truncated = errors.Is(err, ErrGraphTruncated)
+					if err != nil && !graph.Truncated {
+						return nil, err
+					}
+					return graph, nil
+				},
+			},
+			"paths": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(path))),
+				Description: "The shortest paths between two entities, as returned by GET /entity/{id}/path/{to}.",
+				Args: graphql.FieldConfigArgument{
+					"from":      id,
+					"to":        id,
+					"relations": relations,
+					"max_depth": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPathDepth},
+					"limit":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPathLimit},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					max_depth, limit := p.Args["max_depth"].(int), p.Args["limit"].(int)
+					if max_depth < 1 || max_depth > maxPathDepth {
+						return nil, fmt.Errorf("invalid max_depth: %d, must be between 1 and %d", max_depth, maxPathDepth)
+					}
+					if limit < 1 || limit > maxPathLimit {
+						return nil, fmt.Errorf("invalid limit: %d, must be between 1 and %d", limit, maxPathLimit)
+					}
+
+					paths, err := api.shortestPaths(p.Context, p.Args["from"].(string), p.Args["to"].(string), stringList(p.Args["relations"]), max_depth, limit)
+					if err != nil {
+						return nil, err
+					}
+					if paths == nil {
+						paths = []Path{}
+					}
+					return paths, nil
+				},
+			},
+		},
+	})
+
+	if_match := &graphql.ArgumentConfig{
+		Type:        graphql.String,
+		Description: "The etag the object must still have to be written.",
+	}
+	patch := &graphql.ArgumentConfig{
+		Type:        graphql.NewNonNull(graphqlJSON),
+		Description: "A JSON Merge Patch object, or a JSON Patch array.",
+	}
+
+	// A cascading delete deletes the edges and tags of the entity too,
+	// but only the entity is returned.
+	deleteEntity := func(id string, if_match string, cascade bool) (Entity, error) {
+		deleted, err := api.deleteEntity(id, if_match, cascade)
+		return deleted.Entity, err
+	}
+
+	// Mutations are generated for each kind of object from the fields
+	// of its REST payload.
+	kinds := []struct {
+		name      string
+		object    *graphql.Object
+		content   graphql.FieldConfigArgument
+		mutations graphqlMutations
+	}{
+		{
+			"entity", entity,
+			graphql.FieldConfigArgument{
+				"type":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(assetType)},
+				"asset": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphqlJSON)},
+			},
+			mutationsOf(api, api.createEntity, api.updateEntity, api.patchEntity, deleteEntity),
+		},
+		{
+			"edge", edge,
+			graphql.FieldConfigArgument{
+				"type":        &graphql.ArgumentConfig{Type: graphql.NewNonNull(relationType)},
+				"relation":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphqlJSON)},
+				"from_entity": id,
+				"to_entity":   id,
+			},
+			mutationsOf(api, api.createEdge, api.updateEdge, api.patchEdge, withoutCascade(api.deleteEdge)),
+		},
+		{
+			"entity_tag", entityTag,
+			graphql.FieldConfigArgument{
+				"type":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(propertyType)},
+				"property": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphqlJSON)},
+				"entity":   id,
+			},
+			mutationsOf(api, api.createEntityTag, api.updateEntityTag, api.patchEntityTag, withoutCascade(api.deleteEntityTag)),
+		},
+		{
+			"edge_tag", edgeTag,
+			graphql.FieldConfigArgument{
+				"type":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(propertyType)},
+				"property": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphqlJSON)},
+				"edge":     id,
+			},
+			mutationsOf(api, api.createEdgeTag, api.updateEdgeTag, api.patchEdgeTag, withoutCascade(api.deleteEdgeTag)),
+		},
+	}
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{Name: "Mutation", Fields: graphql.Fields{}})
+	for _, kind := range kinds {
+		content := func(p graphql.ResolveParams) map[string]any {
+			body := make(map[string]any, len(kind.content))
+			for name := range kind.content {
+				body[name] = p.Args[name]
+			}
+			return body
+		}
+
+		update_args := graphql.FieldConfigArgument{"id": id, "if_match": if_match}
+		for name, arg := range kind.content {
+			update_args[name] = arg
+		}
+
+		mutation.AddFieldConfig("create_"+kind.name, &graphql.Field{
+			Type: kind.object,
+			Args: kind.content,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return kind.mutations.create(content(p))
+			},
+		})
+		mutation.AddFieldConfig("update_"+kind.name, &graphql.Field{
+			Type: kind.object,
+			Args: update_args,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				id, _ := p.Args["id"].(string)
+				if_match, _ := p.Args["if_match"].(string)
+				return kind.mutations.update(id, content(p), if_match)
+			},
+		})
+		mutation.AddFieldConfig("patch_"+kind.name, &graphql.Field{
+			Type: kind.object,
+			Args: graphql.FieldConfigArgument{"id": id, "patch": patch, "if_match": if_match},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				id, _ := p.Args["id"].(string)
+				if_match, _ := p.Args["if_match"].(string)
+				body, err := valuePatch(p.Args["patch"])
+				if err != nil {
+					return nil, err
+				}
+				return kind.mutations.patch(id, body, if_match)
+			},
+		})
+
+		delete_args := graphql.FieldConfigArgument{"id": id, "if_match": if_match}
+		if kind.name == "entity" {
+			delete_args["cascade"] = &graphql.ArgumentConfig{
+				Type:        graphql.Boolean,
+				Description: "Deletes the edges and tags of the entity along with it.",
+			}
+		}
+		mutation.AddFieldConfig("delete_"+kind.name, &graphql.Field{
+			Type: kind.object,
+			Args: delete_args,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				id, _ := p.Args["id"].(string)
+				if_match, _ := p.Args["if_match"].(string)
+				cascade, _ := p.Args["cascade"].(bool)
+				return kind.mutations.remove(id, if_match, cascade)
+			},
+		})
+	}
+
+	event := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Event",
+		Fields: graphql.Fields{
+			"type": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return string(p.Source.(ServerSentEvent).Event), nil
+				},
+			},
+			"data": &graphql.Field{
+				Type: graphqlJSON,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return json.RawMessage(p.Source.(ServerSentEvent).Data.JSON()), nil
+				},
+			},
+		},
+	})
+
+	subscription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"events": &graphql.Field{
+				Type:        graphql.NewNonNull(event),
+				Description: "The events published on /listen.",
+				Args: graphql.FieldConfigArgument{
+					"types": &graphql.ArgumentConfig{
+						Type:        graphql.NewList(graphql.NewNonNull(graphql.String)),
+						Description: "Restricts the events to these types.",
+					},
+				},
+				Subscribe: func(p graphql.ResolveParams) (any, error) {
+					return g.subscribe(p, stringList(p.Args["types"])), nil
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:        query,
+		Mutation:     mutation,
+		Subscription: subscription,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g.schema = schema
+	return g, nil
+}
+
+func (g *GraphQL) entity(p graphql.ResolveParams, id string) (any, error) {
+	found, err := g.api.store.FindEntityById(p.Context, id)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find entity: %w", err)
+	}
+	return EntityFromStore(found), nil
+}
+
+// subscribe forwards the events of the bus until the request ends.
+func (g *GraphQL) subscribe(p graphql.ResolveParams, types []string) chan any {
+	var wanted []EventType
+	for _, t := range types {
+		wanted = append(wanted, EventType(t))
+	}
+
+	events := g.api.bus.Subscribe(p.Context, wanted...)
+	out := make(chan any)
+
+	go func() {
+		defer close(out)
+		for sse := range events {
+			select {
+			case out <- sse:
+			case <-p.Context.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// operation returns the type of the operation a request executes, or
+// an empty string when the query does not parse.
+func (req GraphQLRequest) operation() string {
+	document, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return ""
+	}
+
+	for _, definition := range document.Definitions {
+		op, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if req.OperationName == "" || (op.Name != nil && op.Name.Value == req.OperationName) {
+			return op.Operation
+		}
+	}
+	return ""
+}
+
+// ServeHTTP executes queries sent with GET or POST and mutations sent
+// with POST. Subscriptions are streamed as server-sent events, following
+// the distinct connections mode of the GraphQL over SSE protocol: a
+// "next" event per result, then a "complete" event.
+func (g *GraphQL) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req GraphQLRequest
+
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				http.Error(w, "invalid variables: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		if r.Body == nil {
+			http.Error(w, "no body", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if err := readJSON(r, &req); err != nil {
+			http.Error(w, "invalid JSON: "+err.Error(), errorStatus(err))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := graphql.Params{
+		Schema:         g.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        r.Context(),
+	}
+
+	switch req.operation() {
+	case ast.OperationTypeMutation:
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "Mutations must be sent with POST", http.StatusMethodNotAllowed)
+			return
+		}
+	case ast.OperationTypeSubscription:
+		g.stream(w, params)
+		return
+	}
+
+	json_encoded, _ := json.Marshal(graphql.Do(params))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(json_encoded)
+}
+
+func (g *GraphQL) stream(w http.ResponseWriter, params graphql.Params) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher.Flush()
+
+	// The results channel is closed once the request context is done.
+	for result := range graphql.Subscribe(params) {
+		json_encoded, _ := json.Marshal(result)
+		fmt.Fprintf(w, "event: next\ndata: %s\n\n", json_encoded)
+		flusher.Flush()
+	}
+
+	fmt.Fprint(w, "event: complete\ndata:\n\n")
+	flusher.Flush()
+}
@@ -0,0 +1,191 @@
+package gateway
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// graphqlResponse is the body of a GraphQL response.
+type graphqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+// graphql posts a GraphQL request and decodes the response.
+func (tg *testGateway) graphql(query string, variables map[string]any) graphqlResponse {
+	tg.t.Helper()
+
+	body, _ := json.Marshal(GraphQLRequest{Query: query, Variables: variables})
+
+	var resp graphqlResponse
+	tg.must("POST", "/graphql", string(body), &resp)
+	return resp
+}
+
+func TestGraphQLQuery(t *testing.T) {
+	tg := newTestGateway(t)
+	fqdn := tg.entity(fqdnBody("www.example.com"))
+
+	resp := tg.graphql(`query ($id: ID!) { entity(id: $id) { id type etag } }`, map[string]any{"id": fqdn.ID})
+	if len(resp.Errors) != 0 {
+		t.Fatalf("errors: %+v", resp.Errors)
+	}
+
+	var got struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		ETag string `json:"etag"`
+	}
+	json.Unmarshal(resp.Data["entity"], &got)
+	if got.ID != fqdn.ID || got.Type != "FQDN" || got.ETag != ETag(fqdn) {
+		t.Errorf("entity %+v", got)
+	}
+
+	resp = tg.graphql(`{ entity(id: "unknown") { id } }`, nil)
+	if len(resp.Errors) != 1 || string(resp.Data["entity"]) != "null" {
+		t.Errorf("unknown entity: %+v", resp)
+	}
+
+	// Queries are read-only, so they may be sent with GET.
+	var get graphqlResponse
+	tg.must("GET", "/graphql?query="+url.QueryEscape(`{ entity(id: "`+fqdn.ID+`") { id } }`), "", &get)
+	if len(get.Errors) != 0 || !strings.Contains(string(get.Data["entity"]), fqdn.ID) {
+		t.Errorf("query over GET: %+v", get)
+	}
+}
+
+func TestGraphQLMutation(t *testing.T) {
+	tg := newTestGateway(t)
+	events := tg.listen()
+
+	create := `mutation ($asset: JSON!) { create_entity(type: FQDN, asset: $asset) { id etag } }`
+	resp := tg.graphql(create, map[string]any{"asset": map[string]any{"name": "www.example.com"}})
+	if len(resp.Errors) != 0 {
+		t.Fatalf("errors: %+v", resp.Errors)
+	}
+
+	var created struct {
+		ID   string `json:"id"`
+		ETag string `json:"etag"`
+	}
+	json.Unmarshal(resp.Data["create_entity"], &created)
+
+	// Mutations publish events like the REST API.
+	if event := <-events; event.event != EntityCreated || string(event.data["id"]) != fmt.Sprintf("%q", created.ID) {
+		t.Errorf("event %s about %s", event.event, event.data["id"])
+	}
+
+	var stored Entity
+	tg.must("GET", "/entity/"+created.ID, "", &stored)
+	if ETag(stored) != created.ETag {
+		t.Errorf("etag %s, stored %s", created.ETag, ETag(stored))
+	}
+
+	update := `mutation ($id: ID!, $if_match: String, $asset: JSON!) {
+		update_entity(id: $id, if_match: $if_match, type: FQDN, asset: $asset) { id etag }
+	}`
+	variables := map[string]any{
+		"id":       created.ID,
+		"if_match": `"stale"`,
+		"asset":    map[string]any{"name": "app.example.com"},
+	}
+
+	// The errors of the operations carry their HTTP status.
+	resp = tg.graphql(update, variables)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["status"] != float64(http.StatusPreconditionFailed) {
+		t.Fatalf("stale if_match: %+v", resp.Errors)
+	}
+
+	variables["if_match"] = created.ETag
+	resp = tg.graphql(update, variables)
+	if len(resp.Errors) != 0 {
+		t.Fatalf("errors: %+v", resp.Errors)
+	}
+	if event := <-events; event.event != EntityUpdated {
+		t.Errorf("event %s", event.event)
+	}
+
+	// Fields the REST API rejects are rejected alike.
+	resp = tg.graphql(create, map[string]any{"asset": map[string]any{"name": "www.example.com", "extra": true}})
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["status"] != float64(http.StatusBadRequest) {
+		t.Errorf("unknown field: %+v", resp.Errors)
+	}
+
+	// Mutations are not sent with GET.
+	tg.status("GET", "/graphql?query="+url.QueryEscape(`mutation { delete_entity(id: "`+created.ID+`") { id } }`), "", http.StatusMethodNotAllowed)
+}
+
+func TestGraphQLSubscription(t *testing.T) {
+	tg := newTestGateway(t)
+
+	body, _ := json.Marshal(GraphQLRequest{
+		Query: `subscription { events(types: ["EntityCreated"]) { type data } }`,
+	})
+	resp, err := http.Post(tg.url+"/graphql", "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	if content_type := resp.Header.Get("Content-Type"); content_type != "text/event-stream" {
+		t.Fatalf("content type %s", content_type)
+	}
+
+	results := make(chan string)
+	go func() {
+		defer close(results)
+
+		var event string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			field, value, _ := strings.Cut(scanner.Text(), ": ")
+			switch field {
+			case "event":
+				event = value
+			case "data":
+				if event == "next" {
+					results <- value
+				}
+			}
+		}
+	}()
+
+	// The subscription starts once the headers are sent, so entities are
+	// emitted until one of them is reported.
+	var result graphqlResponse
+	for i := 0; ; i++ {
+		tg.entity(fqdnBody(fmt.Sprintf("%d.example.com", i)))
+
+		select {
+		case data, ok := <-results:
+			if !ok {
+				t.Fatal("stream ended")
+			}
+			json.Unmarshal([]byte(data), &result)
+		case <-time.After(50 * time.Millisecond):
+			if i < 100 {
+				continue
+			}
+			t.Fatal("no event")
+		}
+		break
+	}
+
+	var event struct {
+		Type string         `json:"type"`
+		Data map[string]any `json:"data"`
+	}
+	json.Unmarshal(result.Data["events"], &event)
+	if event.Type != string(EntityCreated) || event.Data["type"] != "FQDN" {
+		t.Errorf("event %+v", event)
+	}
+}
@@ -0,0 +1,687 @@
+package gateway
+
+//go:generate sh ../gatewaypb/generate.sh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/0ppliger/oam-broker/gatewaypb"
+	oam "github.com/owasp-amass/open-asset-model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GrpcServer serves the gRPC API of the gateway. Like the GraphQL API,
+// its writes call the operations of ApiV1.
+type GrpcServer struct {
+	gatewaypb.UnimplementedGatewayServer
+	api *ApiV1
+}
+
+// grpcServer returns the gRPC server of the gateway, enforcing its
+// limits like the HTTP API does.
+func (g *Gateway) grpcServer(opts ...grpc.ServerOption) *grpc.Server {
+	if g.limiter != nil {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(g.limiter.unaryInterceptor),
+			grpc.ChainStreamInterceptor(g.limiter.streamInterceptor),
+		)
+	}
+
+	server := grpc.NewServer(opts...)
+	gatewaypb.RegisterGatewayServer(server, &GrpcServer{api: g.api})
+	return server
+}
+
+// grpcError maps the HTTP status of a failed operation to a gRPC code.
+func grpcError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	var service_error *ServiceError
+	if !errors.As(err, &service_error) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	switch service_error.Status {
+	case http.StatusBadRequest:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case http.StatusNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case http.StatusConflict:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case http.StatusPreconditionFailed:
+		return status.Error(codes.Aborted, err.Error())
+	case http.StatusTooManyRequests:
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func toStruct(v any) (*structpb.Struct, error) {
+	json_encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(json_encoded, &fields); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(fields)
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func entityToPB(e Entity) (*gatewaypb.Entity, error) {
+	asset, err := toStruct(e.Asset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gatewaypb.Entity{
+		Id:        e.ID,
+		CreatedAt: toTimestamp(e.CreatedAt),
+		LastSeen:  toTimestamp(e.LastSeen),
+		Type:      string(e.Type),
+		Asset:     asset,
+	}, nil
+}
+
+func edgeToPB(e Edge) (*gatewaypb.Edge, error) {
+	relation, err := toStruct(e.Relation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gatewaypb.Edge{
+		Id:         e.ID,
+		CreatedAt:  toTimestamp(e.CreatedAt),
+		LastSeen:   toTimestamp(e.LastSeen),
+		Type:       string(e.Type),
+		Relation:   relation,
+		FromEntity: e.FromEntity,
+		ToEntity:   e.ToEntity,
+	}, nil
+}
+
+func entityTagToPB(e EntityTag) (*gatewaypb.EntityTag, error) {
+	property, err := toStruct(e.Property)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gatewaypb.EntityTag{
+		Id:        e.ID,
+		CreatedAt: toTimestamp(e.CreatedAt),
+		LastSeen:  toTimestamp(e.LastSeen),
+		Type:      string(e.Type),
+		Property:  property,
+		Entity:    e.Entity,
+	}, nil
+}
+
+func edgeTagToPB(e EdgeTag) (*gatewaypb.EdgeTag, error) {
+	property, err := toStruct(e.Property)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gatewaypb.EdgeTag{
+		Id:        e.ID,
+		CreatedAt: toTimestamp(e.CreatedAt),
+		LastSeen:  toTimestamp(e.LastSeen),
+		Type:      string(e.Type),
+		Property:  property,
+		Edge:      e.Edge,
+	}, nil
+}
+
+// The fields of the objects of the API, built from the messages.
+
+func entityBody(e *gatewaypb.Entity) map[string]any {
+	return map[string]any{
+		"id":    e.GetId(),
+		"type":  e.GetType(),
+		"asset": e.GetAsset().AsMap(),
+	}
+}
+
+func edgeBody(e *gatewaypb.Edge) map[string]any {
+	return map[string]any{
+		"id":          e.GetId(),
+		"type":        e.GetType(),
+		"relation":    e.GetRelation().AsMap(),
+		"from_entity": e.GetFromEntity(),
+		"to_entity":   e.GetToEntity(),
+	}
+}
+
+func entityTagBody(e *gatewaypb.EntityTag) map[string]any {
+	return map[string]any{
+		"id":       e.GetId(),
+		"type":     e.GetType(),
+		"property": e.GetProperty().AsMap(),
+		"entity":   e.GetEntity(),
+	}
+}
+
+func edgeTagBody(e *gatewaypb.EdgeTag) map[string]any {
+	return map[string]any{
+		"id":       e.GetId(),
+		"type":     e.GetType(),
+		"property": e.GetProperty().AsMap(),
+		"edge":     e.GetEdge(),
+	}
+}
+
+// grpcResult converts the result of an operation, and sends its ETag
+// as "etag" header.
+func grpcResult[T Serializable, P any](ctx context.Context, out T, err error, toPB func(T) (P, error)) (P, error) {
+	if err != nil {
+		var zero P
+		return zero, grpcError(err)
+	}
+
+	grpc.SetHeader(ctx, metadata.Pairs("etag", ETag(out)))
+	return toPB(out)
+}
+
+// grpcPatch reads the patch of a Patch request, a JSON Patch when it
+// is a list of operations.
+func grpcPatch(patch *structpb.Value) (Patch, error) {
+	body, err := valuePatch(patch.AsInterface())
+	if err != nil {
+		return Patch{}, status.Error(codes.InvalidArgument, "invalid patch: "+err.Error())
+	}
+	return body, nil
+}
+
+func (s *GrpcServer) CreateEntity(ctx context.Context, in *gatewaypb.Entity) (*gatewaypb.Entity, error) {
+	var input Entity
+	if err := s.api.decodeFields(entityBody(in), &input); err != nil {
+		return nil, grpcError(err)
+	}
+
+	out, err := s.api.createEntity(input)
+	return grpcResult(ctx, out, err, entityToPB)
+}
+
+func (s *GrpcServer) GetEntity(ctx context.Context, in *gatewaypb.GetRequest) (*gatewaypb.Entity, error) {
+	found, err := s.api.store.FindEntityById(ctx, in.GetId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "cannot find entity: "+err.Error())
+	}
+
+	return grpcResult(ctx, EntityFromStore(found), nil, entityToPB)
+}
+
+func (s *GrpcServer) UpdateEntity(ctx context.Context, in *gatewaypb.UpdateEntityRequest) (*gatewaypb.Entity, error) {
+	var input Entity
+	if err := s.api.decodeFields(entityBody(in.GetEntity()), &input); err != nil {
+		return nil, grpcError(err)
+	}
+
+	out, err := s.api.updateEntity(in.GetEntity().GetId(), input, in.GetIfMatch())
+	return grpcResult(ctx, out, err, entityToPB)
+}
+
+func (s *GrpcServer) PatchEntity(ctx context.Context, in *gatewaypb.PatchRequest) (*gatewaypb.Entity, error) {
+	patch, err := grpcPatch(in.GetPatch())
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.api.patchEntity(in.GetId(), patch, in.GetIfMatch())
+	return grpcResult(ctx, out, err, entityToPB)
+}
+
+// DeleteEntity deletes an entity, along with its edges and tags when
+// cascading. Only the entity is returned.
+func (s *GrpcServer) DeleteEntity(ctx context.Context, in *gatewaypb.DeleteRequest) (*gatewaypb.Entity, error) {
+	cascade, err := s.api.deleteEntity(in.GetId(), in.GetIfMatch(), in.GetCascade())
+	return grpcResult(ctx, cascade.Entity, err, entityToPB)
+}
+
+func (s *GrpcServer) CreateEdge(ctx context.Context, in *gatewaypb.Edge) (*gatewaypb.Edge, error) {
+	var input Edge
+	if err := s.api.decodeFields(edgeBody(in), &input); err != nil {
+		return nil, grpcError(err)
+	}
+
+	out, err := s.api.createEdge(input)
+	return grpcResult(ctx, out, err, edgeToPB)
+}
+
+func (s *GrpcServer) GetEdge(ctx context.Context, in *gatewaypb.GetRequest) (*gatewaypb.Edge, error) {
+	found, err := s.api.store.FindEdgeById(ctx, in.GetId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "cannot find edge: "+err.Error())
+	}
+
+	return grpcResult(ctx, EdgeFromStore(found), nil, edgeToPB)
+}
+
+func (s *GrpcServer) UpdateEdge(ctx context.Context, in *gatewaypb.UpdateEdgeRequest) (*gatewaypb.Edge, error) {
+	var input Edge
+	if err := s.api.decodeFields(edgeBody(in.GetEdge()), &input); err != nil {
+		return nil, grpcError(err)
+	}
+
+	out, err := s.api.updateEdge(in.GetEdge().GetId(), input, in.GetIfMatch())
+	return grpcResult(ctx, out, err, edgeToPB)
+}
+
+func (s *GrpcServer) PatchEdge(ctx context.Context, in *gatewaypb.PatchRequest) (*gatewaypb.Edge, error) {
+	patch, err := grpcPatch(in.GetPatch())
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.api.patchEdge(in.GetId(), patch, in.GetIfMatch())
+	return grpcResult(ctx, out, err, edgeToPB)
+}
+
+func (s *GrpcServer) DeleteEdge(ctx context.Context, in *gatewaypb.DeleteRequest) (*gatewaypb.Edge, error) {
+	out, err := s.api.deleteEdge(in.GetId(), in.GetIfMatch())
+	return grpcResult(ctx, out, err, edgeToPB)
+}
+
+func (s *GrpcServer) CreateEntityTag(ctx context.Context, in *gatewaypb.EntityTag) (*gatewaypb.EntityTag, error) {
+	var input EntityTag
+	if err := s.api.decodeFields(entityTagBody(in), &input); err != nil {
+		return nil, grpcError(err)
+	}
+
+	out, err := s.api.createEntityTag(input)
+	return grpcResult(ctx, out, err, entityTagToPB)
+}
+
+func (s *GrpcServer) GetEntityTag(ctx context.Context, in *gatewaypb.GetRequest) (*gatewaypb.EntityTag, error) {
+	found, err := s.api.store.FindEntityTagById(ctx, in.GetId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "cannot find entity tag: "+err.Error())
+	}
+
+	return grpcResult(ctx, EntityTagFromStore(found), nil, entityTagToPB)
+}
+
+func (s *GrpcServer) UpdateEntityTag(ctx context.Context, in *gatewaypb.UpdateEntityTagRequest) (*gatewaypb.EntityTag, error) {
+	var input EntityTag
+	if err := s.api.decodeFields(entityTagBody(in.GetEntityTag()), &input); err != nil {
+		return nil, grpcError(err)
+	}
+
+	out, err := s.api.updateEntityTag(in.GetEntityTag().GetId(), input, in.GetIfMatch())
+	return grpcResult(ctx, out, err, entityTagToPB)
+}
+
+func (s *GrpcServer) PatchEntityTag(ctx context.Context, in *gatewaypb.PatchRequest) (*gatewaypb.EntityTag, error) {
+	patch, err := grpcPatch(in.GetPatch())
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.api.patchEntityTag(in.GetId(), patch, in.GetIfMatch())
+	return grpcResult(ctx, out, err, entityTagToPB)
+}
+
+func (s *GrpcServer) DeleteEntityTag(ctx context.Context, in *gatewaypb.DeleteRequest) (*gatewaypb.EntityTag, error) {
+	out, err := s.api.deleteEntityTag(in.GetId(), in.GetIfMatch())
+	return grpcResult(ctx, out, err, entityTagToPB)
+}
+
+func (s *GrpcServer) CreateEdgeTag(ctx context.Context, in *gatewaypb.EdgeTag) (*gatewaypb.EdgeTag, error) {
+	var input EdgeTag
+	if err := s.api.decodeFields(edgeTagBody(in), &input); err != nil {
+		return nil, grpcError(err)
+	}
+
+	out, err := s.api.createEdgeTag(input)
+	return grpcResult(ctx, out, err, edgeTagToPB)
+}
+
+func (s *GrpcServer) GetEdgeTag(ctx context.Context, in *gatewaypb.GetRequest) (*gatewaypb.EdgeTag, error) {
+	found, err := s.api.store.FindEdgeTagById(ctx, in.GetId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "cannot find edge tag: "+err.Error())
+	}
+
+	return grpcResult(ctx, EdgeTagFromStore(found), nil, edgeTagToPB)
+}
+
+func (s *GrpcServer) UpdateEdgeTag(ctx context.Context, in *gatewaypb.UpdateEdgeTagRequest) (*gatewaypb.EdgeTag, error) {
+	var input EdgeTag
+	if err := s.api.decodeFields(edgeTagBody(in.GetEdgeTag()), &input); err != nil {
+		return nil, grpcError(err)
+	}
+
+	out, err := s.api.updateEdgeTag(in.GetEdgeTag().GetId(), input, in.GetIfMatch())
+	return grpcResult(ctx, out, err, edgeTagToPB)
+}
+
+func (s *GrpcServer) PatchEdgeTag(ctx context.Context, in *gatewaypb.PatchRequest) (*gatewaypb.EdgeTag, error) {
+	patch, err := grpcPatch(in.GetPatch())
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.api.patchEdgeTag(in.GetId(), patch, in.GetIfMatch())
+	return grpcResult(ctx, out, err, edgeTagToPB)
+}
+
+func (s *GrpcServer) DeleteEdgeTag(ctx context.Context, in *gatewaypb.DeleteRequest) (*gatewaypb.EdgeTag, error) {
+	out, err := s.api.deleteEdgeTag(in.GetId(), in.GetIfMatch())
+	return grpcResult(ctx, out, err, edgeTagToPB)
+}
+
+func (s *GrpcServer) FindEntities(in *gatewaypb.FindEntitiesRequest, stream grpc.ServerStreamingServer[gatewaypb.Entity]) error {
+	atype := oam.AssetType(in.GetType())
+	if _, ok := assetTypes[atype]; !ok {
+		return status.Errorf(codes.InvalidArgument, "unsupported asset type: %s", atype)
+	}
+
+	limit := int(in.GetLimit())
+	if limit == 0 {
+		limit = defaultGraphLimit
+	}
+	if limit < 0 || limit > maxGraphLimit {
+		return status.Errorf(codes.InvalidArgument, "invalid limit: %d, must be between 1 and %d", limit, maxGraphLimit)
+	}
+
+	var since time.Time
+	if in.GetSince() != nil {
+		since = in.GetSince().AsTime()
+	}
+
+	// The store reports empty results as errors.
+	found, _ := s.api.store.FindEntitiesByType(stream.Context(), atype, since)
+	for _, e := range found[:min(len(found), limit)] {
+		out, err := entityToPB(EntityFromStore(e))
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if err := stream.Send(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetGraph returns the neighbourhood of an entity, like GET
+// /entity/{id}/graph. Zero depth and limit stand for their defaults.
+func (s *GrpcServer) GetGraph(ctx context.Context, in *gatewaypb.GraphRequest) (*gatewaypb.Subgraph, error) {
+	filter := GraphFilter{
+		Root:      in.GetRoot(),
+		Depth:     int(in.GetDepth()),
+		Relations: in.GetRelations(),
+		Limit:     int(in.GetLimit()),
+		SkipTags:  !in.GetTags(),
+	}
+	for _, atype := range in.GetAssetTypes() {
+		if _, ok := assetTypes[oam.AssetType(atype)]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unsupported asset type: %s", atype)
+		}
+		filter.AssetTypes = append(filter.AssetTypes, oam.AssetType(atype))
+	}
+
+	if filter.Depth == 0 {
+		filter.Depth = 1
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultGraphLimit
+	}
+	if filter.Depth < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid depth: %d", filter.Depth)
+	}
+	if filter.Limit < 0 || filter.Limit > maxGraphLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limit: %d, must be between 1 and %d", filter.Limit, maxGraphLimit)
+	}
+
+	if _, err := s.api.store.FindEntityById(ctx, filter.Root); err != nil {
+		return nil, status.Error(codes.NotFound, "cannot find entity: "+err.Error())
+	}
+
+	graph := &Subgraph{Root: filter.Root}
+	err := s.api.exportGraph(ctx, filter, graph)
+	graph.Truncated = errors.Is(err, ErrGraphTruncated)
+	if err != nil && !graph.Truncated {
+		return nil, grpcError(err)
+	}
+
+	out := &gatewaypb.Subgraph{Root: graph.Root, Truncated: graph.Truncated}
+	for _, e := range graph.Entities {
+		pb, err := entityToPB(e)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		out.Entities = append(out.Entities, pb)
+	}
+	for _, e := range graph.Edges {
+		pb, err := edgeToPB(e)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		out.Edges = append(out.Edges, pb)
+	}
+	for _, e := range graph.EntityTags {
+		pb, err := entityTagToPB(e)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		out.EntityTags = append(out.EntityTags, pb)
+	}
+	for _, e := range graph.EdgeTags {
+		pb, err := edgeTagToPB(e)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		out.EdgeTags = append(out.EdgeTags, pb)
+	}
+	return out, nil
+}
+
+// FindPaths returns the shortest paths between two entities, like GET
+// /entity/{id}/path/{to}, or none when they are not connected. Zero
+// max_depth and limit stand for their defaults.
+func (s *GrpcServer) FindPaths(ctx context.Context, in *gatewaypb.PathRequest) (*gatewaypb.PathResponse, error) {
+	max_depth, limit := int(in.GetMaxDepth()), int(in.GetLimit())
+	if max_depth == 0 {
+		max_depth = defaultPathDepth
+	}
+	if limit == 0 {
+		limit = defaultPathLimit
+	}
+	if max_depth < 0 || max_depth > maxPathDepth {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid max_depth: %d, must be between 1 and %d", max_depth, maxPathDepth)
+	}
+	if limit < 0 || limit > maxPathLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limit: %d, must be between 1 and %d", limit, maxPathLimit)
+	}
+
+	for _, id := range []string{in.GetFrom(), in.GetTo()} {
+		if _, err := s.api.store.FindEntityById(ctx, id); err != nil {
+			return nil, status.Errorf(codes.NotFound, "cannot find entity %s: %s", id, err)
+		}
+	}
+
+	paths, err := s.api.shortestPaths(ctx, in.GetFrom(), in.GetTo(), in.GetRelations(), max_depth, limit)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	out := &gatewaypb.PathResponse{}
+	for _, path := range paths {
+		pb := &gatewaypb.Path{}
+		for _, e := range path.Entities {
+			entity, err := entityToPB(e)
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			pb.Entities = append(pb.Entities, entity)
+		}
+		for _, e := range path.Edges {
+			edge, err := edgeToPB(e)
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			pb.Edges = append(pb.Edges, edge)
+		}
+		out.Paths = append(out.Paths, pb)
+	}
+	return out, nil
+}
+
+// Subscribe sends the response header once subscribed, so that clients
+// can tell when no event is missed anymore.
+func (s *GrpcServer) Subscribe(in *gatewaypb.SubscribeRequest, stream grpc.ServerStreamingServer[gatewaypb.Event]) error {
+	var types []EventType
+	for _, t := range in.GetTypes() {
+		types = append(types, EventType(t))
+	}
+
+	events := s.api.bus.Subscribe(stream.Context(), types...)
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for sse := range events {
+		data, err := toStruct(json.RawMessage(sse.Data.JSON()))
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		if err := stream.Send(&gatewaypb.Event{Type: string(sse.Event), Data: data}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ingest writes the objects of the stream through an importer, so that
+// the IDs sent by the client are mapped like those of an imported file.
+// It runs as an "ingest" job, whose ID is sent in the "job-id" header,
+// until the stream ends or the job is cancelled.
+func (s *GrpcServer) Ingest(stream grpc.ClientStreamingServer[gatewaypb.IngestRequest, gatewaypb.IngestResponse]) error {
+	// The stream is read apart from the job, so that the job stops when
+	// cancelled while the client sends nothing.
+	received := make(chan *gatewaypb.IngestRequest)
+	failed := make(chan error, 1)
+	go func() {
+		defer close(received)
+		for {
+			in, err := stream.Recv()
+			if err != nil {
+				if err != io.EOF {
+					failed <- err
+				}
+				return
+			}
+
+			select {
+			case received <- in:
+			case <-stream.Context().Done():
+				return
+			}
+		}
+	}()
+
+	var result *ImportResult
+	job := s.api.startJob("ingest", func(ctx context.Context, job *Job) (any, error) {
+		imp := s.api.newImporter(ctx, job)
+		result = &imp.result
+
+		for {
+			select {
+			case in, ok := <-received:
+				if !ok {
+					select {
+					case err := <-failed:
+						return result, err
+					default:
+						return result, nil
+					}
+				}
+				s.ingest(imp, in)
+				job.Advance(1)
+			case <-ctx.Done():
+				return result, ctx.Err()
+			}
+		}
+	})
+	grpc.SendHeader(stream.Context(), metadata.Pairs("job-id", job.ID))
+
+	select {
+	case <-job.Finished():
+	case <-stream.Context().Done():
+		job.cancel()
+		<-job.Finished()
+	}
+
+	switch finished := job.snapshot(); finished.State {
+	case JobCancelled:
+		return status.Error(codes.Canceled, "ingest cancelled")
+	case JobFailed:
+		return status.Error(codes.Internal, "ingest failed: "+finished.Error)
+	}
+
+	return stream.SendAndClose(&gatewaypb.IngestResponse{
+		Created:  int32(result.Created),
+		Updated:  int32(result.Updated),
+		Skipped:  int32(result.Skipped),
+		Rejected: int32(result.Rejected),
+		Errors:   result.Errors,
+		JobId:    job.ID,
+	})
+}
+
+// ingest writes an object sent to Ingest.
+func (s *GrpcServer) ingest(imp *importer, in *gatewaypb.IngestRequest) {
+	switch object := in.GetObject().(type) {
+	case *gatewaypb.IngestRequest_Entity:
+		var input Entity
+		if err := s.api.decodeFields(entityBody(object.Entity), &input); err != nil {
+			imp.reject("entity %s: %s", object.Entity.GetId(), err)
+		} else {
+			imp.entity(input)
+		}
+	case *gatewaypb.IngestRequest_Edge:
+		var input Edge
+		if err := s.api.decodeFields(edgeBody(object.Edge), &input); err != nil {
+			imp.reject("edge %s: %s", object.Edge.GetId(), err)
+		} else {
+			imp.edge(input)
+		}
+	case *gatewaypb.IngestRequest_EntityTag:
+		var input EntityTag
+		if err := s.api.decodeFields(entityTagBody(object.EntityTag), &input); err != nil {
+			imp.reject("entity tag %s: %s", object.EntityTag.GetId(), err)
+		} else {
+			imp.entityTag(input)
+		}
+	case *gatewaypb.IngestRequest_EdgeTag:
+		var input EdgeTag
+		if err := s.api.decodeFields(edgeTagBody(object.EdgeTag), &input); err != nil {
+			imp.reject("edge tag %s: %s", object.EdgeTag.GetId(), err)
+		} else {
+			imp.edgeTag(input)
+		}
+	default:
+		imp.reject("empty ingest request")
+	}
+}
@@ -0,0 +1,95 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcRouteGroup returns the route group of a gRPC method, as named in
+// the FullMethod of its calls.
+func grpcRouteGroup(full_method string) RouteGroup {
+	method := full_method[strings.LastIndex(full_method, "/")+1:]
+
+	switch {
+	case method == "Subscribe":
+		return ListenRoutes
+	case method == "Ingest",
+		strings.HasPrefix(method, "Create"),
+		strings.HasPrefix(method, "Update"),
+		strings.HasPrefix(method, "Patch"),
+		strings.HasPrefix(method, "Delete"):
+		return EmitRoutes
+	default:
+		return ReadRoutes
+	}
+}
+
+// grpcRequest carries the metadata and the peer of a call as the headers
+// and the remote address of a request, for its client to be identified
+// like those of the HTTP API.
+func grpcRequest(ctx context.Context) *http.Request {
+	r := &http.Request{Header: make(http.Header)}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+	return r
+}
+
+// admitCall checks the limits of a call, sending the limit headers as
+// response metadata. Admitted calls must be released by calling the
+// returned function.
+func (l *limiter) admitCall(ctx context.Context, full_method string) (func(), error) {
+	client := l.identify(grpcRequest(ctx))
+	group := grpcRouteGroup(full_method)
+
+	header := make(http.Header)
+	reason := l.admit(header, client, group)
+
+	md := metadata.MD{}
+	for key, values := range header {
+		md.Append(key, values...)
+	}
+	grpc.SetHeader(ctx, md)
+
+	if reason != "" {
+		return nil, status.Error(codes.ResourceExhausted, reason)
+	}
+	if group == ListenRoutes && l.limits.MaxSubscriptions > 0 {
+		return func() { l.done(client) }, nil
+	}
+	return func() {}, nil
+}
+
+func (l *limiter) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	release, err := l.admitCall(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return handler(ctx, req)
+}
+
+func (l *limiter) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	release, err := l.admitCall(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return handler(srv, stream)
+}
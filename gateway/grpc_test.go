@@ -0,0 +1,295 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/0ppliger/oam-broker/gatewaypb"
+	"github.com/0ppliger/oam-broker/memory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// newTestGrpc serves the gRPC API of a gateway over an in-memory
+// repository, and returns the gateway and a client of it.
+func newTestGrpc(t *testing.T, opts ...Option) (*Gateway, gatewaypb.GatewayClient) {
+	t.Helper()
+
+	g, err := New(append([]Option{WithRepository(memory.New())}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := g.grpcServer()
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return g, gatewaypb.NewGatewayClient(conn)
+}
+
+func fqdnPB(t *testing.T, name string) *gatewaypb.Entity {
+	t.Helper()
+
+	asset, err := structpb.NewStruct(map[string]any{"name": name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &gatewaypb.Entity{Type: "FQDN", Asset: asset}
+}
+
+func expectCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+
+	if status.Code(err) != code {
+		t.Errorf("got %v, expected %s", err, code)
+	}
+}
+
+func TestGrpcWrites(t *testing.T) {
+	_, client := newTestGrpc(t)
+	ctx := context.Background()
+
+	var header metadata.MD
+	created, err := client.CreateEntity(ctx, fqdnPB(t, "www.example.com"), grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag := header.Get("etag")
+	if len(etag) != 1 || created.GetId() == "" {
+		t.Fatalf("created %v with etag %v", created, etag)
+	}
+
+	got, err := client.GetEntity(ctx, &gatewaypb.GetRequest{Id: created.GetId()})
+	if err != nil || got.GetAsset().GetFields()["name"].GetStringValue() != "www.example.com" {
+		t.Fatalf("got %v, %v", got, err)
+	}
+
+	update := &gatewaypb.UpdateEntityRequest{Entity: fqdnPB(t, "app.example.com"), IfMatch: `"stale"`}
+	update.Entity.Id = created.GetId()
+	_, err = client.UpdateEntity(ctx, update)
+	expectCode(t, err, codes.Aborted)
+
+	update.IfMatch = etag[0]
+	updated, err := client.UpdateEntity(ctx, update, grpc.Header(&header))
+	if err != nil || updated.GetAsset().GetFields()["name"].GetStringValue() != "app.example.com" {
+		t.Fatalf("updated %v, %v", updated, err)
+	}
+
+	// A merge patch is an object, a JSON Patch a list of operations.
+	merge, _ := structpb.NewValue(map[string]any{"asset": map[string]any{"name": "api.example.com"}})
+	patched, err := client.PatchEntity(ctx, &gatewaypb.PatchRequest{Id: created.GetId(), Patch: merge, IfMatch: header.Get("etag")[0]})
+	if err != nil || patched.GetAsset().GetFields()["name"].GetStringValue() != "api.example.com" {
+		t.Fatalf("merge patched %v, %v", patched, err)
+	}
+
+	operations, _ := structpb.NewValue([]any{map[string]any{"op": "replace", "path": "/asset/name", "value": "cdn.example.com"}})
+	patched, err = client.PatchEntity(ctx, &gatewaypb.PatchRequest{Id: created.GetId(), Patch: operations})
+	if err != nil || patched.GetAsset().GetFields()["name"].GetStringValue() != "cdn.example.com" {
+		t.Fatalf("patched %v, %v", patched, err)
+	}
+
+	invalid, _ := structpb.NewValue([]any{map[string]any{"op": "remove", "path": "/nowhere"}})
+	_, err = client.PatchEntity(ctx, &gatewaypb.PatchRequest{Id: created.GetId(), Patch: invalid})
+	expectCode(t, err, codes.InvalidArgument)
+
+	// Fields the REST API rejects are rejected alike.
+	extra := fqdnPB(t, "www.example.com")
+	extra.Asset.Fields["extra"] = structpb.NewBoolValue(true)
+	_, err = client.CreateEntity(ctx, extra)
+	expectCode(t, err, codes.InvalidArgument)
+
+	if _, err := client.DeleteEntity(ctx, &gatewaypb.DeleteRequest{Id: created.GetId()}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.GetEntity(ctx, &gatewaypb.GetRequest{Id: created.GetId()})
+	expectCode(t, err, codes.NotFound)
+	// Like the REST API, deleting a missing entity is a bad request.
+	_, err = client.DeleteEntity(ctx, &gatewaypb.DeleteRequest{Id: created.GetId()})
+	expectCode(t, err, codes.InvalidArgument)
+}
+
+func TestGrpcIngest(t *testing.T) {
+	g, client := newTestGrpc(t)
+	ctx := context.Background()
+
+	stream, err := client.Ingest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The edge references the entities by the IDs of the stream.
+	www, ip := fqdnPB(t, "www.example.com"), &gatewaypb.Entity{Id: "ip", Type: "IPAddress"}
+	www.Id = "www"
+	ip.Asset, _ = structpb.NewStruct(map[string]any{"address": "192.0.2.1", "type": "IPv4"})
+	relation, _ := structpb.NewStruct(map[string]any{"label": "dns_record", "header": map[string]any{"rr_type": 1, "class": 1, "ttl": 60}})
+	edge := &gatewaypb.Edge{Type: "BasicDNSRelation", Relation: relation, FromEntity: "www", ToEntity: "ip"}
+
+	for _, in := range []*gatewaypb.IngestRequest{
+		{Object: &gatewaypb.IngestRequest_Entity{Entity: www}},
+		{Object: &gatewaypb.IngestRequest_Entity{Entity: ip}},
+		{Object: &gatewaypb.IngestRequest_Edge{Edge: edge}},
+		{Object: &gatewaypb.IngestRequest_Entity{Entity: www}},
+		{},
+	} {
+		if err := stream.Send(in); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	header, err := stream.Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetCreated() != 3 || resp.GetSkipped() != 1 || resp.GetRejected() != 1 {
+		t.Errorf("response %v", resp)
+	}
+
+	job, ok := g.api.jobs.Get(resp.GetJobId())
+	if !ok || header.Get("job-id")[0] != job.ID {
+		t.Fatalf("job %s of header %v", resp.GetJobId(), header.Get("job-id"))
+	}
+	if finished := job.snapshot(); finished.Kind != "ingest" || finished.State != JobSucceeded || finished.Done != 5 {
+		t.Errorf("job %+v", finished)
+	}
+}
+
+func TestGrpcIngestCancellation(t *testing.T) {
+	g, client := newTestGrpc(t)
+
+	stream, err := client.Ingest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&gatewaypb.IngestRequest{Object: &gatewaypb.IngestRequest_Entity{Entity: fqdnPB(t, "www.example.com")}}); err != nil {
+		t.Fatal(err)
+	}
+
+	header, err := stream.Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, ok := g.api.jobs.Get(header.Get("job-id")[0])
+	if !ok {
+		t.Fatal("no job")
+	}
+
+	// The job is cancelled while the client sends nothing.
+	job.cancel()
+
+	_, err = stream.CloseAndRecv()
+	expectCode(t, err, codes.Canceled)
+	if state := job.snapshot().State; state != JobCancelled {
+		t.Errorf("job %s", state)
+	}
+}
+
+func TestGrpcSubscribe(t *testing.T) {
+	_, client := newTestGrpc(t)
+
+	stream, err := client.Subscribe(context.Background(), &gatewaypb.SubscribeRequest{Types: []string{string(EntityCreated)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The header is sent once subscribed.
+	if _, err := stream.Header(); err != nil {
+		t.Fatal(err)
+	}
+
+	www, err := client.CreateEntity(context.Background(), fqdnPB(t, "www.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.GetType() != string(EntityCreated) || event.GetData().GetFields()["id"].GetStringValue() != www.GetId() {
+		t.Errorf("event %v", event)
+	}
+}
+
+func TestGrpcLimits(t *testing.T) {
+	_, client := newTestGrpc(t, WithLimits(Limits{DailyWrites: 1, MaxSubscriptions: 1}))
+	ctx := context.Background()
+
+	var header metadata.MD
+	if _, err := client.CreateEntity(ctx, fqdnPB(t, "www.example.com"), grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := header.Get("x-quota-remaining"); len(remaining) != 1 || remaining[0] != "0" {
+		t.Errorf("remaining quota %v", remaining)
+	}
+
+	_, err := client.CreateEntity(ctx, fqdnPB(t, "app.example.com"), grpc.Header(&header))
+	expectCode(t, err, codes.ResourceExhausted)
+	if len(header.Get("retry-after")) != 1 {
+		t.Errorf("no retry-after in %v", header)
+	}
+
+	stream, err := client.Ingest(ctx)
+	if err == nil {
+		_, err = stream.CloseAndRecv()
+	}
+	expectCode(t, err, codes.ResourceExhausted)
+
+	// Reads do not count as writes.
+	if _, err := client.GetEntity(ctx, &gatewaypb.GetRequest{Id: "unknown"}); status.Code(err) != codes.NotFound {
+		t.Errorf("read: %v", err)
+	}
+
+	// Admitted subscriptions wait for events until the deadline.
+	subscribe := func(timeout time.Duration) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		stream, err := client.Subscribe(ctx, &gatewaypb.SubscribeRequest{})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		return err
+	}
+
+	first, cancel := context.WithCancel(ctx)
+	subscription, err := client.Subscribe(first, &gatewaypb.SubscribeRequest{})
+	if err == nil {
+		_, err = subscription.Header()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectCode(t, subscribe(time.Second), codes.ResourceExhausted)
+
+	// Closing the first subscription releases it.
+	cancel()
+	for i := 0; ; i++ {
+		err := subscribe(100 * time.Millisecond)
+		if status.Code(err) == codes.DeadlineExceeded {
+			break
+		}
+		if i == 20 {
+			t.Fatalf("subscription not released: %v", err)
+		}
+	}
+}
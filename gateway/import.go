@@ -0,0 +1,573 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// maxImportErrors caps the number of errors kept in an ImportResult.
+const maxImportErrors = 100
+
+// importer writes the records of an imported graph into the store. The
+// IDs of the imported file are mapped to the IDs of the entities they
+// were deduplicated against; edges referencing IDs absent from the file
+// are resolved against the store directly.
+type importer struct {
+	api    *ApiV1
+	ctx    context.Context
+	job    *Job
+	result ImportResult
+
+	entities map[string]string
+	edges    map[string]string
+}
+
+func (api *ApiV1) newImporter(ctx context.Context, job *Job) *importer {
+	return &importer{
+		api:      api,
+		ctx:      ctx,
+		job:      job,
+		entities: make(map[string]string),
+		edges:    make(map[string]string),
+	}
+}
+
+func (imp *importer) reject(format string, args ...any) {
+	imp.result.Rejected++
+	if len(imp.result.Errors) < maxImportErrors {
+		imp.result.Errors = append(imp.result.Errors, fmt.Sprintf(format, args...))
+	}
+}
+
+func (imp *importer) count(outcome Outcome) {
+	switch outcome {
+	case Created:
+		imp.result.Created++
+	case Updated:
+		imp.result.Updated++
+	default:
+		imp.result.Skipped++
+	}
+}
+
+func (imp *importer) entityID(id string) string {
+	if mapped, ok := imp.entities[id]; ok {
+		return mapped
+	}
+	return id
+}
+
+func (imp *importer) edgeID(id string) string {
+	if mapped, ok := imp.edges[id]; ok {
+		return mapped
+	}
+	return id
+}
+
+func (imp *importer) entity(input Entity) {
+	src_id := input.ID
+	input.ID = ""
+
+	out, outcome, changes, err := imp.api.upsertEntity(input.ToStore(), "")
+	if err != nil {
+		imp.reject("entity %s: %s", src_id, err)
+		return
+	}
+	imp.entities[src_id] = out.ID
+	imp.count(outcome)
+
+	if !imp.api.tailing {
+		imp.api.bus.Publish(outcome.EntityEvent(), eventData(EntityFromStore(out), changes))
+	}
+}
+
+func (imp *importer) edge(input Edge) {
+	src_id := input.ID
+	input.ID = ""
+
+	from_entity, err := imp.api.store.FindEntityById(imp.ctx, imp.entityID(input.FromEntity))
+	if err != nil {
+		imp.reject("edge %s: cannot find from entity %s", src_id, input.FromEntity)
+		return
+	}
+
+	to_entity, err := imp.api.store.FindEntityById(imp.ctx, imp.entityID(input.ToEntity))
+	if err != nil {
+		imp.reject("edge %s: cannot find to entity %s", src_id, input.ToEntity)
+		return
+	}
+
+	out, outcome, changes, err := imp.api.upsertEdge(input.ToStore(from_entity, to_entity), "")
+	if err != nil {
+		imp.reject("edge %s: %s", src_id, err)
+		return
+	}
+	imp.edges[src_id] = out.ID
+	imp.count(outcome)
+
+	if !imp.api.tailing {
+		imp.api.bus.Publish(outcome.EdgeEvent(), eventData(EdgeFromStore(out), changes))
+	}
+}
+
+// entityTag imports a tag unless its entity already holds one with the
+// same content.
+func (imp *importer) entityTag(input EntityTag) {
+	entity, err := imp.api.store.FindEntityById(imp.ctx, imp.entityID(input.Entity))
+	if err != nil {
+		imp.reject("entity tag %s: cannot find entity %s", input.ID, input.Entity)
+		return
+	}
+
+	existing, _ := imp.api.store.GetEntityTags(imp.ctx, entity, time.Time{}, input.Property.Name())
+	for _, tag := range existing {
+		if sameContent(tag.Property, input.Property) {
+			imp.result.Skipped++
+			return
+		}
+	}
+
+	tag := input.ToStore()
+	tag.ID = ""
+	tag.Entity = entity
+	out, outcome, changes, err := imp.api.upsertEntityTag(tag, "")
+	if err != nil {
+		imp.reject("entity tag %s: %s", input.ID, err)
+		return
+	}
+	imp.count(outcome)
+
+	if !imp.api.tailing {
+		imp.api.bus.Publish(outcome.EntityTagEvent(), eventData(EntityTagFromStore(out), changes))
+	}
+}
+
+// edgeTag imports a tag unless its edge already holds one with the same
+// content.
+func (imp *importer) edgeTag(input EdgeTag) {
+	edge, err := imp.api.store.FindEdgeById(imp.ctx, imp.edgeID(input.Edge))
+	if err != nil {
+		imp.reject("edge tag %s: cannot find edge %s", input.ID, input.Edge)
+		return
+	}
+
+	existing, _ := imp.api.store.GetEdgeTags(imp.ctx, edge, time.Time{}, input.Property.Name())
+	for _, tag := range existing {
+		if sameContent(tag.Property, input.Property) {
+			imp.result.Skipped++
+			return
+		}
+	}
+
+	tag := input.ToStore()
+	tag.ID = ""
+	tag.Edge = edge
+	out, outcome, changes, err := imp.api.upsertEdgeTag(tag, "")
+	if err != nil {
+		imp.reject("edge tag %s: %s", input.ID, err)
+		return
+	}
+	imp.count(outcome)
+
+	if !imp.api.tailing {
+		imp.api.bus.Publish(outcome.EdgeTagEvent(), eventData(EdgeTagFromStore(out), changes))
+	}
+}
+
+// The importer is a GraphWriter, so that any walk of a graph can be
+// imported. An entity or an edge is one step of the job, along with its
+// tags.
+
+func (imp *importer) Begin() error {
+	return nil
+}
+
+func (imp *importer) Entity(entity Entity, tags []EntityTag) error {
+	defer imp.job.Advance(1)
+
+	imp.entity(entity)
+	for _, tag := range tags {
+		imp.entityTag(tag)
+	}
+	return imp.ctx.Err()
+}
+
+func (imp *importer) Edge(edge Edge, tags []EdgeTag) error {
+	defer imp.job.Advance(1)
+
+	imp.edge(edge)
+	for _, tag := range tags {
+		imp.edgeTag(tag)
+	}
+	return imp.ctx.Err()
+}
+
+func (imp *importer) End() error {
+	return nil
+}
+
+// newRecordScanner returns a scanner of the lines of the NDJSON format,
+// which may be long for assets with large content.
+func newRecordScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return scanner
+}
+
+// countRecords counts the records of the NDJSON format, each being a
+// step of the job.
+func countRecords(r io.Reader) (int, error) {
+	scanner := newRecordScanner(r)
+
+	n := 0
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > 0 {
+			n++
+		}
+	}
+	return n, scanner.Err()
+}
+
+// importRecords imports the NDJSON format written by /export.
+func (imp *importer) importRecords(r io.Reader) error {
+	scanner := newRecordScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		if err := imp.ctx.Err(); err != nil {
+			return err
+		}
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		imp.job.Advance(1)
+
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			imp.reject("line %d: %s", line, err)
+			continue
+		}
+
+		var err error
+		switch record.Kind {
+		case EntityRecord:
+			var input Entity
+			if err = imp.api.decodeObject(record.Data, &input); err == nil {
+				imp.entity(input)
+			}
+		case EdgeRecord:
+			var input Edge
+			if err = imp.api.decodeObject(record.Data, &input); err == nil {
+				imp.edge(input)
+			}
+		case EntityTagRecord:
+			var input EntityTag
+			if err = imp.api.decodeObject(record.Data, &input); err == nil {
+				imp.entityTag(input)
+			}
+		case EdgeTagRecord:
+			var input EdgeTag
+			if err = imp.api.decodeObject(record.Data, &input); err == nil {
+				imp.edgeTag(input)
+			}
+		default:
+			err = fmt.Errorf("unsupported record kind: %s", record.Kind)
+		}
+		if err != nil {
+			imp.reject("line %d: %s", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphmlElement struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphmlData `xml:"data"`
+}
+
+// importGraphML imports GraphML documents whose nodes and edges carry
+// "type" and "content" attributes, as written by /export. Attributes
+// are matched on their attr.name.
+func (imp *importer) importGraphML(r io.Reader) error {
+	decoder := xml.NewDecoder(r)
+	names := make(map[string]string)
+
+	for {
+		if err := imp.ctx.Err(); err != nil {
+			return err
+		}
+
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "key":
+			var id, name string
+			for _, attr := range start.Attr {
+				switch attr.Name.Local {
+				case "id":
+					id = attr.Value
+				case "attr.name":
+					name = attr.Value
+				}
+			}
+			names[id] = name
+		case "node", "edge":
+			var element graphmlElement
+			if err := decoder.DecodeElement(&element, &start); err != nil {
+				return err
+			}
+
+			imp.job.Advance(1)
+
+			data := make(map[string]string)
+			for _, d := range element.Data {
+				name, ok := names[d.Key]
+				if !ok {
+					name = d.Key
+				}
+				data[name] = d.Value
+			}
+
+			if start.Name.Local == "node" {
+				imp.graphmlNode(element, data)
+			} else {
+				imp.graphmlEdge(element, data)
+			}
+		}
+	}
+}
+
+// countGraphML counts the nodes and edges of a GraphML document, each
+// being a step of the job.
+func countGraphML(r io.Reader) (int, error) {
+	decoder := xml.NewDecoder(r)
+
+	n := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+
+		if start, ok := token.(xml.StartElement); ok && (start.Name.Local == "node" || start.Name.Local == "edge") {
+			n++
+		}
+	}
+}
+
+func (imp *importer) graphmlNode(element graphmlElement, data map[string]string) {
+	var input Entity
+	doc, _ := json.Marshal(map[string]any{
+		"id":    element.ID,
+		"type":  data["type"],
+		"asset": json.RawMessage(orNull(data["content"])),
+	})
+	if err := imp.api.decodeObject(doc, &input); err != nil {
+		imp.reject("node %s: %s", element.ID, err)
+		return
+	}
+	imp.entity(input)
+
+	var tags []json.RawMessage
+	json.Unmarshal([]byte(orNull(data["tags"])), &tags)
+	for _, raw := range tags {
+		var tag EntityTag
+		if err := imp.api.decodeObject(raw, &tag); err != nil {
+			imp.reject("node %s: tag: %s", element.ID, err)
+			continue
+		}
+		tag.Entity = element.ID
+		imp.entityTag(tag)
+	}
+}
+
+func (imp *importer) graphmlEdge(element graphmlElement, data map[string]string) {
+	var input Edge
+	doc, _ := json.Marshal(map[string]any{
+		"id":          element.ID,
+		"type":        data["type"],
+		"relation":    json.RawMessage(orNull(data["content"])),
+		"from_entity": element.Source,
+		"to_entity":   element.Target,
+	})
+	if err := imp.api.decodeObject(doc, &input); err != nil {
+		imp.reject("edge %s: %s", element.ID, err)
+		return
+	}
+	imp.edge(input)
+
+	var tags []json.RawMessage
+	json.Unmarshal([]byte(orNull(data["tags"])), &tags)
+	for _, raw := range tags {
+		var tag EdgeTag
+		if err := imp.api.decodeObject(raw, &tag); err != nil {
+			imp.reject("edge %s: tag: %s", element.ID, err)
+			continue
+		}
+		tag.Edge = element.ID
+		imp.edgeTag(tag)
+	}
+}
+
+func orNull(s string) string {
+	if s == "" {
+		return "null"
+	}
+	return s
+}
+
+// reader returns an API reading store with the settings of api. It
+// publishes nothing, the objects of store being only read.
+func (api *ApiV1) reader(store Repository) *ApiV1 {
+	return &ApiV1{
+		ctx:     api.ctx,
+		store:   store,
+		bus:     api.bus,
+		logger:  api.logger,
+		tailing: true,
+		prefix:  api.prefix,
+		lenient: api.lenient,
+	}
+}
+
+// countGraph counts the entities and edges of store, each being a step
+// of the job. The repository has no count query, so the graph is walked
+// once for it.
+func countGraph(ctx context.Context, store Repository) (int, error) {
+	n := 0
+	for atype := range assetTypes {
+		// The store reports empty results as errors.
+		entities, _ := store.FindEntitiesByType(ctx, atype, time.Time{})
+		for _, entity := range entities {
+			if err := ctx.Err(); err != nil {
+				return n, err
+			}
+			edges, _ := store.OutgoingEdges(ctx, entity, time.Time{})
+			n += 1 + len(edges)
+		}
+	}
+	return n, nil
+}
+
+// importSQLite imports an asset database written by Amass, by walking
+// it through the asset store's own SQLite repository.
+func (imp *importer) importSQLite(path string) error {
+	source, err := imp.api.openAssetDB(path)
+	if err != nil {
+		return fmt.Errorf("cannot open asset database: %w", err)
+	}
+	defer source.Close()
+
+	total, err := countGraph(imp.ctx, source)
+	if err != nil {
+		return err
+	}
+	imp.job.SetTotal(total)
+
+	return imp.api.reader(source).exportGraph(imp.ctx, GraphFilter{}, imp)
+}
+
+// importFile imports a file read twice: once to count its records,
+// then to import them.
+func importFile(count func(io.Reader) (int, error), read func(*importer, io.Reader) error) func(imp *importer, path string) error {
+	return func(imp *importer, path string) error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		total, err := count(f)
+		if err != nil {
+			return err
+		}
+		imp.job.SetTotal(total)
+
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return read(imp, f)
+	}
+}
+
+var importFormats = map[string]func(imp *importer, path string) error{
+	"ndjson":  importFile(countRecords, (*importer).importRecords),
+	"graphml": importFile(countGraphML, (*importer).importGraphML),
+	"sqlite":  (*importer).importSQLite,
+}
+
+// ImportGraph imports the file sent as body in the background. The
+// file is spooled to disk first, since the job outlives the request.
+func (api *ApiV1) ImportGraph(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		http.Error(w, "no body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "ndjson"
+	}
+
+	format, ok := importFormats[name]
+	if !ok || (name == "sqlite" && api.openAssetDB == nil) {
+		http.Error(w, "unsupported format: "+name, http.StatusBadRequest)
+		return
+	}
+
+	spool, err := os.CreateTemp("", "oag-import-*")
+	if err != nil {
+		http.Error(w, "Cannot store upload: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := io.Copy(spool, r.Body); err != nil {
+		spool.Close()
+		os.Remove(spool.Name())
+		http.Error(w, "Error reading request body: "+err.Error(), errorStatus(err))
+		return
+	}
+	spool.Close()
+
+	job := api.startJob("import", func(ctx context.Context, job *Job) (any, error) {
+		defer os.Remove(spool.Name())
+
+		imp := api.newImporter(ctx, job)
+		err := format(imp, spool.Name())
+		if err != nil && !errors.Is(err, context.Canceled) {
+			api.logger.Info("ImportGraph: " + err.Error())
+		}
+		return &imp.result, err
+	})
+
+	api.writeJob(w, job)
+}
@@ -0,0 +1,141 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/0ppliger/oam-broker/memory"
+	"github.com/0ppliger/oam-broker/wire"
+)
+
+// importGraph imports body in the given format and waits for the job to
+// finish.
+func (tg *testGateway) importGraph(format, body string) (wire.Job, ImportResult) {
+	tg.t.Helper()
+
+	resp, response := request(tg.t, "POST", tg.url+"/import?format="+format, body, nil)
+	expectStatus(tg.t, resp, http.StatusAccepted)
+	var job wire.Job
+	if err := json.Unmarshal(response, &job); err != nil {
+		tg.t.Fatal(err)
+	}
+
+	job = tg.waitJob(job.ID)
+	if job.State != JobSucceeded {
+		tg.t.Fatalf("import %s: %s %s", format, job.State, job.Error)
+	}
+	var result ImportResult
+	if err := json.Unmarshal(job.Result, &result); err != nil {
+		tg.t.Fatal(err)
+	}
+	return job, result
+}
+
+// expectImported checks that the graph of exportGraphFixture is in tg.
+func expectImported(tg *testGateway) {
+	tg.t.Helper()
+
+	_, body := tg.export("format=json")
+	var records []Record
+	if err := json.Unmarshal(body, &records); err != nil {
+		tg.t.Fatal(err)
+	}
+	expected := strings.Join([]string{EntityRecord, EntityTagRecord, EntityRecord, EdgeRecord, EdgeTagRecord}, " ")
+	if got := strings.Join(recordKinds(tg.t, records), " "); got != expected {
+		tg.t.Errorf("imported %s, want %s", got, expected)
+	}
+}
+
+func TestImportRecords(t *testing.T) {
+	source := newTestGateway(t)
+	exportGraphFixture(source)
+	_, exported := source.export("format=ndjson")
+
+	tg := newTestGateway(t)
+	body := string(exported) + "\nnot json\n" + `{"kind":"asset","data":{}}` + "\n"
+
+	job, result := tg.importGraph("ndjson", body)
+	if result.Created != 5 || result.Rejected != 2 || len(result.Errors) != 2 {
+		t.Errorf("first import: %+v", result)
+	}
+	// Every record is a step, rejected or not, and blank lines are not.
+	if job.Total != 7 || job.Done != 7 {
+		t.Errorf("first import at %d/%d, want 7/7", job.Done, job.Total)
+	}
+	expectImported(tg)
+
+	// Importing the same graph again changes nothing.
+	_, result = tg.importGraph("ndjson", string(exported))
+	if result.Created != 0 || result.Updated != 0 || result.Skipped != 5 {
+		t.Errorf("second import: %+v", result)
+	}
+	expectImported(tg)
+}
+
+func TestImportGraphML(t *testing.T) {
+	source := newTestGateway(t)
+	exportGraphFixture(source)
+	_, exported := source.export("format=graphml")
+
+	tg := newTestGateway(t)
+	job, result := tg.importGraph("graphml", string(exported))
+	if result.Created != 5 || result.Rejected != 0 {
+		t.Errorf("import: %+v", result)
+	}
+	// The steps are the nodes and edges, their tags coming along.
+	if job.Total != 3 || job.Done != 3 {
+		t.Errorf("import at %d/%d, want 3/3", job.Done, job.Total)
+	}
+	expectImported(tg)
+
+	// A document that cannot be counted fails before any import.
+	resp, body := request(t, "POST", tg.url+"/import?format=graphml", "<graphml><graph>", nil)
+	expectStatus(t, resp, http.StatusAccepted)
+	if err := json.Unmarshal(body, &job); err != nil {
+		t.Fatal(err)
+	}
+	if job = tg.waitJob(job.ID); job.State != JobFailed || job.Total != 0 {
+		t.Errorf("truncated document: %s at %d/%d", job.State, job.Done, job.Total)
+	}
+}
+
+func TestImportSQLite(t *testing.T) {
+	// The asset database is read through the repository interface, so an
+	// in-memory store stands for the SQLite file.
+	store := memory.New()
+	source := newTestGateway(t, WithRepository(store))
+	exportGraphFixture(source)
+
+	tg := newTestGateway(t, WithSQLiteImport(func(path string) (Repository, error) { return store, nil }))
+	events := tg.listen()
+	job, result := tg.importGraph("sqlite", "SQLite format 3")
+	if result.Created != 5 || result.Rejected != 0 {
+		t.Errorf("import: %+v", result)
+	}
+	// The steps are the entities and edges of the database.
+	if job.Total != 3 || job.Done != 3 {
+		t.Errorf("import at %d/%d, want 3/3", job.Done, job.Total)
+	}
+	expectImported(tg)
+
+	// What is created is published on the bus of the gateway.
+	var published []string
+	for got := range events {
+		if got.event == JobProgress || got.event == JobFinished {
+			continue
+		}
+		published = append(published, string(got.event))
+		if len(published) == 5 {
+			break
+		}
+	}
+	expected := strings.Join([]string{string(EntityCreated), string(EntityTagCreated), string(EntityCreated), string(EdgeCreated), string(EdgeTagCreated)}, " ")
+	if got := strings.Join(published, " "); got != expected {
+		t.Errorf("published %s, want %s", got, expected)
+	}
+
+	// Without a way to open them, asset databases are not accepted.
+	source.status("POST", "/import?format=sqlite", "SQLite format 3", http.StatusBadRequest)
+}
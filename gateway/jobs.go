@@ -0,0 +1,383 @@
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/0ppliger/oam-broker/wire"
+)
+
+// jobRetention is how long finished jobs are kept for their results to
+// be retrieved.
+const jobRetention = 24 * time.Hour
+
+// jobProgressInterval throttles the progress events of a job.
+const jobProgressInterval = time.Second
+
+// jobExpiryInterval is how often finished jobs are looked for expiry.
+const jobExpiryInterval = time.Hour
+
+// jobKinds maps the kinds accepted by POST /jobs to a function building
+// the job from its parameters.
+var jobKinds = map[string]func(api *ApiV1, params json.RawMessage) (JobFunc, error){
+	"prune": (*ApiV1).pruneJob,
+}
+
+// adminJobKinds are the kinds of jobs only admins may start, see, cancel
+// and get the result of.
+var adminJobKinds = map[string]bool{
+	"prune": true,
+}
+
+// Job is a long-running operation executed in the background, out of
+// the lifetime of the request that started it.
+type Job struct {
+	mutex sync.Mutex
+
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	State      JobState  `json:"state"`
+	Done       int       `json:"done"`
+	Total      int       `json:"total"`
+	Result     any       `json:"result,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+
+	// admin jobs are only accessible with an admin key.
+	admin        bool
+	cancel       context.CancelFunc
+	done         chan struct{}
+	bus          *EventBus
+	lastProgress time.Time
+}
+
+func (job *Job) JSON() []byte {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	json_encoded, _ := json.Marshal(job)
+	return json_encoded
+}
+
+// snapshot returns the state of the job at the time of the call, for
+// events to report it as it was when they were published.
+func (job *Job) snapshot() wire.Job {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	out := wire.Job{
+		ID:         job.ID,
+		Kind:       job.Kind,
+		State:      job.State,
+		Done:       job.Done,
+		Total:      job.Total,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.Result != nil {
+		out.Result, _ = json.Marshal(job.Result)
+	}
+	return out
+}
+
+// Finished is closed once the job finished.
+func (job *Job) Finished() <-chan struct{} {
+	return job.done
+}
+
+// SetTotal sets the number of steps the job has to go through.
+func (job *Job) SetTotal(total int) {
+	job.mutex.Lock()
+	job.Total = total
+	job.mutex.Unlock()
+
+	job.progress(false)
+}
+
+// Advance records that n more steps are done.
+func (job *Job) Advance(n int) {
+	job.mutex.Lock()
+	job.Done += n
+	job.mutex.Unlock()
+
+	job.progress(false)
+}
+
+// progress publishes the state of the job, at most once per
+// jobProgressInterval unless forced.
+func (job *Job) progress(force bool) {
+	if job.bus == nil {
+		return
+	}
+
+	job.mutex.Lock()
+	if !force && time.Since(job.lastProgress) < jobProgressInterval {
+		job.mutex.Unlock()
+		return
+	}
+	job.lastProgress = time.Now()
+
+	event := JobProgress
+	if job.State != JobRunning {
+		event = JobFinished
+	}
+	job.mutex.Unlock()
+
+	job.bus.Publish(event, job.snapshot())
+}
+
+func (job *Job) finish(result any, err error) {
+	job.mutex.Lock()
+	job.Result = result
+	job.FinishedAt = time.Now()
+	switch {
+	case errors.Is(err, context.Canceled):
+		job.State = JobCancelled
+	case err != nil:
+		job.State = JobFailed
+		job.Error = err.Error()
+	default:
+		job.State = JobSucceeded
+	}
+	job.mutex.Unlock()
+
+	job.progress(true)
+}
+
+// JobFunc runs a job, reporting progress on it, and returns its result.
+// It must return when ctx is cancelled.
+type JobFunc func(ctx context.Context, job *Job) (any, error)
+
+type JobManager struct {
+	mutex sync.Mutex
+	jobs  map[string]*Job
+}
+
+// Start runs fn in the background as a job of the given kind, until it
+// returns or ctx is cancelled. Progress is published on bus.
+func (jm *JobManager) Start(ctx context.Context, bus *EventBus, kind string, fn JobFunc) *Job {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	ctx, cancel := context.WithCancel(ctx)
+	job := &Job{
+		ID:        hex.EncodeToString(id),
+		Kind:      kind,
+		State:     JobRunning,
+		CreatedAt: time.Now(),
+		admin:     adminJobKinds[kind],
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		bus:       bus,
+	}
+
+	jm.mutex.Lock()
+	if jm.jobs == nil {
+		jm.jobs = make(map[string]*Job)
+	}
+	jm.jobs[job.ID] = job
+	jm.mutex.Unlock()
+
+	go func() {
+		defer close(job.done)
+		defer cancel()
+		// A failing job must not take the gateway down with it.
+		defer func() {
+			if p := recover(); p != nil {
+				job.finish(nil, fmt.Errorf("job panicked: %v", p))
+			}
+		}()
+
+		result, err := fn(ctx, job)
+		job.finish(result, err)
+	}()
+
+	return job
+}
+
+// Run expires the finished jobs every jobExpiryInterval until ctx is
+// cancelled.
+func (jm *JobManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(jobExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			jm.expire()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// expire forgets the jobs finished for longer than jobRetention.
+func (jm *JobManager) expire() {
+	jm.mutex.Lock()
+	defer jm.mutex.Unlock()
+
+	for id, job := range jm.jobs {
+		job.mutex.Lock()
+		expired := job.State != JobRunning && time.Since(job.FinishedAt) > jobRetention
+		job.mutex.Unlock()
+
+		if expired {
+			delete(jm.jobs, id)
+		}
+	}
+}
+
+func (jm *JobManager) Get(id string) (*Job, bool) {
+	jm.mutex.Lock()
+	defer jm.mutex.Unlock()
+
+	job, ok := jm.jobs[id]
+	return job, ok
+}
+
+// List returns every known job, oldest first.
+func (jm *JobManager) List() []*Job {
+	jm.mutex.Lock()
+	defer jm.mutex.Unlock()
+
+	jobs := make([]*Job, 0, len(jm.jobs))
+	for _, job := range jm.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// startJob starts a job bound to the lifetime of the gateway rather
+// than to the request, so that it survives client disconnects.
+func (api *ApiV1) startJob(kind string, fn JobFunc) *Job {
+	return api.jobs.Start(api.ctx, api.bus, kind, fn)
+}
+
+// writeJob answers a request that started a job with 202 Accepted.
+func (api *ApiV1) writeJob(w http.ResponseWriter, job *Job) {
+	w.Header().Set("Location", api.prefix+"/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	w.Write(job.JSON())
+}
+
+type JobRequest struct {
+	Kind   string          `json:"kind"`
+	Params json.RawMessage `json:"params"`
+}
+
+func (api *ApiV1) CreateJob(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		http.Error(w, "no body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var input JobRequest
+
+	if err := readJSON(r, &input); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	build, ok := jobKinds[input.Kind]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unsupported job kind: %s", input.Kind), http.StatusBadRequest)
+		return
+	}
+	if adminJobKinds[input.Kind] && !api.requireAdmin(w, r) {
+		return
+	}
+
+	fn, err := build(api, input.Params)
+	if err != nil {
+		http.Error(w, "invalid params: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	api.writeJob(w, api.startJob(input.Kind, fn))
+}
+
+// ListJobs lists the admin jobs only to the requests carrying an admin
+// key.
+func (api *ApiV1) ListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs := api.jobs.List()
+	admin := api.isAdmin(r)
+
+	out := make([]json.RawMessage, 0, len(jobs))
+	for _, job := range jobs {
+		if job.admin && !admin {
+			continue
+		}
+		out = append(out, job.JSON())
+	}
+
+	json_encoded, _ := json.Marshal(out)
+	w.Write(json_encoded)
+}
+
+func (api *ApiV1) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := api.jobs.Get(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "Cannot find job", http.StatusNotFound)
+		return
+	}
+	if job.admin && !api.requireAdmin(w, r) {
+		return
+	}
+
+	w.Write(job.JSON())
+}
+
+// GetJobResult returns the result of a finished job alone.
+func (api *ApiV1) GetJobResult(w http.ResponseWriter, r *http.Request) {
+	job, ok := api.jobs.Get(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "Cannot find job", http.StatusNotFound)
+		return
+	}
+	if job.admin && !api.requireAdmin(w, r) {
+		return
+	}
+
+	job.mutex.Lock()
+	state, result := job.State, job.Result
+	job.mutex.Unlock()
+
+	if state == JobRunning {
+		http.Error(w, "Job is still running", http.StatusConflict)
+		return
+	}
+
+	json_encoded, _ := json.Marshal(result)
+	w.Write(json_encoded)
+}
+
+// CancelJob requests a running job to stop. The job is reported as
+// cancelled once it has returned.
+func (api *ApiV1) CancelJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := api.jobs.Get(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "Cannot find job", http.StatusNotFound)
+		return
+	}
+	if job.admin && !api.requireAdmin(w, r) {
+		return
+	}
+
+	job.cancel()
+
+	api.writeJob(w, job)
+}
@@ -0,0 +1,192 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0ppliger/oam-broker/eventbus"
+	"github.com/0ppliger/oam-broker/wire"
+)
+
+// finished waits for the JobFinished event of a job on events, and
+// returns the job it reports.
+func finished(t *testing.T, events <-chan ServerSentEvent) wire.Job {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case sse := <-events:
+			if sse.Event == JobFinished {
+				return sse.Data.(wire.Job)
+			}
+		case <-timeout:
+			t.Fatal("job did not finish")
+			return wire.Job{}
+		}
+	}
+}
+
+func TestJobs(t *testing.T) {
+	cases := []struct {
+		name   string
+		fn     JobFunc
+		state  JobState
+		result string
+		error  string
+	}{
+		{
+			name:   "succeeds",
+			fn:     func(ctx context.Context, job *Job) (any, error) { return map[string]int{"deleted": 2}, nil },
+			state:  JobSucceeded,
+			result: `{"deleted":2}`,
+		},
+		{
+			name:  "fails",
+			fn:    func(ctx context.Context, job *Job) (any, error) { return nil, errors.New("store is down") },
+			state: JobFailed,
+			error: "store is down",
+		},
+		{
+			name:  "panics",
+			fn:    func(ctx context.Context, job *Job) (any, error) { panic("nil entity") },
+			state: JobFailed,
+			error: "job panicked: nil entity",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var jm JobManager
+			bus := eventbus.New()
+			events := bus.Subscribe(ctx, JobFinished)
+
+			job := jm.Start(ctx, bus, "test", c.fn)
+			got := finished(t, events)
+			if got.ID != job.ID || got.State != c.state || got.Error != c.error || string(got.Result) != c.result {
+				t.Errorf("finished with %s", got.JSON())
+			}
+			if got.FinishedAt.IsZero() {
+				t.Error("no finish time")
+			}
+		})
+	}
+}
+
+func TestJobCancellation(t *testing.T) {
+	var jm JobManager
+	started := make(chan struct{})
+	job := jm.Start(context.Background(), nil, "test", func(ctx context.Context, job *Job) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	<-started
+
+	job.cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var got wire.Job
+		if err := json.Unmarshal(job.JSON(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.State == JobCancelled {
+			if got.Error != "" {
+				t.Errorf("cancelled job has error %q", got.Error)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job still %s", got.State)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJobProgressIsThrottled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var jm JobManager
+	bus := eventbus.New()
+	events := bus.Subscribe(ctx)
+
+	release := make(chan struct{})
+	jm.Start(ctx, bus, "test", func(ctx context.Context, job *Job) (any, error) {
+		job.SetTotal(100)
+		for range 100 {
+			job.Advance(1)
+		}
+		<-release
+		return nil, nil
+	})
+
+	var progress []wire.Job
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case sse := <-events:
+			job := sse.Data.(wire.Job)
+			if sse.Event == JobFinished {
+				if job.Done != 100 || job.Total != 100 {
+					t.Errorf("finished at %d/%d", job.Done, job.Total)
+				}
+				done = true
+				continue
+			}
+			progress = append(progress, job)
+			if len(progress) == 1 {
+				close(release)
+			}
+		case <-timeout:
+			t.Fatal("job did not finish")
+		}
+	}
+
+	// The steps are done within jobProgressInterval: the first is
+	// published, the others wait for the forced event of the end.
+	if len(progress) != 1 {
+		t.Fatalf("%d progress events, want 1", len(progress))
+	}
+	// The event reports the job as it was when published, not as it
+	// went on.
+	if progress[0].Done != 0 || progress[0].Total != 100 {
+		t.Errorf("progress at %d/%d, want 0/100", progress[0].Done, progress[0].Total)
+	}
+}
+
+func TestJobsExpire(t *testing.T) {
+	var jm JobManager
+	old := jm.Start(context.Background(), nil, "test", func(ctx context.Context, job *Job) (any, error) { return nil, nil })
+	running := jm.Start(context.Background(), nil, "test", func(ctx context.Context, job *Job) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	defer running.cancel()
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if strings.Contains(string(old.JSON()), `"state":"succeeded"`) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("job did not finish")
+		}
+	}
+	old.mutex.Lock()
+	old.FinishedAt = time.Now().Add(-jobRetention - time.Minute)
+	old.mutex.Unlock()
+
+	jm.expire()
+	if _, ok := jm.Get(old.ID); ok {
+		t.Error("expired job still listed")
+	}
+	if _, ok := jm.Get(running.ID); !ok {
+		t.Error("running job expired")
+	}
+}
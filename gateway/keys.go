@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+)
+
+// AdminKeyHeader carries the admin key of requests to the admin routes,
+// apart from the API key, which selects the workspace.
+const AdminKeyHeader = "X-Admin-Key"
+
+// keySet holds the SHA-256 sums of API keys, compared in constant time.
+type keySet [][sha256.Size]byte
+
+func newKeySet(keys []string) keySet {
+	var ks keySet
+	for _, key := range keys {
+		ks = append(ks, sha256.Sum256([]byte(key)))
+	}
+	return ks
+}
+
+// has tells whether key is one of the keys of the set.
+func (ks keySet) has(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	ok := 0
+	for _, k := range ks {
+		ok |= subtle.ConstantTimeCompare(sum[:], k[:])
+	}
+	return ok == 1
+}
+
+// isAdmin tells whether a request carries an admin key.
+func (api *ApiV1) isAdmin(r *http.Request) bool {
+	return api.admin_keys.has(r.Header.Get(AdminKeyHeader))
+}
+
+// requireAdmin answers requests that do not carry an admin key, and
+// tells whether they do. Without admin keys, the admin operations are
+// disabled.
+func (api *ApiV1) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if len(api.admin_keys) == 0 {
+		http.Error(w, "admin operations are disabled: no admin key is configured", http.StatusForbidden)
+		return false
+	}
+	if !api.isAdmin(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		http.Error(w, "invalid admin key in "+AdminKeyHeader, http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
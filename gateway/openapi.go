@@ -0,0 +1,427 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+func schemaRef(name string) Schema {
+	return Schema{"$ref": "#/components/schemas/" + name}
+}
+
+// discriminated adds to schemas the schema of every type of a registry,
+// the variant of the object of the given kind holding each of them in
+// its content field, and the oneOf of the variants discriminated by
+// their "type" field.
+func discriminated[K ~string](schemas Schema, kind string, registry map[K]reflect.Type, content string, fields Schema, required []string) {
+	var variants []Schema
+	mapping := Schema{}
+
+	for _, key := range sortedKeys(registry) {
+		name := string(key)
+		schemas[name] = JSONSchema(registry[key])
+
+		properties := Schema{
+			"id":         Schema{"type": "string"},
+			"created_at": Schema{"type": "string", "format": "date-time"},
+			"last_seen":  Schema{"type": "string", "format": "date-time"},
+			"type":       Schema{"const": name},
+			content:      schemaRef(name),
+		}
+		for field, schema := range fields {
+			properties[field] = schema
+		}
+
+		variant := name + kind
+		schemas[variant] = Schema{
+			"type":       "object",
+			"properties": properties,
+			"required":   append([]string{"type", content}, required...),
+		}
+		variants = append(variants, schemaRef(variant))
+		mapping[name] = "#/components/schemas/" + variant
+	}
+
+	schemas[kind] = Schema{
+		"oneOf": variants,
+		"discriminator": Schema{
+			"propertyName": "type",
+			"mapping":      mapping,
+		},
+	}
+}
+
+// openapiSchemas returns the schemas of the objects of the API. The
+// polymorphic objects are generated from the registries.
+func openapiSchemas() Schema {
+	schemas := Schema{}
+
+	discriminated(schemas, "Entity", assetTypes, "asset", nil, nil)
+	discriminated(schemas, "Edge", relationTypes, "relation", Schema{
+		"from_entity": Schema{"type": "string"},
+		"to_entity":   Schema{"type": "string"},
+	}, []string{"from_entity", "to_entity"})
+	discriminated(schemas, "EntityTag", propertyTypes, "property", Schema{
+		"entity": Schema{"type": "string"},
+	}, []string{"entity"})
+	discriminated(schemas, "EdgeTag", propertyTypes, "property", Schema{
+		"edge": Schema{"type": "string"},
+	}, []string{"edge"})
+
+	list := func(name string) Schema {
+		return Schema{"type": "array", "items": schemaRef(name)}
+	}
+
+	schemas["Cascade"] = Schema{
+		"type": "object",
+		"properties": Schema{
+			"entity":      schemaRef("Entity"),
+			"edges":       list("Edge"),
+			"entity_tags": list("EntityTag"),
+			"edge_tags":   list("EdgeTag"),
+		},
+		"required": []string{"entity", "edges", "entity_tags", "edge_tags"},
+	}
+
+	schemas["Subgraph"] = Schema{
+		"type": "object",
+		"properties": Schema{
+			"root":        Schema{"type": "string"},
+			"entities":    list("Entity"),
+			"edges":       list("Edge"),
+			"entity_tags": list("EntityTag"),
+			"edge_tags":   list("EdgeTag"),
+			"truncated":   Schema{"type": "boolean"},
+		},
+		"required": []string{"root", "entities", "edges", "truncated"},
+	}
+
+	schemas["Path"] = Schema{
+		"type": "object",
+		"properties": Schema{
+			"entities": list("Entity"),
+			"edges":    list("Edge"),
+		},
+		"required": []string{"entities", "edges"},
+	}
+
+	schemas["PathResult"] = Schema{
+		"type": "object",
+		"properties": Schema{
+			"from":   Schema{"type": "string"},
+			"to":     Schema{"type": "string"},
+			"length": Schema{"type": "integer"},
+			"paths":  list("Path"),
+		},
+		"required": []string{"from", "to", "length", "paths"},
+	}
+
+	catalog := Schema{"type": "object", "additionalProperties": Schema{"type": "object"}}
+	schemas["TypeCatalog"] = Schema{
+		"type": "object",
+		"properties": Schema{
+			"assets":     catalog,
+			"relations":  catalog,
+			"properties": catalog,
+		},
+		"required": []string{"assets", "relations", "properties"},
+	}
+
+	schemas["Job"] = Schema{
+		"type": "object",
+		"properties": Schema{
+			"id":          Schema{"type": "string"},
+			"kind":        Schema{"type": "string"},
+			"state":       Schema{"enum": []JobState{JobRunning, JobSucceeded, JobFailed, JobCancelled}},
+			"done":        Schema{"type": "integer"},
+			"total":       Schema{"type": "integer"},
+			"result":      Schema{},
+			"error":       Schema{"type": "string"},
+			"created_at":  Schema{"type": "string", "format": "date-time"},
+			"finished_at": Schema{"type": "string", "format": "date-time"},
+		},
+		"required": []string{"id", "kind", "state", "done", "total", "created_at"},
+	}
+
+	schemas["PatchOperation"] = Schema{
+		"type": "object",
+		"properties": Schema{
+			"op":    Schema{"enum": []string{"add", "remove", "replace", "move", "copy", "test"}},
+			"path":  Schema{"type": "string"},
+			"from":  Schema{"type": "string"},
+			"value": Schema{},
+		},
+		"required": []string{"op", "path"},
+	}
+
+	return schemas
+}
+
+func jsonContent(schema Schema) Schema {
+	return Schema{"application/json": Schema{"schema": schema}}
+}
+
+// errorResponse describes the plain text errors of the handlers.
+func errorResponse(description string) Schema {
+	return Schema{
+		"description": description,
+		"content":     Schema{"text/plain": Schema{"schema": Schema{"type": "string"}}},
+	}
+}
+
+func objectResponse(description string, schema Schema) Schema {
+	return Schema{
+		"description": description,
+		"headers": Schema{
+			"ETag": Schema{"schema": Schema{"type": "string"}},
+		},
+		"content": jsonContent(schema),
+	}
+}
+
+func parameter(name, in, description string, required bool) Schema {
+	return Schema{
+		"name":        name,
+		"in":          in,
+		"description": description,
+		"required":    required,
+		"schema":      Schema{"type": "string"},
+	}
+}
+
+var (
+	idParameter      = parameter("id", "path", "", true)
+	ifMatchParameter = parameter("If-Match", "header", "The ETag the object must still have to be written.", false)
+)
+
+// objectPaths describes the routes reading and writing an object.
+func objectPaths(paths Schema, route, kind string) {
+	object := schemaRef(kind)
+
+	paths["/"+route+"/{id}"] = Schema{
+		"get": Schema{
+			"operationId": "get" + kind,
+			"parameters":  []Schema{idParameter},
+			"responses": Schema{
+				"200": objectResponse("The "+route+" as stored.", object),
+				"404": errorResponse("Unknown ID."),
+			},
+		},
+	}
+
+	paths["/emit/"+route] = Schema{
+		"post": Schema{
+			"operationId": "create" + kind,
+			"requestBody": Schema{"required": true, "content": jsonContent(object)},
+			"responses": Schema{
+				"200": objectResponse("The "+route+" as stored.", object),
+				"400": errorResponse("Invalid body."),
+			},
+		},
+	}
+
+	remove := Schema{
+		"operationId": "delete" + kind,
+		"parameters":  []Schema{idParameter, ifMatchParameter},
+		"responses": Schema{
+			"200": objectResponse("The "+route+" as it was before deletion.", object),
+			"400": errorResponse("Cannot delete."),
+			"412": errorResponse("If-Match does not match."),
+		},
+	}
+
+	if kind == "Entity" {
+		cascade := parameter("cascade", "query", "true deletes the edges and tags of the entity along with it, preview only lists them.", false)
+		cascade["schema"] = Schema{"enum": []string{"true", "false", "preview"}}
+		remove["parameters"] = []Schema{idParameter, ifMatchParameter, cascade}
+		remove["responses"] = Schema{
+			"200": objectResponse("The entity as it was before deletion, or its cascade.", Schema{
+				"oneOf": []Schema{object, schemaRef("Cascade")},
+			}),
+			"400": errorResponse("Cannot delete."),
+			"409": errorResponse("The entity has edges and cascade is not set."),
+			"412": errorResponse("If-Match does not match."),
+		}
+	}
+
+	paths["/emit/"+route+"/{id}"] = Schema{
+		"put": Schema{
+			"operationId": "update" + kind,
+			"parameters":  []Schema{idParameter, ifMatchParameter},
+			"requestBody": Schema{"required": true, "content": jsonContent(object)},
+			"responses": Schema{
+				"200": objectResponse("The "+route+" as stored.", object),
+				"400": errorResponse("Invalid body."),
+				"404": errorResponse("Unknown ID."),
+				"412": errorResponse("If-Match does not match."),
+			},
+		},
+		"patch": Schema{
+			"operationId": "patch" + kind,
+			"parameters":  []Schema{idParameter, ifMatchParameter},
+			"requestBody": Schema{
+				"required": true,
+				"content": Schema{
+					MergePatchType: Schema{"schema": Schema{"type": "object"}},
+					JSONPatchType:  Schema{"schema": Schema{"type": "array", "items": schemaRef("PatchOperation")}},
+				},
+			},
+			"responses": Schema{
+				"200": objectResponse("The "+route+" as stored.", object),
+				"400": errorResponse("Invalid patch."),
+				"404": errorResponse("Unknown ID."),
+				"412": errorResponse("If-Match does not match."),
+			},
+		},
+		"delete": remove,
+	}
+}
+
+func openapiPaths() Schema {
+	paths := Schema{}
+
+	objectPaths(paths, "entity", "Entity")
+	objectPaths(paths, "edge", "Edge")
+	objectPaths(paths, "entity_tag", "EntityTag")
+	objectPaths(paths, "edge_tag", "EdgeTag")
+
+	query := func(name, description string) Schema {
+		return parameter(name, "query", description, false)
+	}
+
+	paths["/entity/{id}/graph"] = Schema{
+		"get": Schema{
+			"operationId": "getEntityGraph",
+			"parameters": []Schema{
+				idParameter,
+				query("depth", "Number of hops, 1 by default."),
+				query("relations", "Comma-separated edge labels to follow."),
+				query("asset_types", "Comma-separated asset types to return."),
+				query("limit", "Maximum number of entities."),
+				query("tags", "true to return tags."),
+			},
+			"responses": Schema{
+				"200": Schema{"description": "The neighbourhood of the entity.", "content": jsonContent(schemaRef("Subgraph"))},
+				"400": errorResponse("Invalid parameter."),
+				"404": errorResponse("Unknown ID."),
+			},
+		},
+	}
+
+	paths["/entity/{id}/path/{to}"] = Schema{
+		"get": Schema{
+			"operationId": "getEntityPath",
+			"parameters": []Schema{
+				idParameter,
+				parameter("to", "path", "", true),
+				query("relations", "Comma-separated edge labels to follow."),
+				query("max_depth", "Maximum number of hops."),
+				query("limit", "Maximum number of paths."),
+			},
+			"responses": Schema{
+				"200": Schema{"description": "The shortest paths between the entities.", "content": jsonContent(schemaRef("PathResult"))},
+				"400": errorResponse("Invalid parameter."),
+				"404": errorResponse("Unknown ID, or no path."),
+			},
+		},
+	}
+
+	job := Schema{"description": "The job.", "content": jsonContent(schemaRef("Job"))}
+	admin_key := parameter(AdminKeyHeader, "header", "The admin key, required by the admin jobs, such as prune.", false)
+	unauthorized := errorResponse("The job requires an admin key.")
+
+	paths["/jobs"] = Schema{
+		"post": Schema{
+			"operationId": "createJob",
+			"parameters":  []Schema{admin_key},
+			"requestBody": Schema{"required": true, "content": jsonContent(Schema{
+				"type": "object",
+				"properties": Schema{
+					"kind":   Schema{"type": "string"},
+					"params": Schema{"type": "object"},
+				},
+				"required": []string{"kind"},
+			})},
+			"responses": Schema{
+				"202": job,
+				"400": errorResponse("Unsupported kind, or invalid params."),
+				"401": unauthorized,
+				"403": errorResponse("Admin jobs are disabled."),
+			},
+		},
+		"get": Schema{
+			"operationId": "listJobs",
+			"parameters":  []Schema{admin_key},
+			"responses": Schema{
+				"200": Schema{"description": "The jobs, oldest first, the admin jobs only for admins.", "content": jsonContent(Schema{"type": "array", "items": schemaRef("Job")})},
+			},
+		},
+	}
+
+	paths["/jobs/{id}"] = Schema{
+		"get": Schema{
+			"operationId": "getJob",
+			"parameters":  []Schema{idParameter, admin_key},
+			"responses": Schema{
+				"200": job,
+				"401": unauthorized,
+				"404": errorResponse("Unknown ID."),
+			},
+		},
+		"delete": Schema{
+			"operationId": "cancelJob",
+			"parameters":  []Schema{idParameter, admin_key},
+			"responses": Schema{
+				"202": job,
+				"401": unauthorized,
+				"404": errorResponse("Unknown ID."),
+			},
+		},
+	}
+
+	paths["/jobs/{id}/result"] = Schema{
+		"get": Schema{
+			"operationId": "getJobResult",
+			"parameters":  []Schema{idParameter, admin_key},
+			"responses": Schema{
+				"200": Schema{"description": "The result of the job, depending on its kind.", "content": jsonContent(Schema{})},
+				"401": unauthorized,
+				"404": errorResponse("Unknown ID."),
+				"409": errorResponse("The job is still running."),
+			},
+		},
+	}
+
+	paths["/types"] = Schema{
+		"get": Schema{
+			"operationId": "getTypes",
+			"responses": Schema{
+				"200": Schema{"description": "The JSON Schema of every asset, relation and property type.", "content": jsonContent(schemaRef("TypeCatalog"))},
+			},
+		},
+	}
+
+	return paths
+}
+
+// openapiDocument is generated once: the registries do not change at
+// runtime.
+var openapiDocument = sync.OnceValue(func() []byte {
+	json_encoded, _ := json.Marshal(Schema{
+		"openapi": "3.1.0",
+		"info": Schema{
+			"title":   "Open Asset Gateway",
+			"version": "1",
+		},
+		"paths":      openapiPaths(),
+		"components": Schema{"schemas": openapiSchemas()},
+	})
+	return json_encoded
+})
+
+func (api *ApiV1) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openapiDocument())
+}
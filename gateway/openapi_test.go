@@ -0,0 +1,246 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0ppliger/oam-broker/memory"
+)
+
+// validate checks a decoded JSON value against the subset of JSON
+// Schema used by the OpenAPI document.
+func validate(document map[string]any, schema map[string]any, value any, at string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+		target, ok := schemas[name].(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: unresolved %s", at, ref)
+		}
+		return validate(document, target, value, at)
+	}
+
+	if variants, ok := schema["oneOf"].([]any); ok {
+		matched := 0
+		for _, variant := range variants {
+			if validate(document, variant.(map[string]any), value, at) == nil {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return fmt.Errorf("%s: matches %d of oneOf", at, matched)
+		}
+	}
+
+	if constant, ok := schema["const"]; ok && constant != value {
+		return fmt.Errorf("%s: %v is not %v", at, value, constant)
+	}
+
+	if values, ok := schema["enum"].([]any); ok && !slices.Contains(values, value) {
+		return fmt.Errorf("%s: %v is not in %v", at, value, values)
+	}
+
+	switch schema["type"] {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: %v is not a string", at, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: %v is not a boolean", at, value)
+		}
+	case "integer", "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: %v is not a number", at, value)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: %v is not an array", at, value)
+		}
+		if item_schema, ok := schema["items"].(map[string]any); ok {
+			for i, item := range items {
+				if err := validate(document, item_schema, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: %v is not an object", at, value)
+		}
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing %s", at, name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for name, property := range object {
+			if property_schema, ok := properties[name].(map[string]any); ok {
+				if err := validate(document, property_schema, property, at+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func openapiForTest(t *testing.T, api *ApiV1) map[string]any {
+	rec := httptest.NewRecorder()
+	api.GetOpenAPI(rec, httptest.NewRequest("GET", "/openapi.json", nil))
+
+	var document map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	return document
+}
+
+func TestOpenAPICoversRegistries(t *testing.T) {
+	document := openapiForTest(t, newTestApi(newFakeStore()))
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+
+	mapping := func(kind string) map[string]any {
+		return schemas[kind].(map[string]any)["discriminator"].(map[string]any)["mapping"].(map[string]any)
+	}
+
+	for key := range assetTypes {
+		if _, ok := mapping("Entity")[string(key)]; !ok {
+			t.Errorf("asset type %s is not documented", key)
+		}
+	}
+	for key := range relationTypes {
+		if _, ok := mapping("Edge")[string(key)]; !ok {
+			t.Errorf("relation type %s is not documented", key)
+		}
+	}
+	for key := range propertyTypes {
+		if _, ok := mapping("EntityTag")[string(key)]; !ok {
+			t.Errorf("property type %s is not documented for entity tags", key)
+		}
+		if _, ok := mapping("EdgeTag")[string(key)]; !ok {
+			t.Errorf("property type %s is not documented for edge tags", key)
+		}
+	}
+}
+
+func TestOpenAPIMatchesResponses(t *testing.T) {
+	g, err := New(WithRepository(memory.New()), WithAdminKeys("admin-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := openapiForTest(t, g.api)
+	paths := document["paths"].(map[string]any)
+	covered := make(map[string]bool)
+
+	// call makes a request to the documented route, as an admin, and
+	// checks the response against the document.
+	call := func(method, route, path, content_type, body string) any {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(AdminKeyHeader, "admin-key")
+		if content_type != "" {
+			req.Header.Set("Content-Type", content_type)
+		}
+		rec := httptest.NewRecorder()
+
+		g.ServeHTTP(rec, req)
+		covered[method+" "+route] = true
+
+		operation, ok := paths[route].(map[string]any)[strings.ToLower(method)].(map[string]any)
+		if !ok {
+			t.Fatalf("%s %s is not documented", method, route)
+		}
+		response, ok := operation["responses"].(map[string]any)[fmt.Sprint(rec.Code)].(map[string]any)
+		if !ok {
+			t.Fatalf("%s %s: status %d is not documented: %s", method, route, rec.Code, rec.Body.String())
+		}
+		if rec.Code != http.StatusOK && rec.Code != http.StatusAccepted {
+			t.Fatalf("%s %s: %d %s", method, route, rec.Code, rec.Body.String())
+		}
+
+		var value any
+		if err := json.Unmarshal(rec.Body.Bytes(), &value); err != nil {
+			t.Fatal(err)
+		}
+		schema := response["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+		if err := validate(document, schema, value, "response"); err != nil {
+			t.Errorf("%s %s: %v", method, route, err)
+		}
+		return value
+	}
+
+	id := func(value any) string {
+		return value.(map[string]any)["id"].(string)
+	}
+
+	fqdn := call("POST", "/emit/entity", "/emit/entity", "", fqdnBody("www.example.com"))
+	ip := call("POST", "/emit/entity", "/emit/entity", "", ipBody("192.0.2.1"))
+
+	call("GET", "/entity/{id}", "/entity/"+id(ip), "", "")
+	call("PUT", "/emit/entity/{id}", "/emit/entity/"+id(fqdn), "", fqdnBody("api.example.com"))
+	call("PATCH", "/emit/entity/{id}", "/emit/entity/"+id(fqdn), MergePatchType,
+		`{"asset":{"name":"mail.example.com"}}`)
+	call("PATCH", "/emit/entity/{id}", "/emit/entity/"+id(ip), JSONPatchType,
+		`[{"op":"replace","path":"/asset/address","value":"192.0.2.2"}]`)
+
+	edge := call("POST", "/emit/edge", "/emit/edge", "", dnsEdgeBody(id(fqdn), id(ip), 60))
+	call("GET", "/edge/{id}", "/edge/"+id(edge), "", "")
+	call("PUT", "/emit/edge/{id}", "/emit/edge/"+id(edge), "", dnsEdgeBody(id(fqdn), id(ip), 300))
+	call("PATCH", "/emit/edge/{id}", "/emit/edge/"+id(edge), MergePatchType, `{"relation":{"header":{"ttl":600}}}`)
+
+	entity_tag := call("POST", "/emit/entity_tag", "/emit/entity_tag", "", propertyBody("entity", id(fqdn), "dns"))
+	call("GET", "/entity_tag/{id}", "/entity_tag/"+id(entity_tag), "", "")
+	call("PUT", "/emit/entity_tag/{id}", "/emit/entity_tag/"+id(entity_tag), "", propertyBody("entity", id(fqdn), "whois"))
+	call("PATCH", "/emit/entity_tag/{id}", "/emit/entity_tag/"+id(entity_tag), MergePatchType, `{"property":{"property_value":"rdap"}}`)
+
+	edge_tag := call("POST", "/emit/edge_tag", "/emit/edge_tag", "", propertyBody("edge", id(edge), "dns"))
+	call("GET", "/edge_tag/{id}", "/edge_tag/"+id(edge_tag), "", "")
+	call("PUT", "/emit/edge_tag/{id}", "/emit/edge_tag/"+id(edge_tag), "", propertyBody("edge", id(edge), "whois"))
+	call("PATCH", "/emit/edge_tag/{id}", "/emit/edge_tag/"+id(edge_tag), JSONPatchType,
+		`[{"op":"replace","path":"/property/property_value","value":"rdap"}]`)
+
+	call("GET", "/entity/{id}/graph", "/entity/"+id(fqdn)+"/graph?depth=2&tags=true", "", "")
+	call("GET", "/entity/{id}/path/{to}", "/entity/"+id(fqdn)+"/path/"+id(ip), "", "")
+	call("GET", "/types", "/types", "", "")
+
+	job := call("POST", "/jobs", "/jobs", "", `{"kind":"prune","params":{"kind":"entity","older_than_days":30}}`)
+	call("GET", "/jobs", "/jobs", "", "")
+	deadline := time.Now().Add(5 * time.Second)
+	for call("GET", "/jobs/{id}", "/jobs/"+id(job), "", "").(map[string]any)["state"] == string(JobRunning) {
+		if time.Now().After(deadline) {
+			t.Fatal("job still running")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	call("GET", "/jobs/{id}/result", "/jobs/"+id(job)+"/result", "", "")
+	call("DELETE", "/jobs/{id}", "/jobs/"+id(job), "", "")
+
+	call("DELETE", "/emit/edge_tag/{id}", "/emit/edge_tag/"+id(edge_tag), "", "")
+	call("DELETE", "/emit/entity_tag/{id}", "/emit/entity_tag/"+id(entity_tag), "", "")
+	call("DELETE", "/emit/edge/{id}", "/emit/edge/"+id(edge), "", "")
+	preview := call("DELETE", "/emit/entity/{id}", "/emit/entity/"+id(ip)+"?cascade=preview", "", "")
+	if _, ok := preview.(map[string]any)["edges"]; !ok {
+		t.Errorf("preview is not a cascade: %v", preview)
+	}
+	call("DELETE", "/emit/entity/{id}", "/emit/entity/"+id(fqdn), "", "")
+
+	for route, item := range paths {
+		for method := range item.(map[string]any) {
+			if !covered[strings.ToUpper(method)+" "+route] {
+				t.Errorf("%s %s is not checked", strings.ToUpper(method), route)
+			}
+		}
+	}
+}
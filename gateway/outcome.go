@@ -0,0 +1,541 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	dbt "github.com/owasp-amass/asset-db/types"
+)
+
+// Outcome is what a single store operation did, as observed by the
+// request that performed it.
+type Outcome int
+
+const (
+	Created Outcome = iota
+	Updated
+	Unchanged
+	Deleted
+)
+
+// EntityEvent maps the outcome of an entity operation to the event
+// published for it. Unchanged entities only had LastSeen refreshed.
+func (o Outcome) EntityEvent() EventType {
+	switch o {
+	case Created:
+		return EntityCreated
+	case Unchanged:
+		return EntityTouched
+	case Deleted:
+		return EntityDeleted
+	default:
+		return EntityUpdated
+	}
+}
+
+// EdgeEvent maps the outcome of an edge operation to the event
+// published for it.
+func (o Outcome) EdgeEvent() EventType {
+	switch o {
+	case Created:
+		return EdgeCreated
+	case Unchanged:
+		return EdgeTouched
+	case Deleted:
+		return EdgeDeleted
+	default:
+		return EdgeUpdated
+	}
+}
+
+// EntityTagEvent maps the outcome of an entity tag operation to the
+// event published for it.
+func (o Outcome) EntityTagEvent() EventType {
+	switch o {
+	case Created:
+		return EntityTagCreated
+	case Unchanged:
+		return EntityTagTouched
+	case Deleted:
+		return EntityTagDeleted
+	default:
+		return EntityTagUpdated
+	}
+}
+
+// EdgeTagEvent maps the outcome of an edge tag operation to the event
+// published for it.
+func (o Outcome) EdgeTagEvent() EventType {
+	switch o {
+	case Created:
+		return EdgeTagCreated
+	case Unchanged:
+		return EdgeTagTouched
+	case Deleted:
+		return EdgeTagDeleted
+	default:
+		return EdgeTagUpdated
+	}
+}
+
+// eventData attaches the changes to the payload of update events.
+func eventData(data Serializable, changes []Change) Serializable {
+	if len(changes) == 0 {
+		return data
+	}
+	return Changed{Data: data, Changes: changes}
+}
+
+// keyedMutex serializes operations sharing the same key, so that the
+// lookup done before a write cannot be interleaved with another write
+// of the same object.
+type keyedMutex struct {
+	mutex sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	refs int
+}
+
+func (km *keyedMutex) Lock(key string) func() {
+	km.mutex.Lock()
+	if km.locks == nil {
+		km.locks = make(map[string]*keyedLock)
+	}
+	lock, ok := km.locks[key]
+	if !ok {
+		lock = &keyedLock{}
+		km.locks[key] = lock
+	}
+	lock.refs++
+	km.mutex.Unlock()
+
+	lock.Lock()
+
+	return func() {
+		lock.Unlock()
+
+		km.mutex.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(km.locks, key)
+		}
+		km.mutex.Unlock()
+	}
+}
+
+// lockEntities locks the entities with the given IDs, in order, for
+// writes connecting objects to them not to interleave with the deletion
+// of one of them. Entities are locked before the edges and tags.
+func (api *ApiV1) lockEntities(ids ...string) func() {
+	ids = slices.Compact(slices.Sorted(slices.Values(ids)))
+
+	unlocks := make([]func(), 0, len(ids))
+	for _, id := range ids {
+		unlocks = append(unlocks, api.locks.Lock("entity:"+id))
+	}
+	return func() {
+		for _, unlock := range slices.Backward(unlocks) {
+			unlock()
+		}
+	}
+}
+
+func sameContent(a, b any) bool {
+	a_json, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	b_json, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(a_json, b_json)
+}
+
+// upsertEntity writes input to the store and reports whether it created
+// a new entity, changed an existing one or only refreshed it, along
+// with the changes made to an existing entity. Without an ID, the store
+// deduplicates on the asset content. Writes over an existing ID fail
+// with ErrPreconditionFailed unless if_match matches its ETag.
+func (api *ApiV1) upsertEntity(input *dbt.Entity, if_match string) (*dbt.Entity, Outcome, []Change, error) {
+	var prev *dbt.Entity
+
+	// A write with an ID may turn the entity into the one a write without
+	// ID deduplicates on, so both hold the lock of the content first, and
+	// then the lock of the entity they write to.
+	content, _ := json.Marshal(input.Asset)
+	unlock := api.locks.Lock("entity:" + string(input.Asset.AssetType()) + ":" + string(content))
+	defer unlock()
+
+	if input.ID != "" {
+		unlock := api.locks.Lock("entity:" + input.ID)
+		defer unlock()
+
+		found, err := api.store.FindEntityById(api.ctx, input.ID)
+		if err != nil {
+			return nil, Updated, nil, err
+		}
+		prev = found
+
+		if !ifMatch(if_match, EntityFromStore(prev)) {
+			return nil, Updated, nil, ErrPreconditionFailed
+		}
+
+		// Clients replacing an entity rarely know when it was created.
+		if input.CreatedAt.IsZero() {
+			input.CreatedAt = prev.CreatedAt
+		}
+	} else {
+		// The store reports "not found" as an error.
+		found, err := api.store.FindEntitiesByContent(api.ctx, input.Asset, time.Time{})
+		if err == nil && len(found) > 0 {
+			unlock := api.locks.Lock("entity:" + found[0].ID)
+			defer unlock()
+
+			// The entity may have changed before the lock was held.
+			if found, err := api.store.FindEntityById(api.ctx, found[0].ID); err == nil {
+				prev = found
+			}
+		}
+	}
+
+	out, err := api.store.CreateEntity(api.ctx, input)
+	if err != nil {
+		return nil, Updated, nil, err
+	}
+	out = api.rereadEntity(out)
+
+	if prev == nil || prev.ID != out.ID {
+		return out, Created, nil, nil
+	}
+
+	changes := Diff(EntityFromStore(prev), EntityFromStore(out))
+	if len(changes) == 0 {
+		return out, Unchanged, nil, nil
+	}
+	return out, Updated, changes, nil
+}
+
+// The objects written are read back, so that what is answered and
+// published, and its ETag, match later reads from stores keeping less
+// precise timestamps than they were written with.
+
+func (api *ApiV1) rereadEntity(out *dbt.Entity) *dbt.Entity {
+	if stored, err := api.store.FindEntityById(api.ctx, out.ID); err == nil {
+		return stored
+	}
+	return out
+}
+
+func (api *ApiV1) rereadEdge(out *dbt.Edge) *dbt.Edge {
+	if stored, err := api.store.FindEdgeById(api.ctx, out.ID); err == nil {
+		return stored
+	}
+	return out
+}
+
+func (api *ApiV1) rereadEntityTag(out *dbt.EntityTag) *dbt.EntityTag {
+	if stored, err := api.store.FindEntityTagById(api.ctx, out.ID); err == nil {
+		return stored
+	}
+	return out
+}
+
+func (api *ApiV1) rereadEdgeTag(out *dbt.EdgeTag) *dbt.EdgeTag {
+	if stored, err := api.store.FindEdgeTagById(api.ctx, out.ID); err == nil {
+		return stored
+	}
+	return out
+}
+
+// removeEntity deletes the entity with the given ID and returns it as it
+// was before deletion, unless if_match does not match its ETag.
+func (api *ApiV1) removeEntity(id string, if_match string) (*dbt.Entity, Outcome, error) {
+	unlock := api.locks.Lock("entity:" + id)
+	defer unlock()
+
+	return api.removeLockedEntity(id, if_match)
+}
+
+// removeLockedEntity is removeEntity for callers holding the lock of the
+// entity.
+func (api *ApiV1) removeLockedEntity(id string, if_match string) (*dbt.Entity, Outcome, error) {
+	prev, err := api.store.FindEntityById(api.ctx, id)
+	if err != nil {
+		return nil, Deleted, fmt.Errorf("cannot find entity: %w", err)
+	}
+
+	if !ifMatch(if_match, EntityFromStore(prev)) {
+		return nil, Deleted, ErrPreconditionFailed
+	}
+
+	if err := api.store.DeleteEntity(api.ctx, id); err != nil {
+		return nil, Deleted, fmt.Errorf("failed to delete entity: %w", err)
+	}
+
+	return prev, Deleted, nil
+}
+
+// upsertEdge writes input to the store like upsertEntity. Without an
+// ID, the store deduplicates on the endpoints and relation content.
+func (api *ApiV1) upsertEdge(input *dbt.Edge, if_match string) (*dbt.Edge, Outcome, []Change, error) {
+	var prev *dbt.Edge
+
+	unlock_entities := api.lockEntities(input.FromEntity.ID, input.ToEntity.ID)
+	defer unlock_entities()
+
+	// Locked in the same order as in upsertEntity.
+	label := input.Relation.Label()
+	unlock := api.locks.Lock("edge:" + input.FromEntity.ID + ":" + label + ":" + input.ToEntity.ID)
+	defer unlock()
+
+	if input.ID != "" {
+		unlock := api.locks.Lock("edge:" + input.ID)
+		defer unlock()
+
+		found, err := api.store.FindEdgeById(api.ctx, input.ID)
+		if err != nil {
+			return nil, Updated, nil, err
+		}
+		prev = found
+
+		if !ifMatch(if_match, EdgeFromStore(prev)) {
+			return nil, Updated, nil, ErrPreconditionFailed
+		}
+
+		if input.CreatedAt.IsZero() {
+			input.CreatedAt = prev.CreatedAt
+		}
+	} else {
+		// The store reports "not found" as an error.
+		found, _ := api.store.OutgoingEdges(api.ctx, input.FromEntity, time.Time{}, label)
+		for _, edge := range found {
+			if edge.ToEntity.ID == input.ToEntity.ID && sameContent(edge.Relation, input.Relation) {
+				unlock := api.locks.Lock("edge:" + edge.ID)
+				defer unlock()
+
+				// The edge may have changed before the lock was held.
+				if found, err := api.store.FindEdgeById(api.ctx, edge.ID); err == nil {
+					prev = found
+				}
+				break
+			}
+		}
+	}
+
+	out, err := api.store.CreateEdge(api.ctx, input)
+	if err != nil {
+		return nil, Updated, nil, err
+	}
+	out = api.rereadEdge(out)
+
+	if prev == nil || prev.ID != out.ID {
+		return out, Created, nil, nil
+	}
+
+	changes := Diff(EdgeFromStore(prev), EdgeFromStore(out))
+	if len(changes) == 0 {
+		return out, Unchanged, nil, nil
+	}
+	return out, Updated, changes, nil
+}
+
+// removeEdge deletes the edge with the given ID and returns it as it was
+// before deletion, unless if_match does not match its ETag.
+func (api *ApiV1) removeEdge(id string, if_match string) (*dbt.Edge, Outcome, error) {
+	unlock := api.locks.Lock("edge:" + id)
+	defer unlock()
+
+	prev, err := api.store.FindEdgeById(api.ctx, id)
+	if err != nil {
+		return nil, Deleted, fmt.Errorf("cannot find edge: %w", err)
+	}
+
+	if !ifMatch(if_match, EdgeFromStore(prev)) {
+		return nil, Deleted, ErrPreconditionFailed
+	}
+
+	if err := api.store.DeleteEdge(api.ctx, id); err != nil {
+		return nil, Deleted, fmt.Errorf("failed to delete edge: %w", err)
+	}
+
+	return prev, Deleted, nil
+}
+
+// upsertEntityTag writes input to the store like upsertEntity. Without
+// an ID, the store deduplicates on the property within the entity.
+func (api *ApiV1) upsertEntityTag(input *dbt.EntityTag, if_match string) (*dbt.EntityTag, Outcome, []Change, error) {
+	var prev *dbt.EntityTag
+
+	unlock_entity := api.lockEntities(input.Entity.ID)
+	defer unlock_entity()
+
+	// Locked in the same order as in upsertEntity.
+	content, _ := json.Marshal(input.Property)
+	unlock := api.locks.Lock("entity_tag:" + input.Entity.ID + ":" + string(input.Property.PropertyType()) + ":" + string(content))
+	defer unlock()
+
+	if input.ID != "" {
+		unlock := api.locks.Lock("entity_tag:" + input.ID)
+		defer unlock()
+
+		found, err := api.store.FindEntityTagById(api.ctx, input.ID)
+		if err != nil {
+			return nil, Updated, nil, err
+		}
+		prev = found
+
+		if !ifMatch(if_match, EntityTagFromStore(prev)) {
+			return nil, Updated, nil, ErrPreconditionFailed
+		}
+
+		if input.CreatedAt.IsZero() {
+			input.CreatedAt = prev.CreatedAt
+		}
+	} else {
+		// The store reports "not found" as an error.
+		found, _ := api.store.GetEntityTags(api.ctx, input.Entity, time.Time{}, input.Property.Name())
+		for _, tag := range found {
+			if sameContent(tag.Property, input.Property) {
+				unlock := api.locks.Lock("entity_tag:" + tag.ID)
+				defer unlock()
+
+				// The tag may have changed before the lock was held.
+				if found, err := api.store.FindEntityTagById(api.ctx, tag.ID); err == nil {
+					prev = found
+				}
+				break
+			}
+		}
+	}
+
+	out, err := api.store.CreateEntityTag(api.ctx, input.Entity, input)
+	if err != nil {
+		return nil, Updated, nil, err
+	}
+	out = api.rereadEntityTag(out)
+
+	if prev == nil || prev.ID != out.ID {
+		return out, Created, nil, nil
+	}
+
+	changes := Diff(EntityTagFromStore(prev), EntityTagFromStore(out))
+	if len(changes) == 0 {
+		return out, Unchanged, nil, nil
+	}
+	return out, Updated, changes, nil
+}
+
+// removeEntityTag deletes the entity tag with the given ID and returns
+// it as it was before deletion, unless if_match does not match its ETag.
+func (api *ApiV1) removeEntityTag(id string, if_match string) (*dbt.EntityTag, error) {
+	unlock := api.locks.Lock("entity_tag:" + id)
+	defer unlock()
+
+	prev, err := api.store.FindEntityTagById(api.ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find entity tag: %w", err)
+	}
+
+	if !ifMatch(if_match, EntityTagFromStore(prev)) {
+		return nil, ErrPreconditionFailed
+	}
+
+	if err := api.store.DeleteEntityTag(api.ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to delete entity tag: %w", err)
+	}
+
+	return prev, nil
+}
+
+// upsertEdgeTag writes input to the store like upsertEntityTag.
+func (api *ApiV1) upsertEdgeTag(input *dbt.EdgeTag, if_match string) (*dbt.EdgeTag, Outcome, []Change, error) {
+	var prev *dbt.EdgeTag
+
+	if input.Edge.FromEntity != nil && input.Edge.ToEntity != nil {
+		unlock_entities := api.lockEntities(input.Edge.FromEntity.ID, input.Edge.ToEntity.ID)
+		defer unlock_entities()
+	}
+
+	content, _ := json.Marshal(input.Property)
+	unlock := api.locks.Lock("edge_tag:" + input.Edge.ID + ":" + string(input.Property.PropertyType()) + ":" + string(content))
+	defer unlock()
+
+	if input.ID != "" {
+		unlock := api.locks.Lock("edge_tag:" + input.ID)
+		defer unlock()
+
+		found, err := api.store.FindEdgeTagById(api.ctx, input.ID)
+		if err != nil {
+			return nil, Updated, nil, err
+		}
+		prev = found
+
+		if !ifMatch(if_match, EdgeTagFromStore(prev)) {
+			return nil, Updated, nil, ErrPreconditionFailed
+		}
+
+		if input.CreatedAt.IsZero() {
+			input.CreatedAt = prev.CreatedAt
+		}
+	} else {
+		// The store reports "not found" as an error.
+		found, _ := api.store.GetEdgeTags(api.ctx, input.Edge, time.Time{}, input.Property.Name())
+		for _, tag := range found {
+			if sameContent(tag.Property, input.Property) {
+				unlock := api.locks.Lock("edge_tag:" + tag.ID)
+				defer unlock()
+
+				if found, err := api.store.FindEdgeTagById(api.ctx, tag.ID); err == nil {
+					prev = found
+				}
+				break
+			}
+		}
+	}
+
+	out, err := api.store.CreateEdgeTag(api.ctx, input.Edge, input)
+	if err != nil {
+		return nil, Updated, nil, err
+	}
+	out = api.rereadEdgeTag(out)
+
+	if prev == nil || prev.ID != out.ID {
+		return out, Created, nil, nil
+	}
+
+	changes := Diff(EdgeTagFromStore(prev), EdgeTagFromStore(out))
+	if len(changes) == 0 {
+		return out, Unchanged, nil, nil
+	}
+	return out, Updated, changes, nil
+}
+
+// removeEdgeTag deletes the edge tag with the given ID like
+// removeEntityTag.
+func (api *ApiV1) removeEdgeTag(id string, if_match string) (*dbt.EdgeTag, error) {
+	unlock := api.locks.Lock("edge_tag:" + id)
+	defer unlock()
+
+	prev, err := api.store.FindEdgeTagById(api.ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find edge tag: %w", err)
+	}
+
+	if !ifMatch(if_match, EdgeTagFromStore(prev)) {
+		return nil, ErrPreconditionFailed
+	}
+
+	if err := api.store.DeleteEdgeTag(api.ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to delete edge tag: %w", err)
+	}
+
+	return prev, nil
+}
@@ -0,0 +1,314 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// Patch is a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902),
+// as told by its media type.
+type Patch struct {
+	Type string
+	Body []byte
+}
+
+// requestPatch reads the patch in the request body. It is a JSON Merge
+// Patch unless it is sent as a JSON Patch.
+func requestPatch(r *http.Request) (Patch, error) {
+	if r.Body == nil {
+		return Patch{}, errors.New("no body")
+	}
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return Patch{}, err
+	}
+
+	media_type := MergePatchType
+	if content_type := r.Header.Get("Content-Type"); content_type != "" {
+		media_type, _, err = mime.ParseMediaType(content_type)
+		if err != nil {
+			return Patch{}, err
+		}
+	}
+	return Patch{Type: media_type, Body: body}, nil
+}
+
+// valuePatch returns the patch of a decoded JSON value: a JSON Patch
+// when it is a list of operations, a JSON Merge Patch otherwise.
+func valuePatch(value any) (Patch, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return Patch{}, err
+	}
+	if _, ok := value.([]any); ok {
+		return Patch{Type: JSONPatchType, Body: body}, nil
+	}
+	return Patch{Type: MergePatchType, Body: body}, nil
+}
+
+// readPatch applies the patch in the request body to the serialized
+// object doc.
+func readPatch(r *http.Request, doc []byte) ([]byte, error) {
+	patch, err := requestPatch(r)
+	if err != nil {
+		return nil, err
+	}
+	return patch.Apply(doc)
+}
+
+// Apply applies the patch to the serialized object doc.
+func (p Patch) Apply(doc []byte) ([]byte, error) {
+	var target any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+
+	switch p.Type {
+	case MergePatchType, "application/json":
+		var patch any
+		if err := json.Unmarshal(p.Body, &patch); err != nil {
+			return nil, err
+		}
+		target = mergePatch(target, patch)
+	case JSONPatchType:
+		var ops []PatchOperation
+		if err := json.Unmarshal(p.Body, &ops); err != nil {
+			return nil, err
+		}
+		for i, op := range ops {
+			var err error
+			if target, err = op.Apply(target); err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported patch type: %s", p.Type)
+	}
+
+	return json.Marshal(target)
+}
+
+func mergePatch(target, patch any) any {
+	patch_obj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	target_obj, ok := target.(map[string]any)
+	if !ok {
+		target_obj = make(map[string]any)
+	}
+
+	for k, v := range patch_obj {
+		if v == nil {
+			delete(target_obj, k)
+		} else {
+			target_obj[k] = mergePatch(target_obj[k], v)
+		}
+	}
+	return target_obj
+}
+
+// PatchOperation is a single JSON Patch operation.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (op PatchOperation) Apply(doc any) (any, error) {
+	var value any
+	if op.Value != nil {
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, err
+		}
+	}
+
+	switch op.Op {
+	case "add":
+		return pointerSet(doc, op.Path, value, true)
+	case "remove":
+		doc, _, err := pointerRemove(doc, op.Path)
+		return doc, err
+	case "replace":
+		if _, err := pointerGet(doc, op.Path); err != nil {
+			return nil, err
+		}
+		return pointerSet(doc, op.Path, value, false)
+	case "move":
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, errors.New("cannot move a value into itself")
+		}
+		doc, moved, err := pointerRemove(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return pointerSet(doc, op.Path, moved, true)
+	case "copy":
+		copied, err := pointerGet(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return pointerSet(doc, op.Path, deepCopy(copied), true)
+	case "test":
+		current, err := pointerGet(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, fmt.Errorf("test failed at %s", op.Path)
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unsupported operation: %s", op.Op)
+}
+
+func splitPointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if path[0] != '/' {
+		return nil, fmt.Errorf("invalid pointer: %s", path)
+	}
+
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func arrayIndex(token string, length int, appending bool) (int, error) {
+	if appending && token == "-" {
+		return length, nil
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > length || (i == length && !appending) {
+		return 0, fmt.Errorf("invalid array index: %s", token)
+	}
+	return i, nil
+}
+
+func pointerGet(doc any, path string) (any, error) {
+	tokens, err := splitPointer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, token := range tokens {
+		switch node := doc.(type) {
+		case map[string]any:
+			child, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("no value at %s", path)
+			}
+			doc = child
+		case []any:
+			i, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("no value at %s", path)
+		}
+	}
+	return doc, nil
+}
+
+// pointerSet sets the value at path. When inserting, values are added
+// to arrays rather than replacing an element.
+func pointerSet(doc any, path string, value any, inserting bool) (any, error) {
+	tokens, err := splitPointer(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	parent_path := path[:strings.LastIndex(path, "/")]
+	parent, err := pointerGet(doc, parent_path)
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+
+	switch node := parent.(type) {
+	case map[string]any:
+		node[last] = value
+		return doc, nil
+	case []any:
+		i, err := arrayIndex(last, len(node), inserting)
+		if err != nil {
+			return nil, err
+		}
+		if inserting {
+			node = append(node[:i], append([]any{value}, node[i:]...)...)
+		} else {
+			node[i] = value
+		}
+		return pointerSet(doc, parent_path, node, false)
+	}
+	return nil, fmt.Errorf("cannot set a value at %s", path)
+}
+
+func pointerRemove(doc any, path string) (any, any, error) {
+	tokens, err := splitPointer(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil, errors.New("cannot remove the whole document")
+	}
+
+	parent_path := path[:strings.LastIndex(path, "/")]
+	parent, err := pointerGet(doc, parent_path)
+	if err != nil {
+		return nil, nil, err
+	}
+	last := tokens[len(tokens)-1]
+
+	switch node := parent.(type) {
+	case map[string]any:
+		removed, ok := node[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("no value at %s", path)
+		}
+		delete(node, last)
+		return doc, removed, nil
+	case []any:
+		i, err := arrayIndex(last, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		removed := node[i]
+		node = append(node[:i:i], node[i+1:]...)
+		doc, err = pointerSet(doc, parent_path, node, false)
+		return doc, removed, err
+	}
+	return nil, nil, fmt.Errorf("no value at %s", path)
+}
+
+func deepCopy(v any) any {
+	encoded, _ := json.Marshal(v)
+	var copied any
+	json.Unmarshal(encoded, &copied)
+	return copied
+}
@@ -0,0 +1,263 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0ppliger/oam-broker/memory"
+	"github.com/owasp-amass/asset-db/repository"
+	dbt "github.com/owasp-amass/asset-db/types"
+)
+
+func TestJSONPatch(t *testing.T) {
+	cases := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string // empty when the patch fails
+	}{
+		{"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add null member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":null}]`, `{"baz":null,"foo":"bar"}`},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"add past the end", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"qux"}]`, `{"foo":["bar","qux"]}`},
+		{"add to empty array", `{"foo":[]}`, `[{"op":"add","path":"/foo/-","value":1}]`, `{"foo":[1]}`},
+		{"add out of bounds", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":"qux"}]`, ``},
+		{"add to missing parent", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, ``},
+		{"remove member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"remove past the end", `{"foo":["bar"]}`, `[{"op":"remove","path":"/foo/-"}]`, ``},
+		{"remove missing member", `{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, ``},
+		{"replace member", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"replace missing member", `{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, ``},
+		{"move member", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"move into itself", `{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar"}]`, ``},
+		{"copy member", `{"foo":{"bar":1}}`, `[{"op":"copy","from":"/foo","path":"/baz"},{"op":"replace","path":"/baz/bar","value":2}]`, `{"baz":{"bar":2},"foo":{"bar":1}}`},
+		{"copy missing member", `{"foo":1}`, `[{"op":"copy","from":"/bar","path":"/baz"}]`, ``},
+		{"test value", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"test failure", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, ``},
+		{"test null", `{"foo":null}`, `[{"op":"test","path":"/foo","value":null}]`, `{"foo":null}`},
+		{"escaped tokens", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10},{"op":"replace","path":"/~1","value":8}]`, `{"/":8,"~1":10}`},
+		{"unknown operation", `{}`, `[{"op":"merge","path":"/a","value":1}]`, ``},
+		{"invalid pointer", `{}`, `[{"op":"add","path":"a","value":1}]`, ``},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", "/", strings.NewReader(c.patch))
+			req.Header.Set("Content-Type", JSONPatchType)
+			got, err := readPatch(req, []byte(c.doc))
+			if c.want == "" {
+				if err == nil {
+					t.Errorf("patch applied: %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameJSON(got, json.RawMessage(c.want)) {
+				t.Errorf("got %s, expected %s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// From RFC 7396, Appendix A.
+	cases := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, c := range cases {
+		for _, content_type := range []string{"", MergePatchType, "application/json"} {
+			req := httptest.NewRequest("PATCH", "/", strings.NewReader(c.patch))
+			if content_type != "" {
+				req.Header.Set("Content-Type", content_type)
+			}
+			got, err := readPatch(req, []byte(c.doc))
+			if err != nil {
+				t.Fatalf("%s with %s: %v", c.doc, c.patch, err)
+			}
+			if !sameJSON(got, json.RawMessage(c.want)) {
+				t.Errorf("%s with %s as %q: got %s, expected %s", c.doc, c.patch, content_type, got, c.want)
+			}
+		}
+	}
+}
+
+func TestPatchType(t *testing.T) {
+	req := httptest.NewRequest("PATCH", "/", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "text/plain")
+	if _, err := readPatch(req, []byte(`{}`)); err == nil {
+		t.Error("patch of an unsupported type applied")
+	}
+}
+
+// lastSeenStore records the LastSeen of the objects written, which the
+// SQL stores keep when it is set.
+type lastSeenStore struct {
+	repository.Repository
+
+	mutex     sync.Mutex
+	last_seen map[string]time.Time
+}
+
+func (ls *lastSeenStore) record(id string, last_seen time.Time) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	ls.last_seen[id] = last_seen
+}
+
+func (ls *lastSeenStore) CreateEntity(ctx context.Context, input *dbt.Entity) (*dbt.Entity, error) {
+	ls.record(input.ID, input.LastSeen)
+	return ls.Repository.CreateEntity(ctx, input)
+}
+
+func (ls *lastSeenStore) CreateEdge(ctx context.Context, input *dbt.Edge) (*dbt.Edge, error) {
+	ls.record(input.ID, input.LastSeen)
+	return ls.Repository.CreateEdge(ctx, input)
+}
+
+func (ls *lastSeenStore) CreateEntityTag(ctx context.Context, entity *dbt.Entity, input *dbt.EntityTag) (*dbt.EntityTag, error) {
+	ls.record(input.ID, input.LastSeen)
+	return ls.Repository.CreateEntityTag(ctx, entity, input)
+}
+
+func (ls *lastSeenStore) CreateEdgeTag(ctx context.Context, edge *dbt.Edge, input *dbt.EdgeTag) (*dbt.EdgeTag, error) {
+	ls.record(input.ID, input.LastSeen)
+	return ls.Repository.CreateEdgeTag(ctx, edge, input)
+}
+
+// TestPatchRefreshesLastSeen patches every kind of object: like other
+// writes, a patch leaves it to the store to refresh LastSeen.
+func TestPatchRefreshesLastSeen(t *testing.T) {
+	store := &lastSeenStore{Repository: memory.New(), last_seen: make(map[string]time.Time)}
+	g, err := New(WithRepository(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(g)
+	defer server.Close()
+	tg := &testGateway{t: t, url: server.URL}
+
+	fqdn := tg.entity(fqdnBody("www.example.com"))
+	ip := tg.entity(ipBody("192.0.2.1"))
+	edge := tg.edge(dnsEdgeBody(fqdn.ID, ip.ID, 60))
+	var entity_tag EntityTag
+	tg.must("POST", "/emit/entity_tag", propertyBody("entity", fqdn.ID, "dns"), &entity_tag)
+	var edge_tag EdgeTag
+	tg.must("POST", "/emit/edge_tag", propertyBody("edge", edge.ID, "dns"), &edge_tag)
+
+	cases := []struct {
+		path  string
+		id    string
+		patch string
+	}{
+		{"/emit/entity/", fqdn.ID, `{"asset":{"name":"app.example.com"}}`},
+		{"/emit/edge/", edge.ID, `{"relation":{"header":{"ttl":300}}}`},
+		{"/emit/entity_tag/", entity_tag.ID, `{"property":{"property_value":"whois"}}`},
+		{"/emit/edge_tag/", edge_tag.ID, `{"property":{"property_value":"whois"}}`},
+	}
+	for _, c := range cases {
+		var out json.RawMessage
+		tg.must("PATCH", c.path+c.id, c.patch, &out)
+
+		store.mutex.Lock()
+		last_seen := store.last_seen[c.id]
+		store.mutex.Unlock()
+		if !last_seen.IsZero() {
+			t.Errorf("PATCH %s%s wrote last seen %s", c.path, c.id, last_seen)
+		}
+	}
+}
+
+// interleavedStore runs between once, right after an entity is read, as
+// a write landing while a patch is applied to the entity.
+type interleavedStore struct {
+	repository.Repository
+	between atomic.Pointer[func()]
+}
+
+func (is *interleavedStore) FindEntityById(ctx context.Context, id string) (*dbt.Entity, error) {
+	entity, err := is.Repository.FindEntityById(ctx, id)
+	if between := is.between.Swap(nil); between != nil {
+		(*between)()
+	}
+	return entity, err
+}
+
+func TestConcurrentPatches(t *testing.T) {
+	store := &interleavedStore{Repository: memory.New()}
+	g, err := New(WithRepository(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(g)
+	defer server.Close()
+	tg := &testGateway{t: t, url: server.URL}
+
+	org := tg.entity(`{"type":"Organization","asset":{"unique_id":"acme","name":"Acme"}}`)
+
+	// Another patch is written between the read and the write of the
+	// first one, which neither overwrites.
+	between := func() {
+		var out Entity
+		tg.must("PATCH", "/emit/entity/"+org.ID, `{"asset":{"jurisdiction":"US-DE"}}`, &out)
+	}
+	store.between.Store(&between)
+
+	var patched Entity
+	tg.must("PATCH", "/emit/entity/"+org.ID, `{"asset":{"industry":"Software"}}`, &patched)
+	if store.between.Load() != nil {
+		t.Fatal("no patch in between")
+	}
+
+	var stored Entity
+	tg.must("GET", "/entity/"+org.ID, "", &stored)
+	for _, field := range []string{`"industry":"Software"`, `"jurisdiction":"US-DE"`} {
+		if !strings.Contains(string(stored.JSON()), field) {
+			t.Errorf("%s lost in %s", field, stored.JSON())
+		}
+	}
+
+	// With If-Match, the stale patch is refused instead.
+	between = func() {
+		var out Entity
+		tg.must("PATCH", "/emit/entity/"+org.ID, `{"asset":{"headcount":10}}`, &out)
+	}
+	store.between.Store(&between)
+	req, err := http.NewRequest("PATCH", server.URL+"/emit/entity/"+org.ID, strings.NewReader(`{"asset":{"industry":"Retail"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("If-Match", ETag(stored))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	expectStatus(t, resp, http.StatusPreconditionFailed)
+}
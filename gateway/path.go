@@ -0,0 +1,211 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	dbt "github.com/owasp-amass/asset-db/types"
+)
+
+const (
+	// defaultPathDepth is the number of hops searched for a path when
+	// no max_depth is given.
+	defaultPathDepth = 6
+	// maxPathDepth is the highest max_depth accepted.
+	maxPathDepth = 10
+	// defaultPathLimit and maxPathLimit bound the number of shortest
+	// paths returned.
+	defaultPathLimit = 10
+	maxPathLimit     = 100
+)
+
+// pathHop is the edge through which an entity was reached from the
+// entity with ID parent.
+type pathHop struct {
+	edge   *dbt.Edge
+	parent string
+}
+
+// shortestPaths searches the graph breadth first from one entity to
+// another, within max_depth hops and following only edges labelled with
+// one of relations when any is given. It returns up to limit of the
+// shortest paths, or none when the entities are not connected within
+// max_depth hops.
+func (api *ApiV1) shortestPaths(ctx context.Context, from, to string, relations []string, max_depth, limit int) ([]Path, error) {
+	depths := map[string]int{from: 0}
+	parents := make(map[string][]pathHop)
+	frontier := []string{from}
+
+	for depth := 0; depth < max_depth && len(frontier) > 0; depth++ {
+		if _, found := depths[to]; found {
+			break
+		}
+
+		var next []string
+		for _, id := range frontier {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			// Querying edges only needs the entity ID.
+			entity := &dbt.Entity{ID: id}
+			in, _ := api.store.IncomingEdges(ctx, entity, time.Time{}, relations...)
+			out, _ := api.store.OutgoingEdges(ctx, entity, time.Time{}, relations...)
+			for _, edge := range append(in, out...) {
+				neighbour := edge.ToEntity.ID
+				if neighbour == id {
+					neighbour = edge.FromEntity.ID
+				}
+				if neighbour == id {
+					continue
+				}
+
+				// Entities reached again at the same depth get another
+				// parent, so that every shortest path is kept. Cycles
+				// lead back to shallower entities and are ignored.
+				if d, seen := depths[neighbour]; seen {
+					if d == depth+1 {
+						parents[neighbour] = append(parents[neighbour], pathHop{edge, id})
+					}
+					continue
+				}
+
+				if len(depths) == maxGraphLimit {
+					return nil, fmt.Errorf("search exceeded %d entities", maxGraphLimit)
+				}
+				depths[neighbour] = depth + 1
+				parents[neighbour] = []pathHop{{edge, id}}
+				next = append(next, neighbour)
+			}
+		}
+		frontier = next
+	}
+
+	if _, found := depths[to]; !found {
+		return nil, nil
+	}
+
+	// Walk the parents back from the destination.
+	var hops [][]pathHop
+	var backtrack func(id string, suffix []pathHop)
+	backtrack = func(id string, suffix []pathHop) {
+		if len(hops) == limit {
+			return
+		}
+		if id == from {
+			hops = append(hops, suffix)
+			return
+		}
+		for _, hop := range parents[id] {
+			backtrack(hop.parent, append([]pathHop{hop}, suffix...))
+		}
+	}
+	backtrack(to, nil)
+
+	entities := make(map[string]Entity)
+	entity := func(id string) (Entity, error) {
+		if e, ok := entities[id]; ok {
+			return e, nil
+		}
+		found, err := api.store.FindEntityById(ctx, id)
+		if err != nil {
+			return Entity{}, fmt.Errorf("cannot find entity: %w", err)
+		}
+		entities[id] = EntityFromStore(found)
+		return entities[id], nil
+	}
+
+	paths := make([]Path, 0, len(hops))
+	for _, path_hops := range hops {
+		path := Path{Entities: []Entity{}, Edges: []Edge{}}
+
+		first, err := entity(from)
+		if err != nil {
+			return nil, err
+		}
+		path.Entities = append(path.Entities, first)
+
+		for i, hop := range path_hops {
+			next := to
+			if i+1 < len(path_hops) {
+				next = path_hops[i+1].parent
+			}
+
+			e, err := entity(next)
+			if err != nil {
+				return nil, err
+			}
+			path.Entities = append(path.Entities, e)
+			path.Edges = append(path.Edges, EdgeFromStore(hop.edge))
+		}
+
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// boundedInt reads an integer query parameter between 1 and max, or
+// returns fallback when it is absent.
+func boundedInt(r *http.Request, name string, fallback, max int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > max {
+		return 0, fmt.Errorf("invalid %s: %s, must be between 1 and %d", name, value, max)
+	}
+	return n, nil
+}
+
+// GetEntityPath returns the shortest paths from an entity to another.
+func (api *ApiV1) GetEntityPath(w http.ResponseWriter, r *http.Request) {
+	from, to := r.PathValue("id"), r.PathValue("to")
+
+	max_depth, err := boundedInt(r, "max_depth", defaultPathDepth, maxPathDepth)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit, err := boundedInt(r, "limit", defaultPathLimit, maxPathLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var relations []string
+	for _, value := range r.URL.Query()["relations"] {
+		relations = append(relations, strings.Split(value, ",")...)
+	}
+
+	for _, id := range []string{from, to} {
+		if _, err := api.store.FindEntityById(r.Context(), id); err != nil {
+			http.Error(w, "Cannot find entity "+id+": "+err.Error(), http.StatusNotFound)
+			return
+		}
+	}
+
+	paths, err := api.shortestPaths(r.Context(), from, to, relations, max_depth, limit)
+	if err != nil {
+		http.Error(w, "Failed to search paths: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if len(paths) == 0 {
+		http.Error(w, fmt.Sprintf("No path within %d hops", max_depth), http.StatusNotFound)
+		return
+	}
+
+	w.Write(PathResult{
+		From:   from,
+		To:     to,
+		Length: len(paths[0].Edges),
+		Paths:  paths,
+	}.JSON())
+}
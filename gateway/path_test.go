@@ -0,0 +1,94 @@
+package gateway
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEntityPath(t *testing.T) {
+	tg := newTestGateway(t)
+
+	// s reaches t through x over dns_record and through y over node, and
+	// through a longer route over p.
+	ids := make(map[string]string)
+	names := make(map[string]string)
+	for _, name := range []string{"s", "x", "y", "t", "p", "q", "lone"} {
+		entity := tg.entity(fqdnBody(name + ".example.com"))
+		ids[name], names[entity.ID] = entity.ID, name
+	}
+	tg.edge(dnsEdgeBody(ids["s"], ids["x"], 60))
+	tg.edge(dnsEdgeBody(ids["x"], ids["t"], 60))
+	tg.edge(simpleEdgeBody("node", ids["s"], ids["y"]))
+	tg.edge(simpleEdgeBody("node", ids["y"], ids["t"]))
+	tg.edge(simpleEdgeBody("node", ids["s"], ids["p"]))
+	tg.edge(simpleEdgeBody("node", ids["p"], ids["q"]))
+	tg.edge(simpleEdgeBody("node", ids["q"], ids["t"]))
+
+	cases := []struct {
+		name   string
+		from   string
+		to     string
+		query  string
+		length int
+		paths  []string
+	}{
+		{"multiple parents", "s", "t", "", 2, []string{"s x t", "s y t"}},
+		{"against the edges", "t", "s", "", 2, []string{"t x s", "t y s"}},
+		{"limit", "s", "t", "limit=1", 2, nil},
+		{"relations", "s", "t", "relations=dns_record", 2, []string{"s x t"}},
+		{"other relations", "s", "t", "relations=node", 2, []string{"s y t"}},
+		{"longer route", "s", "q", "", 2, []string{"s p q"}},
+		{"same entity", "s", "s", "", 0, []string{"s"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var result PathResult
+			tg.must("GET", "/entity/"+ids[c.from]+"/path/"+ids[c.to]+"?"+c.query, "", &result)
+
+			if result.From != ids[c.from] || result.To != ids[c.to] || result.Length != c.length {
+				t.Errorf("from %s to %s of length %d", names[result.From], names[result.To], result.Length)
+			}
+
+			var got []string
+			for _, path := range result.Paths {
+				if len(path.Edges) != c.length || len(path.Entities) != c.length+1 {
+					t.Errorf("path of %d entities and %d edges", len(path.Entities), len(path.Edges))
+					continue
+				}
+
+				var route []string
+				for i, entity := range path.Entities {
+					route = append(route, names[entity.ID])
+					if i == len(path.Edges) {
+						continue
+					}
+					edge := path.Edges[i]
+					next := path.Entities[i+1].ID
+					if !(edge.FromEntity == entity.ID && edge.ToEntity == next) && !(edge.ToEntity == entity.ID && edge.FromEntity == next) {
+						t.Errorf("edge %d does not join %s and %s", i, names[entity.ID], names[next])
+					}
+				}
+				got = append(got, strings.Join(route, " "))
+			}
+			slices.Sort(got)
+
+			if c.paths == nil {
+				if len(got) != 1 {
+					t.Errorf("paths %v, want one", got)
+				}
+			} else if !slices.Equal(got, c.paths) {
+				t.Errorf("paths %v, want %v", got, c.paths)
+			}
+		})
+	}
+
+	tg.status("GET", "/entity/"+ids["s"]+"/path/"+ids["t"]+"?max_depth=1", "", http.StatusNotFound)
+	tg.status("GET", "/entity/"+ids["s"]+"/path/"+ids["lone"], "", http.StatusNotFound)
+	tg.status("GET", "/entity/"+ids["x"]+"/path/"+ids["y"]+"?relations=node", "", http.StatusNotFound)
+	tg.status("GET", "/entity/"+ids["s"]+"/path/unknown", "", http.StatusNotFound)
+	for _, query := range []string{"max_depth=0", "max_depth=11", "limit=0", "limit=101", "limit=many"} {
+		tg.status("GET", "/entity/"+ids["s"]+"/path/"+ids["t"]+"?"+query, "", http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,254 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/0ppliger/oam-broker/wire"
+	dbt "github.com/owasp-amass/asset-db/types"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+// PruneFilter and PruneResult are their wire counterparts, extended
+// with the logic of prune jobs.
+type PruneFilter wire.PruneFilter
+
+type PruneResult wire.PruneResult
+
+// maxPruneErrors caps the number of errors kept in a PruneResult.
+const maxPruneErrors = 100
+
+func (pr *PruneResult) fail(err error) {
+	if len(pr.Errors) < maxPruneErrors {
+		pr.Errors = append(pr.Errors, err.Error())
+	}
+}
+
+func (f PruneFilter) validate() error {
+	switch f.Kind {
+	case "entity", "edge", "entity_tag", "edge_tag":
+	default:
+		return fmt.Errorf("unsupported kind: %s", f.Kind)
+	}
+
+	for _, atype := range f.AssetTypes {
+		if _, ok := assetTypes[atype]; !ok {
+			return fmt.Errorf("unsupported asset type: %s", atype)
+		}
+	}
+
+	if f.OlderThanDays < 0 {
+		return fmt.Errorf("invalid older_than_days: %d", f.OlderThanDays)
+	}
+
+	if f.OlderThanDays == 0 && f.Source == "" && len(f.AssetTypes) == 0 {
+		return fmt.Errorf("refusing to prune every %s without a filter", f.Kind)
+	}
+	return nil
+}
+
+func (f PruneFilter) stale(last_seen time.Time) bool {
+	if f.OlderThanDays == 0 {
+		return true
+	}
+	return last_seen.Before(time.Now().AddDate(0, 0, -f.OlderThanDays))
+}
+
+func isSource(prop oam.Property, source string) bool {
+	return prop.PropertyType() == oam.SourceProperty && prop.Name() == source
+}
+
+// pruneJob builds a prune job from a PruneFilter.
+func (api *ApiV1) pruneJob(params json.RawMessage) (JobFunc, error) {
+	var filter PruneFilter
+
+	if err := json.Unmarshal(params, &filter); err != nil {
+		return nil, err
+	}
+
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, job *Job) (any, error) {
+		return api.prune(ctx, job, filter)
+	}, nil
+}
+
+// PruneAssets is a shortcut for POST /jobs with the "prune" kind, taking
+// the PruneFilter as body. It requires an admin key.
+func (api *ApiV1) PruneAssets(w http.ResponseWriter, r *http.Request) {
+	if !api.requireAdmin(w, r) {
+		return
+	}
+
+	if r.Body == nil {
+		http.Error(w, "no body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	params, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Error reading request body: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	fn, err := api.pruneJob(params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	api.writeJob(w, api.startJob("prune", fn))
+}
+
+// prune deletes the objects matching filter, publishing a delete event
+// for each of them. Entities are deleted along with their edges and
+// tags. The entities are scanned a type at a time, deleting what
+// matches as it is found; progress is reported in types scanned.
+func (api *ApiV1) prune(ctx context.Context, job *Job, filter PruneFilter) (*PruneResult, error) {
+	result := &PruneResult{}
+
+	scanned := make(map[oam.AssetType]bool)
+	for _, atype := range filter.AssetTypes {
+		scanned[atype] = true
+	}
+	if len(scanned) == 0 {
+		for atype := range assetTypes {
+			scanned[atype] = true
+		}
+	}
+	job.SetTotal(len(scanned))
+
+	remove := func(remove func() error) {
+		result.Matched++
+		if err := remove(); err != nil {
+			result.fail(err)
+		} else {
+			result.Deleted++
+		}
+	}
+
+	for atype := range scanned {
+		// The store reports empty results as errors.
+		entities, _ := api.store.FindEntitiesByType(ctx, atype, time.Time{})
+
+		for _, entity := range entities {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+
+			switch filter.Kind {
+			case "entity":
+				if !filter.stale(entity.LastSeen) || !api.hasSource(ctx, entity, filter.Source) {
+					continue
+				}
+				remove(func() error {
+					_, err := api.removeEntityCascade(entity.ID, "", true)
+					return err
+				})
+			case "edge", "edge_tag":
+				for _, edge := range api.pruneEdges(ctx, entity, scanned) {
+					if filter.Kind == "edge" {
+						if !filter.stale(edge.LastSeen) || !api.edgeHasSource(ctx, edge, filter.Source) {
+							continue
+						}
+						remove(func() error {
+							out, outcome, err := api.removeEdge(edge.ID, "")
+							if err == nil {
+								api.bus.Publish(outcome.EdgeEvent(), EdgeFromStore(out))
+							}
+							return err
+						})
+						continue
+					}
+
+					tags, _ := api.store.GetEdgeTags(ctx, edge, time.Time{})
+					for _, tag := range tags {
+						if !filter.stale(tag.LastSeen) || (filter.Source != "" && !isSource(tag.Property, filter.Source)) {
+							continue
+						}
+						remove(func() error {
+							out, err := api.removeEdgeTag(tag.ID, "")
+							if err == nil {
+								api.bus.Publish(EdgeTagDeleted, EdgeTagFromStore(out))
+							}
+							return err
+						})
+					}
+				}
+			case "entity_tag":
+				tags, _ := api.store.GetEntityTags(ctx, entity, time.Time{})
+				for _, tag := range tags {
+					if !filter.stale(tag.LastSeen) || (filter.Source != "" && !isSource(tag.Property, filter.Source)) {
+						continue
+					}
+					remove(func() error {
+						out, err := api.removeEntityTag(tag.ID, "")
+						if err == nil {
+							api.bus.Publish(EntityTagDeleted, EntityTagFromStore(out))
+						}
+						return err
+					})
+				}
+			}
+		}
+
+		job.Advance(1)
+	}
+
+	return result, nil
+}
+
+// pruneEdges returns the edges of entity to consider, each edge being
+// considered once: from its source entity, or from its destination when
+// the type of the source is not scanned.
+func (api *ApiV1) pruneEdges(ctx context.Context, entity *dbt.Entity, scanned map[oam.AssetType]bool) []*dbt.Edge {
+	// The store reports empty results as errors.
+	edges, _ := api.store.OutgoingEdges(ctx, entity, time.Time{})
+
+	in, _ := api.store.IncomingEdges(ctx, entity, time.Time{})
+	for _, edge := range in {
+		from := edge.FromEntity
+		if from != nil && from.Asset == nil {
+			from, _ = api.store.FindEntityById(ctx, from.ID)
+		}
+		if from == nil || from.Asset == nil || !scanned[from.Asset.AssetType()] {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+func (api *ApiV1) hasSource(ctx context.Context, entity *dbt.Entity, source string) bool {
+	if source == "" {
+		return true
+	}
+
+	tags, _ := api.store.GetEntityTags(ctx, entity, time.Time{}, source)
+	for _, tag := range tags {
+		if isSource(tag.Property, source) {
+			return true
+		}
+	}
+	return false
+}
+
+func (api *ApiV1) edgeHasSource(ctx context.Context, edge *dbt.Edge, source string) bool {
+	if source == "" {
+		return true
+	}
+
+	tags, _ := api.store.GetEdgeTags(ctx, edge, time.Time{}, source)
+	for _, tag := range tags {
+		if isSource(tag.Property, source) {
+			return true
+		}
+	}
+	return false
+}
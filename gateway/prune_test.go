@@ -0,0 +1,175 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0ppliger/oam-broker/wire"
+)
+
+// waitJob waits for the job with the given ID to finish, as an admin.
+func (tg *testGateway) waitJob(id string) wire.Job {
+	tg.t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, body := request(tg.t, "GET", tg.url+"/jobs/"+id, "", map[string]string{AdminKeyHeader: "admin-key"})
+		expectStatus(tg.t, resp, http.StatusOK)
+		var job wire.Job
+		if err := json.Unmarshal(body, &job); err != nil {
+			tg.t.Fatal(err)
+		}
+		if job.State != JobRunning {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	tg.t.Fatalf("job %s still running", id)
+	return wire.Job{}
+}
+
+// prune runs a prune job as an admin and returns its result.
+func (tg *testGateway) prune(filter string) PruneResult {
+	tg.t.Helper()
+
+	resp, body := request(tg.t, "POST", tg.url+"/admin/prune", filter, map[string]string{AdminKeyHeader: "admin-key"})
+	expectStatus(tg.t, resp, http.StatusAccepted)
+	var job wire.Job
+	if err := json.Unmarshal(body, &job); err != nil {
+		tg.t.Fatal(err)
+	}
+
+	job = tg.waitJob(job.ID)
+	if job.State != JobSucceeded {
+		tg.t.Fatalf("prune %s: %s %s", filter, job.State, job.Error)
+	}
+	var result PruneResult
+	if err := json.Unmarshal(job.Result, &result); err != nil {
+		tg.t.Fatal(err)
+	}
+	return result
+}
+
+func TestPruneRequiresAdminKey(t *testing.T) {
+	filter := `{"kind":"entity","older_than_days":30}`
+	job := `{"kind":"prune","params":` + filter + `}`
+
+	closed := newTestGateway(t)
+	resp, _ := request(t, "POST", closed.url+"/admin/prune", filter, map[string]string{AdminKeyHeader: "admin-key"})
+	expectStatus(t, resp, http.StatusForbidden)
+	resp, _ = request(t, "POST", closed.url+"/jobs", job, map[string]string{AdminKeyHeader: "admin-key"})
+	expectStatus(t, resp, http.StatusForbidden)
+
+	tg := newTestGateway(t, WithAdminKeys("admin-key"))
+	cases := []struct {
+		name   string
+		path   string
+		body   string
+		header map[string]string
+		status int
+	}{
+		{"no key", "/admin/prune", filter, nil, http.StatusUnauthorized},
+		{"API key", "/admin/prune", filter, map[string]string{"X-API-Key": "admin-key"}, http.StatusUnauthorized},
+		{"wrong key", "/admin/prune", filter, map[string]string{AdminKeyHeader: "guess"}, http.StatusUnauthorized},
+		{"job without key", "/jobs", job, nil, http.StatusUnauthorized},
+		{"admin key", "/admin/prune", filter, map[string]string{AdminKeyHeader: "admin-key"}, http.StatusAccepted},
+		{"job with admin key", "/jobs", job, map[string]string{AdminKeyHeader: "admin-key"}, http.StatusAccepted},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp, body := request(t, "POST", tg.url+c.path, c.body, c.header)
+			if resp.StatusCode != c.status {
+				t.Errorf("expected %d, got %d %s", c.status, resp.StatusCode, body)
+			}
+		})
+	}
+}
+
+func TestPruneJobRequiresAdminKey(t *testing.T) {
+	tg := newTestGateway(t, WithAdminKeys("admin-key"))
+	admin := map[string]string{AdminKeyHeader: "admin-key"}
+
+	resp, body := request(t, "POST", tg.url+"/admin/prune", `{"kind":"entity","older_than_days":30}`, admin)
+	expectStatus(t, resp, http.StatusAccepted)
+	var job wire.Job
+	if err := json.Unmarshal(body, &job); err != nil {
+		t.Fatal(err)
+	}
+	tg.waitJob(job.ID)
+
+	// Only admins see the job, its result, and may cancel it.
+	for _, header := range []map[string]string{nil, {AdminKeyHeader: "guess"}} {
+		for _, route := range []struct{ method, path string }{
+			{"GET", "/jobs/" + job.ID},
+			{"GET", "/jobs/" + job.ID + "/result"},
+			{"DELETE", "/jobs/" + job.ID},
+		} {
+			resp, _ := request(t, route.method, tg.url+route.path, "", header)
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("%s %s with %v: expected %d, got %d", route.method, route.path, header, http.StatusUnauthorized, resp.StatusCode)
+			}
+		}
+
+		resp, body := request(t, "GET", tg.url+"/jobs", "", header)
+		expectStatus(t, resp, http.StatusOK)
+		if strings.Contains(string(body), job.ID) {
+			t.Errorf("listed with %v: %s", header, body)
+		}
+	}
+
+	resp, _ = request(t, "GET", tg.url+"/jobs/"+job.ID+"/result", "", admin)
+	expectStatus(t, resp, http.StatusOK)
+	resp, body = request(t, "GET", tg.url+"/jobs", "", admin)
+	expectStatus(t, resp, http.StatusOK)
+	if !strings.Contains(string(body), job.ID) {
+		t.Errorf("not listed for admins: %s", body)
+	}
+	resp, _ = request(t, "DELETE", tg.url+"/jobs/"+job.ID, "", admin)
+	expectStatus(t, resp, http.StatusAccepted)
+}
+
+func TestPrune(t *testing.T) {
+	tg := newTestGateway(t, WithAdminKeys("admin-key"))
+
+	fqdn := tg.entity(fqdnBody("www.example.com"))
+	ip := tg.entity(ipBody("192.0.2.1"))
+	other := tg.entity(ipBody("192.0.2.2"))
+	edge := tg.edge(dnsEdgeBody(fqdn.ID, ip.ID, 60))
+	var kept, pruned EntityTag
+	tg.must("POST", "/emit/entity_tag", `{"type":"SourceProperty","property":{"name":"dns","confidence":50},"entity":"`+ip.ID+`"}`, &pruned)
+	tg.must("POST", "/emit/entity_tag", `{"type":"SourceProperty","property":{"name":"whois","confidence":50},"entity":"`+other.ID+`"}`, &kept)
+
+	// The edge only is incoming to an IPAddress, and is found from it.
+	result := tg.prune(`{"kind":"edge","asset_types":["IPAddress"]}`)
+	if result.Matched != 1 || result.Deleted != 1 {
+		t.Errorf("edge prune: %+v", result)
+	}
+	tg.status("GET", "/edge/"+edge.ID, "", http.StatusNotFound)
+
+	// Nothing is stale yet.
+	if result := tg.prune(`{"kind":"entity","older_than_days":1}`); result.Matched != 0 {
+		t.Errorf("stale prune: %+v", result)
+	}
+
+	result = tg.prune(`{"kind":"entity","source":"dns"}`)
+	if result.Matched != 1 || result.Deleted != 1 {
+		t.Errorf("source prune: %+v", result)
+	}
+	tg.status("GET", "/entity/"+ip.ID, "", http.StatusNotFound)
+	tg.status("GET", "/entity_tag/"+pruned.ID, "", http.StatusNotFound)
+
+	result = tg.prune(`{"kind":"entity_tag","source":"whois"}`)
+	if result.Matched != 1 || result.Deleted != 1 {
+		t.Errorf("tag prune: %+v", result)
+	}
+	tg.status("GET", "/entity_tag/"+kept.ID, "", http.StatusNotFound)
+	var still Entity
+	tg.must("GET", "/entity/"+other.ID, "", &still)
+	tg.must("GET", "/entity/"+fqdn.ID, "", &still)
+
+	resp, _ := request(t, "POST", tg.url+"/admin/prune", `{"kind":"entity"}`, map[string]string{AdminKeyHeader: "admin-key"})
+	expectStatus(t, resp, http.StatusBadRequest)
+}
@@ -0,0 +1,302 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// RouteGroup groups the routes sharing a rate limit. The gRPC methods
+// are grouped with the routes they mirror.
+type RouteGroup string
+
+const (
+	// EmitRoutes are the routes writing to the store: every method but
+	// GET and HEAD, so imports and jobs count too, and GraphQL mutations.
+	EmitRoutes RouteGroup = "emit"
+	// ListenRoutes is /listen, and GraphQL subscriptions.
+	ListenRoutes RouteGroup = "listen"
+	// ReadRoutes are the other GET routes, and GraphQL queries.
+	ReadRoutes RouteGroup = "read"
+)
+
+// Rate is a token bucket: Burst requests can be made at once, then
+// PerSecond requests per second. A zero rate does not limit.
+type Rate struct {
+	PerSecond float64
+	Burst     int
+}
+
+// Limits bounds what each client can do. Zero values do not limit.
+type Limits struct {
+	Rates map[RouteGroup]Rate
+	// MaxSubscriptions bounds the concurrent /listen and Subscribe
+	// streams of a client.
+	MaxSubscriptions int
+	// DailyWrites bounds the requests a client makes to EmitRoutes per
+	// day, from midnight UTC.
+	DailyWrites int
+}
+
+// subscriptionRetry is the Retry-After of clients holding too many
+// streams, since when one closes cannot be told.
+const subscriptionRetry = 30 * time.Second
+
+// apiKey returns the API key of a request, sent as a bearer token or in
+// X-API-Key.
+func apiKey(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		return token
+	}
+	return r.Header.Get("X-API-Key")
+}
+
+// ClientIdentity returns the remote address of a request, which tells
+// clients apart unless they send an API key the gateway verifies.
+func ClientIdentity(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr:" + host
+}
+
+// routeGroup groups a request, reading at most graphql_body_size bytes
+// of the body of GraphQL requests.
+func routeGroup(r *http.Request, graphql_body_size int64) RouteGroup {
+	switch {
+	case r.URL.Path == "/listen":
+		return ListenRoutes
+	case r.URL.Path == "/graphql":
+		return graphqlRouteGroup(r, graphql_body_size)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return ReadRoutes
+	default:
+		return EmitRoutes
+	}
+}
+
+// graphqlRouteGroup groups a GraphQL request by its operation, whatever
+// its method. At most max bytes of the body are read for it, then put
+// back for the handler, DefaultMaxBodySize when the route accepts any
+// size. Requests which cannot be told apart, such as those with a larger
+// body or one which cannot be read, are writes.
+func graphqlRouteGroup(r *http.Request, max int64) RouteGroup {
+	var req GraphQLRequest
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+	case http.MethodPost:
+		if max <= 0 {
+			max = DefaultMaxBodySize
+		}
+		if r.Body == nil || r.ContentLength > max {
+			return EmitRoutes
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, max+1))
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+
+		if err != nil || int64(len(body)) > max || json.Unmarshal(body, &req) != nil {
+			return EmitRoutes
+		}
+	}
+
+	switch req.operation() {
+	case ast.OperationTypeQuery:
+		return ReadRoutes
+	case ast.OperationTypeSubscription:
+		return ListenRoutes
+	default:
+		return EmitRoutes
+	}
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket up to now and takes a token from it. When it
+// is empty, it returns how long until a token is available.
+func (b *bucket) take(rate Rate, now time.Time) (bool, time.Duration) {
+	b.tokens = math.Min(float64(rate.Burst), b.tokens+now.Sub(b.last).Seconds()*rate.PerSecond)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate.PerSecond * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// full returns how long until the bucket is full again.
+func (b *bucket) full(rate Rate) time.Duration {
+	return time.Duration((float64(rate.Burst) - b.tokens) / rate.PerSecond * float64(time.Second))
+}
+
+type quota struct {
+	day    string
+	writes int
+}
+
+type bucketKey struct {
+	client string
+	group  RouteGroup
+}
+
+// limiter enforces Limits on the requests of every client.
+type limiter struct {
+	limits   Limits
+	identify func(*http.Request) string
+	now      func() time.Time
+	// graphql_body_size bounds what is read of GraphQL bodies to group
+	// them, the size /graphql accepts.
+	graphql_body_size int64
+
+	mutex         sync.Mutex
+	buckets       map[bucketKey]*bucket
+	quotas        map[string]*quota
+	subscriptions map[string]int
+	swept         time.Time
+}
+
+func newLimiter(limits Limits, identify func(*http.Request) string, graphql_body_size int64) *limiter {
+	return &limiter{
+		limits:            limits,
+		identify:          identify,
+		now:               time.Now,
+		graphql_body_size: graphql_body_size,
+		buckets:           make(map[bucketKey]*bucket),
+		quotas:            make(map[string]*quota),
+		subscriptions:     make(map[string]int),
+	}
+}
+
+// tooManyRequests tells the client when to retry, and returns why it is
+// rejected.
+func tooManyRequests(header http.Header, retry time.Duration, reason string) string {
+	header.Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+	return reason
+}
+
+// sweep forgets the buckets that refilled and the quotas of past days,
+// so that clients seen once do not stay in memory.
+func (l *limiter) sweep(now time.Time, day string) {
+	if now.Sub(l.swept) < time.Minute {
+		return
+	}
+	l.swept = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= b.full(l.limits.Rates[key.group]) {
+			delete(l.buckets, key)
+		}
+	}
+	for client, q := range l.quotas {
+		if q.day != day {
+			delete(l.quotas, client)
+		}
+	}
+}
+
+// admit checks the limits of a request, setting the limit headers in
+// header. It returns why the request is rejected, or an empty string
+// when it is admitted. Admitted subscriptions must be released with done.
+func (l *limiter) admit(header http.Header, client string, group RouteGroup) string {
+	now := l.now()
+	day := now.UTC().Format(time.DateOnly)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.sweep(now, day)
+
+	if rate := l.limits.Rates[group]; rate.PerSecond > 0 {
+		key := bucketKey{client, group}
+		b, ok := l.buckets[key]
+		if !ok {
+			b = &bucket{tokens: float64(rate.Burst), last: now}
+			l.buckets[key] = b
+		}
+
+		ok, retry := b.take(rate, now)
+		header.Set("X-RateLimit-Limit", strconv.Itoa(rate.Burst))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(int(b.tokens)))
+		header.Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(b.full(rate).Seconds()))))
+		if !ok {
+			return tooManyRequests(header, retry, fmt.Sprintf("Rate limit of %s routes exceeded", group))
+		}
+	}
+
+	if group == EmitRoutes && l.limits.DailyWrites > 0 {
+		q, ok := l.quotas[client]
+		if !ok || q.day != day {
+			q = &quota{day: day}
+			l.quotas[client] = q
+		}
+
+		midnight := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		reset := midnight.Sub(now)
+		header.Set("X-Quota-Limit", strconv.Itoa(l.limits.DailyWrites))
+		header.Set("X-Quota-Reset", strconv.Itoa(int(math.Ceil(reset.Seconds()))))
+		if q.writes >= l.limits.DailyWrites {
+			header.Set("X-Quota-Remaining", "0")
+			return tooManyRequests(header, reset, "Daily write quota exceeded")
+		}
+		q.writes++
+		header.Set("X-Quota-Remaining", strconv.Itoa(l.limits.DailyWrites-q.writes))
+	}
+
+	if group == ListenRoutes && l.limits.MaxSubscriptions > 0 {
+		if l.subscriptions[client] >= l.limits.MaxSubscriptions {
+			return tooManyRequests(header, subscriptionRetry, fmt.Sprintf("Too many concurrent subscriptions, at most %d", l.limits.MaxSubscriptions))
+		}
+		l.subscriptions[client]++
+	}
+
+	return ""
+}
+
+// done releases the subscription of a client admitted to /listen.
+func (l *limiter) done(client string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.subscriptions[client]--
+	if l.subscriptions[client] <= 0 {
+		delete(l.subscriptions, client)
+	}
+}
+
+// limit enforces the limits on the requests to handler.
+func (l *limiter) limit(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := l.identify(r)
+		group := routeGroup(r, l.graphql_body_size)
+
+		if reason := l.admit(w.Header(), client, group); reason != "" {
+			http.Error(w, reason, http.StatusTooManyRequests)
+			return
+		}
+		if group == ListenRoutes && l.limits.MaxSubscriptions > 0 {
+			defer l.done(client)
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,220 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0ppliger/oam-broker/memory"
+)
+
+// send makes a request as the client with the given admin key, which
+// the limits tell apart from the others.
+func (tg *testGateway) send(method, path, body, key string) *http.Response {
+	tg.t.Helper()
+
+	req, err := http.NewRequest(method, tg.url+path, strings.NewReader(body))
+	if err != nil {
+		tg.t.Fatal(err)
+	}
+	req.Header.Set(AdminKeyHeader, key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		tg.t.Fatal(err)
+	}
+	if path != "/listen" {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	return resp
+}
+
+func expectStatus(t *testing.T, resp *http.Response, status int) {
+	t.Helper()
+
+	if resp.StatusCode != status {
+		t.Fatalf("%s %s: expected %d, got %d", resp.Request.Method, resp.Request.URL.Path, status, resp.StatusCode)
+	}
+}
+
+func retryAfter(t *testing.T, resp *http.Response) int {
+	t.Helper()
+
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil {
+		t.Fatalf("invalid Retry-After %q", resp.Header.Get("Retry-After"))
+	}
+	return seconds
+}
+
+func TestRateLimits(t *testing.T) {
+	tg := newTestGateway(t, WithAdminKeys("scanner", "other"), WithLimits(Limits{
+		Rates: map[RouteGroup]Rate{EmitRoutes: {PerSecond: 0.01, Burst: 2}},
+	}))
+
+	resp := tg.send("POST", "/emit/entity", fqdnBody("a.example.com"), "scanner")
+	expectStatus(t, resp, http.StatusOK)
+	if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining != "1" {
+		t.Errorf("X-RateLimit-Remaining is %q, expected 1", remaining)
+	}
+	expectStatus(t, tg.send("POST", "/emit/entity", fqdnBody("b.example.com"), "scanner"), http.StatusOK)
+
+	resp = tg.send("POST", "/emit/entity", fqdnBody("c.example.com"), "scanner")
+	expectStatus(t, resp, http.StatusTooManyRequests)
+	if seconds := retryAfter(t, resp); seconds < 99 || seconds > 100 {
+		t.Errorf("Retry-After is %d, expected 100", seconds)
+	}
+
+	// Other clients and other route groups have their own buckets.
+	expectStatus(t, tg.send("POST", "/emit/entity", fqdnBody("c.example.com"), "other"), http.StatusOK)
+	expectStatus(t, tg.send("GET", "/types", "", "scanner"), http.StatusOK)
+}
+
+func TestDailyWriteQuota(t *testing.T) {
+	tg := newTestGateway(t, WithAdminKeys("scanner", "other"), WithLimits(Limits{DailyWrites: 2}))
+
+	for _, name := range []string{"a.example.com", "b.example.com"} {
+		expectStatus(t, tg.send("POST", "/emit/entity", fqdnBody(name), "scanner"), http.StatusOK)
+	}
+	// Reads do not count.
+	expectStatus(t, tg.send("GET", "/types", "", "scanner"), http.StatusOK)
+
+	resp := tg.send("POST", "/emit/entity", fqdnBody("c.example.com"), "scanner")
+	expectStatus(t, resp, http.StatusTooManyRequests)
+	if remaining := resp.Header.Get("X-Quota-Remaining"); remaining != "0" {
+		t.Errorf("X-Quota-Remaining is %q, expected 0", remaining)
+	}
+	if seconds := retryAfter(t, resp); seconds <= 0 || seconds > 24*60*60 {
+		t.Errorf("Retry-After is %d, expected the time until midnight", seconds)
+	}
+
+	expectStatus(t, tg.send("POST", "/emit/entity", fqdnBody("c.example.com"), "other"), http.StatusOK)
+}
+
+func TestSubscriptionCap(t *testing.T) {
+	tg := newTestGateway(t, WithAdminKeys("scanner", "other"), WithLimits(Limits{MaxSubscriptions: 1}))
+
+	first := tg.send("GET", "/listen", "", "scanner")
+	expectStatus(t, first, http.StatusOK)
+
+	second := tg.send("GET", "/listen", "", "scanner")
+	expectStatus(t, second, http.StatusTooManyRequests)
+	second.Body.Close()
+	retryAfter(t, second)
+
+	other := tg.send("GET", "/listen", "", "other")
+	expectStatus(t, other, http.StatusOK)
+	other.Body.Close()
+
+	// Closing the stream frees the subscription once the gateway notices.
+	first.Body.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp := tg.send("GET", "/listen", "", "scanner")
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the closed subscription was not released")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestUnverifiedKeys(t *testing.T) {
+	tg := newTestGateway(t, WithLimits(Limits{DailyWrites: 1}))
+
+	// API keys the gateway does not verify do not tell clients apart, who
+	// would otherwise escape their limits by making up keys.
+	for i, key := range []string{"scanner", "other"} {
+		req, err := http.NewRequest("POST", tg.url+"/emit/entity", strings.NewReader(fqdnBody("www.example.com")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-API-Key", key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if expected := []int{http.StatusOK, http.StatusTooManyRequests}[i]; resp.StatusCode != expected {
+			t.Errorf("key %s: expected %d, got %d", key, expected, resp.StatusCode)
+		}
+	}
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	tg := newTestGateway(t, WithAdminKeys("scanner"), WithLimits(Limits{DailyWrites: 1, MaxSubscriptions: 1}))
+
+	graphql := func(query string) *http.Response {
+		body, _ := json.Marshal(GraphQLRequest{Query: query})
+		return tg.send("POST", "/graphql", string(body), "scanner")
+	}
+
+	// Queries sent with POST are reads.
+	for range 2 {
+		expectStatus(t, graphql(`{ entity(id: "unknown") { id } }`), http.StatusOK)
+	}
+	expectStatus(t, graphql(`mutation { create_entity(type: FQDN, asset: {name: "www.example.com"}) { id } }`), http.StatusOK)
+	expectStatus(t, graphql(`mutation { create_entity(type: FQDN, asset: {name: "app.example.com"}) { id } }`), http.StatusTooManyRequests)
+
+	// Subscriptions count with those of /listen.
+	first := tg.send("GET", "/listen", "", "scanner")
+	expectStatus(t, first, http.StatusOK)
+	defer first.Body.Close()
+	expectStatus(t, graphql(`subscription { events { type } }`), http.StatusTooManyRequests)
+}
+
+// countingReader counts the bytes read of an endless body.
+type countingReader struct {
+	read int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = ' '
+	}
+	c.read += int64(len(p))
+	return len(p), nil
+}
+
+func TestGraphQLBodyLimits(t *testing.T) {
+	g, err := New(WithRepository(memory.New()), WithMaxBodySize(256), WithLimits(Limits{DailyWrites: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Larger bodies are writes, grouped without being read past the
+	// size the route accepts.
+	body := &countingReader{}
+	req := httptest.NewRequest("POST", "/graphql", io.MultiReader(strings.NewReader(`{"query":"{ types }"`), body))
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", w.Code)
+	}
+	if body.read > 256+512 {
+		t.Errorf("%d bytes read of a body bounded to 256", body.read)
+	}
+
+	query, _ := json.Marshal(GraphQLRequest{Query: `{ entity(id: "unknown") { id } }`})
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(string(query))))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", w.Code)
+	}
+
+	mutation, _ := json.Marshal(GraphQLRequest{Query: `mutation { create_entity(type: FQDN, asset: {name: "www.example.com"}) { id } }`})
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(string(mutation))))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the write quota used by the larger body, got %d", w.Code)
+	}
+}
@@ -0,0 +1,40 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	dbt "github.com/owasp-amass/asset-db/types"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+// Repository is the asset store the gateway serves: the methods of the
+// asset-db repository.Repository it calls, which take a context in the
+// asset-db fork the server is built with. Declaring them here rather
+// than importing the fork lets the gateway be built against published
+// releases of asset-db, given a store implementing them.
+type Repository interface {
+	CreateEntity(ctx context.Context, entity *dbt.Entity) (*dbt.Entity, error)
+	FindEntityById(ctx context.Context, id string) (*dbt.Entity, error)
+	FindEntitiesByContent(ctx context.Context, asset oam.Asset, since time.Time) ([]*dbt.Entity, error)
+	FindEntitiesByType(ctx context.Context, atype oam.AssetType, since time.Time) ([]*dbt.Entity, error)
+	DeleteEntity(ctx context.Context, id string) error
+
+	CreateEdge(ctx context.Context, edge *dbt.Edge) (*dbt.Edge, error)
+	FindEdgeById(ctx context.Context, id string) (*dbt.Edge, error)
+	IncomingEdges(ctx context.Context, entity *dbt.Entity, since time.Time, labels ...string) ([]*dbt.Edge, error)
+	OutgoingEdges(ctx context.Context, entity *dbt.Entity, since time.Time, labels ...string) ([]*dbt.Edge, error)
+	DeleteEdge(ctx context.Context, id string) error
+
+	CreateEntityTag(ctx context.Context, entity *dbt.Entity, tag *dbt.EntityTag) (*dbt.EntityTag, error)
+	FindEntityTagById(ctx context.Context, id string) (*dbt.EntityTag, error)
+	GetEntityTags(ctx context.Context, entity *dbt.Entity, since time.Time, names ...string) ([]*dbt.EntityTag, error)
+	DeleteEntityTag(ctx context.Context, id string) error
+
+	CreateEdgeTag(ctx context.Context, edge *dbt.Edge, tag *dbt.EdgeTag) (*dbt.EdgeTag, error)
+	FindEdgeTagById(ctx context.Context, id string) (*dbt.EdgeTag, error)
+	GetEdgeTags(ctx context.Context, edge *dbt.Edge, since time.Time, names ...string) ([]*dbt.EdgeTag, error)
+	DeleteEdgeTag(ctx context.Context, id string) error
+
+	Close() error
+}
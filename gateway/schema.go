@@ -0,0 +1,117 @@
+package gateway
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// JSONSchema derives the JSON Schema of the JSON encoding of a Go type
+// by reflection. Struct fields are named after their JSON tag, and are
+// required unless tagged omitempty or omitzero.
+func JSONSchema(t reflect.Type) Schema {
+	if t == timeType {
+		return Schema{"type": "string", "format": "date-time"}
+	}
+
+	if t.Kind() != reflect.Pointer {
+		// Types such as netip.Addr encode themselves as text.
+		if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+			return Schema{"type": "string"}
+		}
+		if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+			return Schema{}
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return JSONSchema(t.Elem())
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return Schema{"type": "array", "items": JSONSchema(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": JSONSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+
+	// Interfaces may hold anything.
+	return Schema{}
+}
+
+func structSchema(t reflect.Type) Schema {
+	properties := Schema{}
+	required := []string{}
+
+	var collect func(t reflect.Type)
+	collect = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := strings.Split(field.Tag.Get("json"), ",")
+			name := tag[0]
+
+			if name == "-" {
+				continue
+			}
+
+			// Untagged embedded structs are flattened, like
+			// encoding/json does.
+			if field.Anonymous && name == "" {
+				embedded := field.Type
+				if embedded.Kind() == reflect.Pointer {
+					embedded = embedded.Elem()
+				}
+				if embedded.Kind() == reflect.Struct {
+					collect(embedded)
+					continue
+				}
+			}
+
+			if !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			properties[name] = JSONSchema(field.Type)
+
+			optional := false
+			for _, option := range tag[1:] {
+				if option == "omitempty" || option == "omitzero" {
+					optional = true
+				}
+			}
+			if !optional {
+				required = append(required, name)
+			}
+		}
+	}
+	collect(t)
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
@@ -0,0 +1,52 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// The operations of the API are methods of ApiV1 taking and returning
+// the objects of the wire package. The REST handlers, the GraphQL
+// resolvers and the gRPC methods only decode their input and encode the
+// result, so that every API validates, locks and publishes events alike.
+
+// ServiceError is the failure of an operation of the API, along with
+// the HTTP status the REST API answers it with. The other APIs map the
+// status to their own codes.
+type ServiceError struct {
+	Status int
+	// Message prefixes the message of Err.
+	Message string
+	Err     error
+}
+
+func serviceError(status int, message string, err error) *ServiceError {
+	return &ServiceError{Status: status, Message: message, Err: err}
+}
+
+func (e *ServiceError) Error() string {
+	return e.Message + e.Err.Error()
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+// Extensions reports the status in the extensions of GraphQL errors.
+func (e *ServiceError) Extensions() map[string]any {
+	return map[string]any{"status": e.Status}
+}
+
+// decodeFields decodes obj from the fields of an object of the API,
+// sent as arguments of GraphQL or as a gRPC message, the way the REST
+// API decodes bodies.
+func (api *ApiV1) decodeFields(fields map[string]any, obj decodable) error {
+	json_encoded, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	if err := api.decodeObject(json_encoded, obj); err != nil {
+		return serviceError(http.StatusBadRequest, "invalid JSON: ", err)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	dbt "github.com/owasp-amass/asset-db/types"
+	"github.com/sirupsen/logrus"
+)
+
+// StoreWatcher tails the asset store by polling it with a LastSeen
+// watermark, so that writes made by other clients of the same database
+// (e.g. Amass) are published on the EventBus too.
+//
+// Polling cannot observe deletions: those are only published when they
+// go through the gateway handlers.
+type StoreWatcher struct {
+	store    Repository
+	bus      *EventBus
+	logger   *logrus.Logger
+	interval time.Duration
+	// overlap re-reads a window before the watermark on every poll so
+	// that rows committed late with an older LastSeen are not missed.
+	overlap time.Duration
+
+	watermark time.Time
+	// seen holds the LastSeen of the objects announced within the
+	// overlap window, by kind and ID, such as "edge:5": stores number
+	// the objects of each kind on their own.
+	seen map[string]time.Time
+}
+
+// ChangeFeed is implemented by repositories able to list the objects
+// written since a time in one query per kind. The StoreWatcher uses it
+// when available; other repositories are walked from the entities that
+// changed, which costs queries per entity and misses the edges and tags
+// written between unchanged entities.
+type ChangeFeed interface {
+	EntitiesSince(ctx context.Context, since time.Time) ([]*dbt.Entity, error)
+	EdgesSince(ctx context.Context, since time.Time) ([]*dbt.Edge, error)
+	EntityTagsSince(ctx context.Context, since time.Time) ([]*dbt.EntityTag, error)
+	EdgeTagsSince(ctx context.Context, since time.Time) ([]*dbt.EdgeTag, error)
+}
+
+func NewStoreWatcher(store Repository, bus *EventBus, logger *logrus.Logger, interval time.Duration) *StoreWatcher {
+	return &StoreWatcher{
+		store:     store,
+		bus:       bus,
+		logger:    logger,
+		interval:  interval,
+		overlap:   interval,
+		watermark: time.Now(),
+		seen:      make(map[string]time.Time),
+	}
+}
+
+// Run polls the store until ctx is cancelled.
+func (sw *StoreWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(sw.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sw.poll(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (sw *StoreWatcher) poll(ctx context.Context) {
+	since := sw.watermark.Add(-sw.overlap)
+
+	var changes changeSet
+	if feed, ok := sw.store.(ChangeFeed); ok {
+		changes = sw.feed(ctx, feed, since)
+	} else {
+		changes = sw.walk(ctx, since)
+	}
+
+	for _, entity := range changes.entities {
+		if changed, created := sw.observe("entity:"+entity.ID, entity.CreatedAt, entity.LastSeen, since); changed {
+			sw.bus.Publish(pick(created, EntityCreated, EntityUpdated), EntityFromStore(entity))
+		}
+	}
+	for _, edge := range changes.edges {
+		if changed, created := sw.observe("edge:"+edge.ID, edge.CreatedAt, edge.LastSeen, since); changed {
+			sw.bus.Publish(pick(created, EdgeCreated, EdgeUpdated), EdgeFromStore(edge))
+		}
+	}
+	for _, tag := range changes.entity_tags {
+		if changed, created := sw.observe("entity_tag:"+tag.ID, tag.CreatedAt, tag.LastSeen, since); changed {
+			sw.bus.Publish(pick(created, EntityTagCreated, EntityTagUpdated), EntityTagFromStore(tag))
+		}
+	}
+	for _, tag := range changes.edge_tags {
+		if changed, created := sw.observe("edge_tag:"+tag.ID, tag.CreatedAt, tag.LastSeen, since); changed {
+			sw.bus.Publish(pick(created, EdgeTagCreated, EdgeTagUpdated), EdgeTagFromStore(tag))
+		}
+	}
+
+	// Entries that fell out of the overlap window can no longer be
+	// returned twice, so there is no need to remember them.
+	for key, last_seen := range sw.seen {
+		if last_seen.Before(since) {
+			delete(sw.seen, key)
+		}
+	}
+}
+
+// changeSet is what a poll found written since the last one.
+type changeSet struct {
+	entities    []*dbt.Entity
+	edges       []*dbt.Edge
+	entity_tags []*dbt.EntityTag
+	edge_tags   []*dbt.EdgeTag
+}
+
+func (sw *StoreWatcher) feed(ctx context.Context, feed ChangeFeed, since time.Time) changeSet {
+	var changes changeSet
+	var err error
+
+	if changes.entities, err = feed.EntitiesSince(ctx, since); err != nil {
+		sw.logger.Debug("StoreWatcher: entities: ", err)
+	}
+	if changes.edges, err = feed.EdgesSince(ctx, since); err != nil {
+		sw.logger.Debug("StoreWatcher: edges: ", err)
+	}
+	if changes.entity_tags, err = feed.EntityTagsSince(ctx, since); err != nil {
+		sw.logger.Debug("StoreWatcher: entity tags: ", err)
+	}
+	if changes.edge_tags, err = feed.EdgeTagsSince(ctx, since); err != nil {
+		sw.logger.Debug("StoreWatcher: edge tags: ", err)
+	}
+	return changes
+}
+
+// walk finds the entities written since the given time by type, and the
+// edges and tags from them. Entities re-read in the overlap at the same
+// LastSeen were walked by the previous poll, and are not walked again.
+func (sw *StoreWatcher) walk(ctx context.Context, since time.Time) changeSet {
+	var changes changeSet
+	edges := make(map[string]bool)
+
+	for atype := range assetTypes {
+		// The store reports an empty result set as an error.
+		entities, err := sw.store.FindEntitiesByType(ctx, atype, since)
+		if err != nil {
+			sw.logger.Debug("StoreWatcher: ", atype, ": ", err)
+			continue
+		}
+		changes.entities = append(changes.entities, entities...)
+
+		for _, entity := range entities {
+			if last_seen, ok := sw.seen["entity:"+entity.ID]; ok && last_seen.Equal(entity.LastSeen) {
+				continue
+			}
+
+			out, _ := sw.store.OutgoingEdges(ctx, entity, since)
+			in, _ := sw.store.IncomingEdges(ctx, entity, since)
+			for _, edge := range append(out, in...) {
+				if !edges[edge.ID] {
+					edges[edge.ID] = true
+					changes.edges = append(changes.edges, edge)
+				}
+			}
+
+			tags, _ := sw.store.GetEntityTags(ctx, entity, since)
+			changes.entity_tags = append(changes.entity_tags, tags...)
+		}
+	}
+
+	for _, edge := range changes.edges {
+		tags, _ := sw.store.GetEdgeTags(ctx, edge, since)
+		changes.edge_tags = append(changes.edge_tags, tags...)
+	}
+	return changes
+}
+
+// observe records the object of the given key as seen at last_seen, and
+// reports whether this version had not been published yet, and whether
+// it is the first one published, the object being created within the
+// window. It also advances the watermark.
+func (sw *StoreWatcher) observe(key string, created_at, last_seen, since time.Time) (changed, created bool) {
+	if last_seen.After(sw.watermark) {
+		sw.watermark = last_seen
+	}
+
+	prev, announced := sw.seen[key]
+	if announced && prev.Equal(last_seen) {
+		return false, false
+	}
+	sw.seen[key] = last_seen
+	return true, !announced && !created_at.Before(since)
+}
+
+func pick(created bool, on_create, on_update EventType) EventType {
+	if created {
+		return on_create
+	}
+	return on_update
+}
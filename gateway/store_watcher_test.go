@@ -0,0 +1,199 @@
+package gateway
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/0ppliger/oam-broker/eventbus"
+	"github.com/0ppliger/oam-broker/memory"
+	"github.com/owasp-amass/asset-db/repository"
+	dbt "github.com/owasp-amass/asset-db/types"
+	oam_dns "github.com/owasp-amass/open-asset-model/dns"
+	"github.com/owasp-amass/open-asset-model/general"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/sirupsen/logrus"
+)
+
+// walkedStore hides the ChangeFeed of the memory store, for the watcher
+// to walk it like the other stores.
+type walkedStore struct {
+	repository.Repository
+}
+
+// polled returns the events published by one poll of the watcher.
+func polled(sw *StoreWatcher) []EventType {
+	ch := sw.bus.AddSubscriber()
+	done := make(chan struct{})
+	go func() {
+		sw.poll(context.Background())
+		close(done)
+	}()
+
+	// Publish returns once the event is received, so every event is in
+	// once the poll is done.
+	var out []EventType
+	for {
+		select {
+		case sse := <-ch:
+			out = append(out, sse.Event)
+		case <-done:
+			sw.bus.RemoveSubscriber(ch)
+			return out
+		}
+	}
+}
+
+func count(events []EventType, event EventType) int {
+	n := 0
+	for _, e := range events {
+		if e == event {
+			n++
+		}
+	}
+	return n
+}
+
+func TestStoreWatcher(t *testing.T) {
+	stores := map[string]func() repository.Repository{
+		"feed": func() repository.Repository { return memory.New() },
+		"walk": func() repository.Repository { return walkedStore{memory.New()} },
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore()
+			sw := NewStoreWatcher(store, eventbus.New(), logrus.New(), time.Hour)
+
+			fqdn, err := store.CreateEntity(ctx, &dbt.Entity{Asset: &oam_dns.FQDN{Name: "www.example.com"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ip, err := store.CreateEntity(ctx, &dbt.Entity{Asset: &network.IPAddress{Address: netip.MustParseAddr("192.0.2.1"), Type: "IPv4"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			edge, err := store.CreateEdge(ctx, &dbt.Edge{
+				Relation:   &oam_dns.BasicDNSRelation{Name: "dns_record", Header: oam_dns.RRHeader{RRType: 1, Class: 1, TTL: 300}},
+				FromEntity: fqdn,
+				ToEntity:   ip,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.CreateEntityTag(ctx, fqdn, &dbt.EntityTag{Property: &general.SimpleProperty{PropertyName: "source", PropertyValue: "test"}}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.CreateEdgeTag(ctx, edge, &dbt.EdgeTag{Property: &general.SimpleProperty{PropertyName: "source", PropertyValue: "test"}}); err != nil {
+				t.Fatal(err)
+			}
+
+			got := polled(sw)
+			for event, n := range map[EventType]int{
+				EntityCreated:    2,
+				EdgeCreated:      1,
+				EntityTagCreated: 1,
+				EdgeTagCreated:   1,
+			} {
+				if count(got, event) != n {
+					t.Errorf("first poll: %d %s events in %v, want %d", count(got, event), event, got, n)
+				}
+			}
+			if len(got) != 5 {
+				t.Errorf("first poll published %v", got)
+			}
+
+			// What is re-read in the overlap is not published again.
+			if got := polled(sw); len(got) != 0 {
+				t.Errorf("second poll published %v", got)
+			}
+
+			// An update within the overlap window is announced as such,
+			// although the entity was created in the window too.
+			time.Sleep(time.Millisecond)
+			if _, err := store.CreateEntity(ctx, &dbt.Entity{Asset: &oam_dns.FQDN{Name: "www.example.com"}}); err != nil {
+				t.Fatal(err)
+			}
+			got = polled(sw)
+			if count(got, EntityUpdated) != 1 || count(got, EntityCreated) != 0 {
+				t.Errorf("poll after update published %v", got)
+			}
+		})
+	}
+}
+
+// collidingFeed returns one object of every kind, all with the same ID,
+// like stores numbering each kind on its own.
+type collidingFeed struct {
+	repository.Repository
+	entity *dbt.Entity
+}
+
+func newCollidingFeed(at time.Time) *collidingFeed {
+	return &collidingFeed{entity: &dbt.Entity{
+		ID:        "5",
+		CreatedAt: at,
+		LastSeen:  at,
+		Asset:     &oam_dns.FQDN{Name: "www.example.com"},
+	}}
+}
+
+func (f *collidingFeed) EntitiesSince(ctx context.Context, since time.Time) ([]*dbt.Entity, error) {
+	return []*dbt.Entity{f.entity}, nil
+}
+
+// The edge is seen at the same time as the entity, the tags later.
+func (f *collidingFeed) EdgesSince(ctx context.Context, since time.Time) ([]*dbt.Edge, error) {
+	return []*dbt.Edge{{
+		ID:         "5",
+		CreatedAt:  f.entity.CreatedAt,
+		LastSeen:   f.entity.LastSeen,
+		Relation:   &general.SimpleRelation{Name: "node"},
+		FromEntity: f.entity,
+		ToEntity:   f.entity,
+	}}, nil
+}
+
+func (f *collidingFeed) EntityTagsSince(ctx context.Context, since time.Time) ([]*dbt.EntityTag, error) {
+	at := f.entity.LastSeen.Add(time.Millisecond)
+	return []*dbt.EntityTag{{
+		ID:        "5",
+		CreatedAt: at,
+		LastSeen:  at,
+		Property:  &general.SimpleProperty{PropertyName: "source", PropertyValue: "test"},
+		Entity:    f.entity,
+	}}, nil
+}
+
+func (f *collidingFeed) EdgeTagsSince(ctx context.Context, since time.Time) ([]*dbt.EdgeTag, error) {
+	edges, _ := f.EdgesSince(ctx, since)
+	at := f.entity.LastSeen.Add(2 * time.Millisecond)
+	return []*dbt.EdgeTag{{
+		ID:        "5",
+		CreatedAt: at,
+		LastSeen:  at,
+		Property:  &general.SimpleProperty{PropertyName: "source", PropertyValue: "test"},
+		Edge:      edges[0],
+	}}, nil
+}
+
+func TestStoreWatcherCollidingIDs(t *testing.T) {
+	sw := NewStoreWatcher(nil, eventbus.New(), logrus.New(), time.Hour)
+	sw.store = newCollidingFeed(time.Now())
+
+	// Objects of different kinds with the same ID are told apart.
+	got := polled(sw)
+	for _, event := range []EventType{EntityCreated, EdgeCreated, EntityTagCreated, EdgeTagCreated} {
+		if count(got, event) != 1 {
+			t.Errorf("first poll: %d %s events in %v, want 1", count(got, event), event, got)
+		}
+	}
+	if len(got) != 4 {
+		t.Errorf("first poll published %v", got)
+	}
+
+	if got := polled(sw); len(got) != 0 {
+		t.Errorf("second poll published %v", got)
+	}
+}
@@ -0,0 +1,28 @@
+package gateway
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+func registrySchemas[K ~string](registry map[K]reflect.Type) map[string]Schema {
+	schemas := make(map[string]Schema, len(registry))
+	for key, t := range registry {
+		schemas[string(key)] = JSONSchema(t)
+	}
+	return schemas
+}
+
+var typeCatalog = sync.OnceValue(func() []byte {
+	return TypeCatalog{
+		Assets:     registrySchemas(assetTypes),
+		Relations:  registrySchemas(relationTypes),
+		Properties: registrySchemas(propertyTypes),
+	}.JSON()
+})
+
+func (api *ApiV1) GetTypes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(typeCatalog())
+}
@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"github.com/0ppliger/oam-broker/eventbus"
+	"github.com/0ppliger/oam-broker/wire"
+)
+
+// The objects exchanged with clients are defined in the wire package,
+// so that clients can import them.
+
+type (
+	Entity       = wire.Entity
+	Edge         = wire.Edge
+	EntityTag    = wire.EntityTag
+	EdgeTag      = wire.EdgeTag
+	Cascade      = wire.Cascade
+	Subgraph     = wire.Subgraph
+	Path         = wire.Path
+	PathResult   = wire.PathResult
+	Record       = wire.Record
+	Change       = wire.Change
+	EventType    = wire.EventType
+	JobState     = wire.JobState
+	ImportResult = wire.ImportResult
+	Schema       = wire.Schema
+	TypeCatalog  = wire.TypeCatalog
+	Serializable = wire.Serializable
+)
+
+// The event bus lives in the eventbus package, so that it can be shared
+// with the embedding service.
+type (
+	EventBus        = eventbus.EventBus
+	ServerSentEvent = eventbus.ServerSentEvent
+)
+
+var (
+	assetTypes    = wire.AssetTypes
+	relationTypes = wire.RelationTypes
+	propertyTypes = wire.PropertyTypes
+)
+
+var (
+	EntityFromStore    = wire.EntityFromStore
+	EdgeFromStore      = wire.EdgeFromStore
+	EntityTagFromStore = wire.EntityTagFromStore
+	EdgeTagFromStore   = wire.EdgeTagFromStore
+)
+
+const (
+	EntityRecord    = wire.EntityRecord
+	EdgeRecord      = wire.EdgeRecord
+	EntityTagRecord = wire.EntityTagRecord
+	EdgeTagRecord   = wire.EdgeTagRecord
+)
+
+var (
+	EntityCreated = wire.EntityCreated
+	EntityUpdated = wire.EntityUpdated
+	EntityDeleted = wire.EntityDeleted
+)
+
+const (
+	EntityTouched    = wire.EntityTouched
+	EdgeCreated      = wire.EdgeCreated
+	EdgeUpdated      = wire.EdgeUpdated
+	EdgeTouched      = wire.EdgeTouched
+	EdgeDeleted      = wire.EdgeDeleted
+	EntityTagCreated = wire.EntityTagCreated
+	EntityTagUpdated = wire.EntityTagUpdated
+	EntityTagTouched = wire.EntityTagTouched
+	EntityTagDeleted = wire.EntityTagDeleted
+	EdgeTagCreated   = wire.EdgeTagCreated
+	EdgeTagUpdated   = wire.EdgeTagUpdated
+	EdgeTagTouched   = wire.EdgeTagTouched
+	EdgeTagDeleted   = wire.EdgeTagDeleted
+	JobProgress      = wire.JobProgress
+	JobFinished      = wire.JobFinished
+)
+
+const (
+	JobRunning   = wire.JobRunning
+	JobSucceeded = wire.JobSucceeded
+	JobFailed    = wire.JobFailed
+	JobCancelled = wire.JobCancelled
+)
@@ -0,0 +1,165 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// WorkspaceHeader selects the workspace of requests made outside of
+// /workspaces/{name}.
+const WorkspaceHeader = "X-Workspace"
+
+var workspaceName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Workspace is an isolated graph: its own repository, its own events and
+// the API keys allowed to use it.
+type Workspace struct {
+	Name       string
+	Repository Repository
+	// Bus is the bus the events of the workspace are published on. A new
+	// one is created when it is nil.
+	Bus *EventBus
+	// Keys are the API keys accepted, sent as a bearer token or in
+	// X-API-Key.
+	Keys []string
+	// Open serves the workspace to every client, without keys. Only open
+	// workspaces go without keys.
+	Open bool
+}
+
+// withWorkspaceKeys tells the gateway of a workspace its keys, which
+// Workspaces verifies before passing requests on.
+func withWorkspaceKeys(keys keySet) Option {
+	return func(g *Gateway) {
+		g.keys = keys
+	}
+}
+
+type workspace struct {
+	gateway *Gateway
+	grpc    *GrpcServer
+	keys    keySet
+	open    bool
+}
+
+// authorized tells whether the workspace is open, or the API key of a
+// request is one of the keys of the workspace.
+func (ws *workspace) authorized(r *http.Request) bool {
+	return ws.open || ws.keys.has(apiKey(r))
+}
+
+// Workspaces serves many workspaces from one process, each through its
+// own Gateway. A workspace is selected by the path, its routes being
+// /workspaces/{name}/emit/entity and so on, or by the X-Workspace header
+// on the routes of a single gateway. Over gRPC, it is selected by the
+// x-workspace metadata.
+type Workspaces struct {
+	prefix     string
+	workspaces map[string]*workspace
+}
+
+// NewWorkspaces returns the handler of workspaces. The options apply to
+// the gateway of every workspace, limits being enforced per workspace,
+// except WithPrefix, which mounts the workspaces as a whole, and
+// WithRepository and WithEventBus, which Workspace sets.
+func NewWorkspaces(workspaces []Workspace, opts ...Option) (*Workspaces, error) {
+	mount := &Gateway{api: &ApiV1{}, route_body_sizes: make(map[string]int64)}
+	for _, opt := range opts {
+		opt(mount)
+	}
+
+	wss := &Workspaces{
+		prefix:     mount.api.prefix,
+		workspaces: make(map[string]*workspace),
+	}
+	for _, w := range workspaces {
+		if !workspaceName.MatchString(w.Name) {
+			return nil, fmt.Errorf("gateway: invalid workspace name %q", w.Name)
+		}
+		if _, ok := wss.workspaces[w.Name]; ok {
+			return nil, fmt.Errorf("gateway: duplicate workspace %q", w.Name)
+		}
+		if w.Repository == nil {
+			return nil, fmt.Errorf("gateway: no repository for workspace %q", w.Name)
+		}
+		if len(w.Keys) == 0 && !w.Open {
+			return nil, fmt.Errorf("gateway: no keys for workspace %q, which is not open", w.Name)
+		}
+		if len(w.Keys) != 0 && w.Open {
+			return nil, fmt.Errorf("gateway: keys for workspace %q, which is open", w.Name)
+		}
+
+		keys := newKeySet(w.Keys)
+		g, err := New(append(slices.Clip(opts),
+			WithRepository(w.Repository),
+			WithEventBus(w.Bus),
+			WithPrefix(wss.prefix+"/workspaces/"+w.Name),
+			withWorkspaceKeys(keys),
+		)...)
+		if err != nil {
+			return nil, fmt.Errorf("gateway: workspace %q: %w", w.Name, err)
+		}
+
+		wss.workspaces[w.Name] = &workspace{
+			gateway: g,
+			grpc:    &GrpcServer{api: g.api},
+			keys:    keys,
+			open:    w.Open,
+		}
+	}
+	if len(wss.workspaces) == 0 {
+		return nil, errors.New("gateway: no workspace")
+	}
+	return wss, nil
+}
+
+func (wss *Workspaces) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The prefix is a whole segment of the path: /oag/types is under
+	// /oag, /oagx/types is not.
+	path, ok := strings.CutPrefix(r.URL.Path, wss.prefix)
+	if !ok || path != "" && !strings.HasPrefix(path, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	var name string
+	if rest, ok := strings.CutPrefix(path, "/workspaces/"); ok {
+		name, _, _ = strings.Cut(rest, "/")
+	} else if name = r.Header.Get(WorkspaceHeader); name != "" {
+		// The routes of the workspace are served under its path, so
+		// that the URLs the gateway answers with, such as the Location
+		// of jobs, work without the header too.
+		r = r.Clone(r.Context())
+		r.URL.Path = wss.prefix + "/workspaces/" + name + path
+		r.URL.RawPath = ""
+	} else {
+		http.Error(w, "no workspace: use /workspaces/{name} or "+WorkspaceHeader, http.StatusBadRequest)
+		return
+	}
+
+	ws, ok := wss.workspaces[name]
+	if !ok {
+		http.Error(w, "Cannot find workspace: "+name, http.StatusNotFound)
+		return
+	}
+	if !ws.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="`+name+`"`)
+		http.Error(w, "invalid API key for workspace "+name, http.StatusUnauthorized)
+		return
+	}
+
+	ws.gateway.ServeHTTP(w, r)
+}
+
+// Gateway returns the gateway of a workspace, or nil when there is none.
+func (wss *Workspaces) Gateway(name string) *Gateway {
+	ws, ok := wss.workspaces[name]
+	if !ok {
+		return nil
+	}
+	return ws.gateway
+}
@@ -0,0 +1,95 @@
+package gateway
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/0ppliger/oam-broker/gatewaypb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcWorkspace returns the workspace named in the x-workspace metadata
+// of a call, once the API key of the call is checked against it.
+func (wss *Workspaces) grpcWorkspace(ctx context.Context) (*workspace, error) {
+	r := grpcRequest(ctx)
+
+	name := r.Header.Get(WorkspaceHeader)
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "no workspace: use the "+strings.ToLower(WorkspaceHeader)+" metadata")
+	}
+
+	ws, ok := wss.workspaces[name]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "Cannot find workspace: "+name)
+	}
+	if !ws.authorized(r) {
+		return nil, status.Error(codes.Unauthenticated, "invalid API key for workspace "+name)
+	}
+	return ws, nil
+}
+
+// admitCall checks the limits of the workspace, if any, for a call.
+func (ws *workspace) admitCall(ctx context.Context, full_method string) (func(), error) {
+	if ws.gateway.limiter == nil {
+		return func() {}, nil
+	}
+	return ws.gateway.limiter.admitCall(ctx, full_method)
+}
+
+// grpcServer returns the gRPC server of the workspaces. Its methods are
+// those of the Gateway service, passing every call on to the GrpcServer
+// of the workspace it is made to, within the limits of the workspace.
+func (wss *Workspaces) grpcServer(opts ...grpc.ServerOption) *grpc.Server {
+	desc := gatewaypb.Gateway_ServiceDesc
+	desc.Methods = slices.Clone(desc.Methods)
+	desc.Streams = slices.Clone(desc.Streams)
+
+	for i, method := range desc.Methods {
+		full_method := "/" + desc.ServiceName + "/" + method.MethodName
+		desc.Methods[i].Handler = func(_ any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			ws, err := wss.grpcWorkspace(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			release, err := ws.admitCall(ctx, full_method)
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+
+			return method.Handler(ws.grpc, ctx, dec, interceptor)
+		}
+	}
+
+	for i, stream := range desc.Streams {
+		full_method := "/" + desc.ServiceName + "/" + stream.StreamName
+		desc.Streams[i].Handler = func(_ any, server_stream grpc.ServerStream) error {
+			ws, err := wss.grpcWorkspace(server_stream.Context())
+			if err != nil {
+				return err
+			}
+
+			release, err := ws.admitCall(server_stream.Context(), full_method)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			return stream.Handler(ws.grpc, server_stream)
+		}
+	}
+
+	server := grpc.NewServer(opts...)
+	server.RegisterService(&desc, &GrpcServer{})
+	return server
+}
+
+// ServeGRPC serves the gRPC API of the workspaces on addr over TLS until
+// it fails.
+func (wss *Workspaces) ServeGRPC(addr, cert_file, key_file string) error {
+	return serveGRPC(wss.grpcServer, addr, cert_file, key_file)
+}
@@ -0,0 +1,248 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/0ppliger/oam-broker/gatewaypb"
+	"github.com/0ppliger/oam-broker/memory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestWorkspaces(t *testing.T, opts ...Option) string {
+	t.Helper()
+
+	server := httptest.NewServer(testWorkspaces(t, opts...))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func testWorkspaces(t *testing.T, opts ...Option) *Workspaces {
+	t.Helper()
+
+	wss, err := NewWorkspaces([]Workspace{
+		{Name: "acme", Repository: memory.New(), Keys: []string{"acme-key"}},
+		{Name: "globex", Repository: memory.New(), Keys: []string{"globex-key", "globex-ci"}},
+		{Name: "public", Repository: memory.New(), Open: true},
+	}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wss
+}
+
+// request makes a request with the given headers, and returns the
+// response with its body read.
+func request(t *testing.T, method, url, body string, header map[string]string) (*http.Response, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, out
+}
+
+func TestWorkspacesIsolation(t *testing.T) {
+	url := newTestWorkspaces(t)
+	acme := map[string]string{"X-API-Key": "acme-key"}
+
+	resp, body := request(t, "POST", url+"/workspaces/acme/emit/entity", fqdnBody("www.acme.com"), acme)
+	expectStatus(t, resp, http.StatusOK)
+	var created Entity
+	if err := json.Unmarshal(body, &created); err != nil {
+		t.Fatal(err)
+	}
+
+	// The entity is in acme, whether selected by path or by header, and
+	// nowhere else.
+	resp, _ = request(t, "GET", url+"/workspaces/acme/entity/"+created.ID, "", acme)
+	expectStatus(t, resp, http.StatusOK)
+	resp, _ = request(t, "GET", url+"/entity/"+created.ID, "", map[string]string{
+		"Authorization": "Bearer acme-key",
+		WorkspaceHeader: "acme",
+	})
+	expectStatus(t, resp, http.StatusOK)
+	resp, _ = request(t, "GET", url+"/workspaces/globex/entity/"+created.ID, "", map[string]string{"X-API-Key": "globex-ci"})
+	expectStatus(t, resp, http.StatusNotFound)
+	resp, _ = request(t, "GET", url+"/workspaces/public/entity/"+created.ID, "", nil)
+	expectStatus(t, resp, http.StatusNotFound)
+}
+
+func TestWorkspacesCredentials(t *testing.T) {
+	url := newTestWorkspaces(t)
+
+	cases := []struct {
+		name   string
+		path   string
+		header map[string]string
+		status int
+	}{
+		{"no key", "/workspaces/acme/types", nil, http.StatusUnauthorized},
+		{"key of another workspace", "/workspaces/acme/types", map[string]string{"X-API-Key": "globex-key"}, http.StatusUnauthorized},
+		{"key of another workspace by header", "/types", map[string]string{"X-API-Key": "globex-key", WorkspaceHeader: "acme"}, http.StatusUnauthorized},
+		{"second key", "/workspaces/globex/types", map[string]string{"Authorization": "Bearer globex-ci"}, http.StatusOK},
+		{"open workspace", "/workspaces/public/types", nil, http.StatusOK},
+		{"unknown workspace", "/workspaces/initech/types", map[string]string{"X-API-Key": "acme-key"}, http.StatusNotFound},
+		{"unknown workspace by header", "/types", map[string]string{WorkspaceHeader: "../acme"}, http.StatusNotFound},
+		{"no workspace", "/types", map[string]string{"X-API-Key": "acme-key"}, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp, _ := request(t, "GET", url+c.path, "", c.header)
+			expectStatus(t, resp, c.status)
+			if c.status == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+				t.Error("no WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestWorkspacesEvents(t *testing.T) {
+	url := newTestWorkspaces(t, WithPrefix("/oag/"))
+
+	req, err := http.NewRequest("GET", url+"/oag/listen", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(WorkspaceHeader, "globex")
+	req.Header.Set("X-API-Key", "globex-key")
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	expectStatus(t, stream, http.StatusOK)
+
+	// Only the entity emitted in globex is streamed to its subscribers.
+	resp, _ := request(t, "POST", url+"/oag/workspaces/acme/emit/entity", fqdnBody("www.acme.com"), map[string]string{"X-API-Key": "acme-key"})
+	expectStatus(t, resp, http.StatusOK)
+	resp, _ = request(t, "POST", url+"/oag/workspaces/globex/emit/entity", fqdnBody("www.globex.com"), map[string]string{"X-API-Key": "globex-key"})
+	expectStatus(t, resp, http.StatusOK)
+
+	scanner := bufio.NewScanner(stream.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if !strings.Contains(data, "www.globex.com") {
+			t.Fatalf("globex received %s", data)
+		}
+		return
+	}
+	t.Fatal("stream ended without events")
+}
+
+func TestWorkspacesConfiguration(t *testing.T) {
+	// Workspaces go without keys only when they are open.
+	for _, w := range []Workspace{
+		{Name: "acme", Repository: memory.New()},
+		{Name: "acme", Repository: memory.New(), Keys: []string{"acme-key"}, Open: true},
+	} {
+		if _, err := NewWorkspaces([]Workspace{w}); err == nil {
+			t.Errorf("workspace %+v accepted", w)
+		}
+	}
+}
+
+func TestWorkspacesPrefix(t *testing.T) {
+	url := newTestWorkspaces(t, WithPrefix("/oag"))
+
+	resp, _ := request(t, "GET", url+"/oag/workspaces/public/types", "", nil)
+	expectStatus(t, resp, http.StatusOK)
+	resp, _ = request(t, "GET", url+"/oagx/workspaces/public/types", "", nil)
+	expectStatus(t, resp, http.StatusNotFound)
+	resp, _ = request(t, "GET", url+"/oagx/types", "", map[string]string{WorkspaceHeader: "public"})
+	expectStatus(t, resp, http.StatusNotFound)
+}
+
+func TestWorkspacesGrpc(t *testing.T) {
+	wss := testWorkspaces(t, WithLimits(Limits{DailyWrites: 1}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := wss.grpcServer()
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := gatewaypb.NewGatewayClient(conn)
+
+	call := func(pairs ...string) context.Context {
+		return metadata.NewOutgoingContext(context.Background(), metadata.Pairs(pairs...))
+	}
+	acme := call("x-workspace", "acme", "x-api-key", "acme-key")
+
+	created, err := client.CreateEntity(acme, fqdnPB(t, "www.acme.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wss.Gateway("acme").api.store.FindEntityById(context.Background(), created.GetId()); err != nil {
+		t.Errorf("not in acme: %v", err)
+	}
+
+	// The entity is in acme and nowhere else.
+	if _, err := client.GetEntity(acme, &gatewaypb.GetRequest{Id: created.GetId()}); err != nil {
+		t.Error(err)
+	}
+	_, err = client.GetEntity(call("x-workspace", "globex", "authorization", "Bearer globex-ci"), &gatewaypb.GetRequest{Id: created.GetId()})
+	expectCode(t, err, codes.NotFound)
+	_, err = client.GetEntity(call("x-workspace", "public"), &gatewaypb.GetRequest{Id: created.GetId()})
+	expectCode(t, err, codes.NotFound)
+
+	get := &gatewaypb.GetRequest{Id: created.GetId()}
+	_, err = client.GetEntity(call("x-workspace", "acme"), get)
+	expectCode(t, err, codes.Unauthenticated)
+	_, err = client.GetEntity(call("x-workspace", "acme", "x-api-key", "globex-key"), get)
+	expectCode(t, err, codes.Unauthenticated)
+	_, err = client.GetEntity(call("x-workspace", "initech", "x-api-key", "acme-key"), get)
+	expectCode(t, err, codes.NotFound)
+	_, err = client.GetEntity(call("x-api-key", "acme-key"), get)
+	expectCode(t, err, codes.InvalidArgument)
+
+	// Streams are passed on too, and the limits are those of the
+	// workspace.
+	subscription, err := client.Subscribe(acme, &gatewaypb.SubscribeRequest{})
+	if err == nil {
+		_, err = subscription.Header()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.CreateEntity(acme, fqdnPB(t, "app.acme.com"))
+	expectCode(t, err, codes.ResourceExhausted)
+	if _, err := client.CreateEntity(call("x-workspace", "globex", "x-api-key", "globex-key"), fqdnPB(t, "www.globex.com")); err != nil {
+		t.Error(err)
+	}
+}
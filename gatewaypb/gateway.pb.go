@@ -0,0 +1,1737 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: gatewaypb/gateway.proto
+
+// The gRPC API of the Open Asset Gateway. It mirrors the REST API:
+// assets, relations and properties are the JSON objects of their type,
+// as registered in the gateway, carried as Structs.
+
+package gatewaypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Entity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastSeen      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Asset         *structpb.Struct       `protobuf:"bytes,5,opt,name=asset,proto3" json:"asset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entity) Reset() {
+	*x = Entity{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entity) ProtoMessage() {}
+
+func (x *Entity) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entity.ProtoReflect.Descriptor instead.
+func (*Entity) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Entity) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Entity) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Entity) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *Entity) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Entity) GetAsset() *structpb.Struct {
+	if x != nil {
+		return x.Asset
+	}
+	return nil
+}
+
+type Edge struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastSeen      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Relation      *structpb.Struct       `protobuf:"bytes,5,opt,name=relation,proto3" json:"relation,omitempty"`
+	FromEntity    string                 `protobuf:"bytes,6,opt,name=from_entity,json=fromEntity,proto3" json:"from_entity,omitempty"`
+	ToEntity      string                 `protobuf:"bytes,7,opt,name=to_entity,json=toEntity,proto3" json:"to_entity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Edge) Reset() {
+	*x = Edge{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Edge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Edge) ProtoMessage() {}
+
+func (x *Edge) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Edge.ProtoReflect.Descriptor instead.
+func (*Edge) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Edge) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Edge) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Edge) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *Edge) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Edge) GetRelation() *structpb.Struct {
+	if x != nil {
+		return x.Relation
+	}
+	return nil
+}
+
+func (x *Edge) GetFromEntity() string {
+	if x != nil {
+		return x.FromEntity
+	}
+	return ""
+}
+
+func (x *Edge) GetToEntity() string {
+	if x != nil {
+		return x.ToEntity
+	}
+	return ""
+}
+
+type EntityTag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastSeen      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Property      *structpb.Struct       `protobuf:"bytes,5,opt,name=property,proto3" json:"property,omitempty"`
+	Entity        string                 `protobuf:"bytes,6,opt,name=entity,proto3" json:"entity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntityTag) Reset() {
+	*x = EntityTag{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntityTag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntityTag) ProtoMessage() {}
+
+func (x *EntityTag) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntityTag.ProtoReflect.Descriptor instead.
+func (*EntityTag) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EntityTag) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *EntityTag) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *EntityTag) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *EntityTag) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *EntityTag) GetProperty() *structpb.Struct {
+	if x != nil {
+		return x.Property
+	}
+	return nil
+}
+
+func (x *EntityTag) GetEntity() string {
+	if x != nil {
+		return x.Entity
+	}
+	return ""
+}
+
+type EdgeTag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastSeen      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Property      *structpb.Struct       `protobuf:"bytes,5,opt,name=property,proto3" json:"property,omitempty"`
+	Edge          string                 `protobuf:"bytes,6,opt,name=edge,proto3" json:"edge,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EdgeTag) Reset() {
+	*x = EdgeTag{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EdgeTag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EdgeTag) ProtoMessage() {}
+
+func (x *EdgeTag) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EdgeTag.ProtoReflect.Descriptor instead.
+func (*EdgeTag) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *EdgeTag) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *EdgeTag) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *EdgeTag) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *EdgeTag) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *EdgeTag) GetProperty() *structpb.Struct {
+	if x != nil {
+		return x.Property
+	}
+	return nil
+}
+
+func (x *EdgeTag) GetEdge() string {
+	if x != nil {
+		return x.Edge
+	}
+	return ""
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	IfMatch string                 `protobuf:"bytes,2,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	// cascade deletes the edges and tags of an entity along with it.
+	Cascade       bool `protobuf:"varint,3,opt,name=cascade,proto3" json:"cascade,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeleteRequest) GetIfMatch() string {
+	if x != nil {
+		return x.IfMatch
+	}
+	return ""
+}
+
+func (x *DeleteRequest) GetCascade() bool {
+	if x != nil {
+		return x.Cascade
+	}
+	return false
+}
+
+// PatchRequest patches an object like the PATCH routes of the REST API.
+type PatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// patch is a JSON Merge Patch object, or a JSON Patch array.
+	Patch         *structpb.Value `protobuf:"bytes,2,opt,name=patch,proto3" json:"patch,omitempty"`
+	IfMatch       string          `protobuf:"bytes,3,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PatchRequest) Reset() {
+	*x = PatchRequest{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatchRequest) ProtoMessage() {}
+
+func (x *PatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatchRequest.ProtoReflect.Descriptor instead.
+func (*PatchRequest) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PatchRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PatchRequest) GetPatch() *structpb.Value {
+	if x != nil {
+		return x.Patch
+	}
+	return nil
+}
+
+func (x *PatchRequest) GetIfMatch() string {
+	if x != nil {
+		return x.IfMatch
+	}
+	return ""
+}
+
+type UpdateEntityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entity        *Entity                `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	IfMatch       string                 `protobuf:"bytes,2,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateEntityRequest) Reset() {
+	*x = UpdateEntityRequest{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateEntityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateEntityRequest) ProtoMessage() {}
+
+func (x *UpdateEntityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateEntityRequest.ProtoReflect.Descriptor instead.
+func (*UpdateEntityRequest) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateEntityRequest) GetEntity() *Entity {
+	if x != nil {
+		return x.Entity
+	}
+	return nil
+}
+
+func (x *UpdateEntityRequest) GetIfMatch() string {
+	if x != nil {
+		return x.IfMatch
+	}
+	return ""
+}
+
+type UpdateEdgeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Edge          *Edge                  `protobuf:"bytes,1,opt,name=edge,proto3" json:"edge,omitempty"`
+	IfMatch       string                 `protobuf:"bytes,2,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateEdgeRequest) Reset() {
+	*x = UpdateEdgeRequest{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateEdgeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateEdgeRequest) ProtoMessage() {}
+
+func (x *UpdateEdgeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateEdgeRequest.ProtoReflect.Descriptor instead.
+func (*UpdateEdgeRequest) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateEdgeRequest) GetEdge() *Edge {
+	if x != nil {
+		return x.Edge
+	}
+	return nil
+}
+
+func (x *UpdateEdgeRequest) GetIfMatch() string {
+	if x != nil {
+		return x.IfMatch
+	}
+	return ""
+}
+
+type UpdateEntityTagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntityTag     *EntityTag             `protobuf:"bytes,1,opt,name=entity_tag,json=entityTag,proto3" json:"entity_tag,omitempty"`
+	IfMatch       string                 `protobuf:"bytes,2,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateEntityTagRequest) Reset() {
+	*x = UpdateEntityTagRequest{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateEntityTagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateEntityTagRequest) ProtoMessage() {}
+
+func (x *UpdateEntityTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateEntityTagRequest.ProtoReflect.Descriptor instead.
+func (*UpdateEntityTagRequest) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateEntityTagRequest) GetEntityTag() *EntityTag {
+	if x != nil {
+		return x.EntityTag
+	}
+	return nil
+}
+
+func (x *UpdateEntityTagRequest) GetIfMatch() string {
+	if x != nil {
+		return x.IfMatch
+	}
+	return ""
+}
+
+type UpdateEdgeTagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EdgeTag       *EdgeTag               `protobuf:"bytes,1,opt,name=edge_tag,json=edgeTag,proto3" json:"edge_tag,omitempty"`
+	IfMatch       string                 `protobuf:"bytes,2,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateEdgeTagRequest) Reset() {
+	*x = UpdateEdgeTagRequest{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateEdgeTagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateEdgeTagRequest) ProtoMessage() {}
+
+func (x *UpdateEdgeTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateEdgeTagRequest.ProtoReflect.Descriptor instead.
+func (*UpdateEdgeTagRequest) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateEdgeTagRequest) GetEdgeTag() *EdgeTag {
+	if x != nil {
+		return x.EdgeTag
+	}
+	return nil
+}
+
+func (x *UpdateEdgeTagRequest) GetIfMatch() string {
+	if x != nil {
+		return x.IfMatch
+	}
+	return ""
+}
+
+type FindEntitiesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Since         *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=since,proto3" json:"since,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindEntitiesRequest) Reset() {
+	*x = FindEntitiesRequest{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindEntitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindEntitiesRequest) ProtoMessage() {}
+
+func (x *FindEntitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindEntitiesRequest.ProtoReflect.Descriptor instead.
+func (*FindEntitiesRequest) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FindEntitiesRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *FindEntitiesRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *FindEntitiesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GraphRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Root          string                 `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	Depth         int32                  `protobuf:"varint,2,opt,name=depth,proto3" json:"depth,omitempty"`
+	Relations     []string               `protobuf:"bytes,3,rep,name=relations,proto3" json:"relations,omitempty"`
+	AssetTypes    []string               `protobuf:"bytes,4,rep,name=asset_types,json=assetTypes,proto3" json:"asset_types,omitempty"`
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	Tags          bool                   `protobuf:"varint,6,opt,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GraphRequest) Reset() {
+	*x = GraphRequest{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GraphRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GraphRequest) ProtoMessage() {}
+
+func (x *GraphRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GraphRequest.ProtoReflect.Descriptor instead.
+func (*GraphRequest) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GraphRequest) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *GraphRequest) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *GraphRequest) GetRelations() []string {
+	if x != nil {
+		return x.Relations
+	}
+	return nil
+}
+
+func (x *GraphRequest) GetAssetTypes() []string {
+	if x != nil {
+		return x.AssetTypes
+	}
+	return nil
+}
+
+func (x *GraphRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GraphRequest) GetTags() bool {
+	if x != nil {
+		return x.Tags
+	}
+	return false
+}
+
+type Subgraph struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Root          string                 `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	Entities      []*Entity              `protobuf:"bytes,2,rep,name=entities,proto3" json:"entities,omitempty"`
+	Edges         []*Edge                `protobuf:"bytes,3,rep,name=edges,proto3" json:"edges,omitempty"`
+	EntityTags    []*EntityTag           `protobuf:"bytes,4,rep,name=entity_tags,json=entityTags,proto3" json:"entity_tags,omitempty"`
+	EdgeTags      []*EdgeTag             `protobuf:"bytes,5,rep,name=edge_tags,json=edgeTags,proto3" json:"edge_tags,omitempty"`
+	Truncated     bool                   `protobuf:"varint,6,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Subgraph) Reset() {
+	*x = Subgraph{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Subgraph) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Subgraph) ProtoMessage() {}
+
+func (x *Subgraph) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Subgraph.ProtoReflect.Descriptor instead.
+func (*Subgraph) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Subgraph) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *Subgraph) GetEntities() []*Entity {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+func (x *Subgraph) GetEdges() []*Edge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+func (x *Subgraph) GetEntityTags() []*EntityTag {
+	if x != nil {
+		return x.EntityTags
+	}
+	return nil
+}
+
+func (x *Subgraph) GetEdgeTags() []*EdgeTag {
+	if x != nil {
+		return x.EdgeTags
+	}
+	return nil
+}
+
+func (x *Subgraph) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type PathRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Relations     []string               `protobuf:"bytes,3,rep,name=relations,proto3" json:"relations,omitempty"`
+	MaxDepth      int32                  `protobuf:"varint,4,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PathRequest) Reset() {
+	*x = PathRequest{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PathRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathRequest) ProtoMessage() {}
+
+func (x *PathRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PathRequest.ProtoReflect.Descriptor instead.
+func (*PathRequest) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PathRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *PathRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *PathRequest) GetRelations() []string {
+	if x != nil {
+		return x.Relations
+	}
+	return nil
+}
+
+func (x *PathRequest) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *PathRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Path is a sequence of entities where edges[i] links entities[i] and
+// entities[i+1].
+type Path struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entities      []*Entity              `protobuf:"bytes,1,rep,name=entities,proto3" json:"entities,omitempty"`
+	Edges         []*Edge                `protobuf:"bytes,2,rep,name=edges,proto3" json:"edges,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Path) Reset() {
+	*x = Path{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Path) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Path) ProtoMessage() {}
+
+func (x *Path) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Path.ProtoReflect.Descriptor instead.
+func (*Path) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Path) GetEntities() []*Entity {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+func (x *Path) GetEdges() []*Edge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+type PathResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Paths         []*Path                `protobuf:"bytes,1,rep,name=paths,proto3" json:"paths,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PathResponse) Reset() {
+	*x = PathResponse{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PathResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathResponse) ProtoMessage() {}
+
+func (x *PathResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PathResponse.ProtoReflect.Descriptor instead.
+func (*PathResponse) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *PathResponse) GetPaths() []*Path {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+type SubscribeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// types restricts the events to these types, all when empty.
+	Types         []string `protobuf:"bytes,1,rep,name=types,proto3" json:"types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SubscribeRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Data          *structpb.Struct       `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type IngestRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Object:
+	//
+	//	*IngestRequest_Entity
+	//	*IngestRequest_Edge
+	//	*IngestRequest_EntityTag
+	//	*IngestRequest_EdgeTag
+	Object        isIngestRequest_Object `protobuf_oneof:"object"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestRequest) Reset() {
+	*x = IngestRequest{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestRequest) ProtoMessage() {}
+
+func (x *IngestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestRequest.ProtoReflect.Descriptor instead.
+func (*IngestRequest) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *IngestRequest) GetObject() isIngestRequest_Object {
+	if x != nil {
+		return x.Object
+	}
+	return nil
+}
+
+func (x *IngestRequest) GetEntity() *Entity {
+	if x != nil {
+		if x, ok := x.Object.(*IngestRequest_Entity); ok {
+			return x.Entity
+		}
+	}
+	return nil
+}
+
+func (x *IngestRequest) GetEdge() *Edge {
+	if x != nil {
+		if x, ok := x.Object.(*IngestRequest_Edge); ok {
+			return x.Edge
+		}
+	}
+	return nil
+}
+
+func (x *IngestRequest) GetEntityTag() *EntityTag {
+	if x != nil {
+		if x, ok := x.Object.(*IngestRequest_EntityTag); ok {
+			return x.EntityTag
+		}
+	}
+	return nil
+}
+
+func (x *IngestRequest) GetEdgeTag() *EdgeTag {
+	if x != nil {
+		if x, ok := x.Object.(*IngestRequest_EdgeTag); ok {
+			return x.EdgeTag
+		}
+	}
+	return nil
+}
+
+type isIngestRequest_Object interface {
+	isIngestRequest_Object()
+}
+
+type IngestRequest_Entity struct {
+	Entity *Entity `protobuf:"bytes,1,opt,name=entity,proto3,oneof"`
+}
+
+type IngestRequest_Edge struct {
+	Edge *Edge `protobuf:"bytes,2,opt,name=edge,proto3,oneof"`
+}
+
+type IngestRequest_EntityTag struct {
+	EntityTag *EntityTag `protobuf:"bytes,3,opt,name=entity_tag,json=entityTag,proto3,oneof"`
+}
+
+type IngestRequest_EdgeTag struct {
+	EdgeTag *EdgeTag `protobuf:"bytes,4,opt,name=edge_tag,json=edgeTag,proto3,oneof"`
+}
+
+func (*IngestRequest_Entity) isIngestRequest_Object() {}
+
+func (*IngestRequest_Edge) isIngestRequest_Object() {}
+
+func (*IngestRequest_EntityTag) isIngestRequest_Object() {}
+
+func (*IngestRequest_EdgeTag) isIngestRequest_Object() {}
+
+type IngestResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Created       int32                  `protobuf:"varint,1,opt,name=created,proto3" json:"created,omitempty"`
+	Updated       int32                  `protobuf:"varint,2,opt,name=updated,proto3" json:"updated,omitempty"`
+	Skipped       int32                  `protobuf:"varint,3,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Rejected      int32                  `protobuf:"varint,4,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	Errors        []string               `protobuf:"bytes,5,rep,name=errors,proto3" json:"errors,omitempty"`
+	JobId         string                 `protobuf:"bytes,6,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestResponse) Reset() {
+	*x = IngestResponse{}
+	mi := &file_gatewaypb_gateway_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestResponse) ProtoMessage() {}
+
+func (x *IngestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gatewaypb_gateway_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestResponse.ProtoReflect.Descriptor instead.
+func (*IngestResponse) Descriptor() ([]byte, []int) {
+	return file_gatewaypb_gateway_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *IngestResponse) GetCreated() int32 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *IngestResponse) GetUpdated() int32 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *IngestResponse) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *IngestResponse) GetRejected() int32 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *IngestResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *IngestResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+var File_gatewaypb_gateway_proto protoreflect.FileDescriptor
+
+const file_gatewaypb_gateway_proto_rawDesc = "" +
+	"\n" +
+	"\x17gatewaypb/gateway.proto\x12\n" +
+	"gateway.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xcf\x01\n" +
+	"\x06Entity\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x127\n" +
+	"\tlast_seen\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12-\n" +
+	"\x05asset\x18\x05 \x01(\v2\x17.google.protobuf.StructR\x05asset\"\x91\x02\n" +
+	"\x04Edge\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x127\n" +
+	"\tlast_seen\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x123\n" +
+	"\brelation\x18\x05 \x01(\v2\x17.google.protobuf.StructR\brelation\x12\x1f\n" +
+	"\vfrom_entity\x18\x06 \x01(\tR\n" +
+	"fromEntity\x12\x1b\n" +
+	"\tto_entity\x18\a \x01(\tR\btoEntity\"\xf0\x01\n" +
+	"\tEntityTag\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x127\n" +
+	"\tlast_seen\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x123\n" +
+	"\bproperty\x18\x05 \x01(\v2\x17.google.protobuf.StructR\bproperty\x12\x16\n" +
+	"\x06entity\x18\x06 \x01(\tR\x06entity\"\xea\x01\n" +
+	"\aEdgeTag\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x127\n" +
+	"\tlast_seen\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x123\n" +
+	"\bproperty\x18\x05 \x01(\v2\x17.google.protobuf.StructR\bproperty\x12\x12\n" +
+	"\x04edge\x18\x06 \x01(\tR\x04edge\"\x1c\n" +
+	"\n" +
+	"GetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"T\n" +
+	"\rDeleteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bif_match\x18\x02 \x01(\tR\aifMatch\x12\x18\n" +
+	"\acascade\x18\x03 \x01(\bR\acascade\"g\n" +
+	"\fPatchRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12,\n" +
+	"\x05patch\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x05patch\x12\x19\n" +
+	"\bif_match\x18\x03 \x01(\tR\aifMatch\"\\\n" +
+	"\x13UpdateEntityRequest\x12*\n" +
+	"\x06entity\x18\x01 \x01(\v2\x12.gateway.v1.EntityR\x06entity\x12\x19\n" +
+	"\bif_match\x18\x02 \x01(\tR\aifMatch\"T\n" +
+	"\x11UpdateEdgeRequest\x12$\n" +
+	"\x04edge\x18\x01 \x01(\v2\x10.gateway.v1.EdgeR\x04edge\x12\x19\n" +
+	"\bif_match\x18\x02 \x01(\tR\aifMatch\"i\n" +
+	"\x16UpdateEntityTagRequest\x124\n" +
+	"\n" +
+	"entity_tag\x18\x01 \x01(\v2\x15.gateway.v1.EntityTagR\tentityTag\x12\x19\n" +
+	"\bif_match\x18\x02 \x01(\tR\aifMatch\"a\n" +
+	"\x14UpdateEdgeTagRequest\x12.\n" +
+	"\bedge_tag\x18\x01 \x01(\v2\x13.gateway.v1.EdgeTagR\aedgeTag\x12\x19\n" +
+	"\bif_match\x18\x02 \x01(\tR\aifMatch\"q\n" +
+	"\x13FindEntitiesRequest\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x120\n" +
+	"\x05since\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"\xa1\x01\n" +
+	"\fGraphRequest\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\x12\x14\n" +
+	"\x05depth\x18\x02 \x01(\x05R\x05depth\x12\x1c\n" +
+	"\trelations\x18\x03 \x03(\tR\trelations\x12\x1f\n" +
+	"\vasset_types\x18\x04 \x03(\tR\n" +
+	"assetTypes\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x12\n" +
+	"\x04tags\x18\x06 \x01(\bR\x04tags\"\xfe\x01\n" +
+	"\bSubgraph\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\x12.\n" +
+	"\bentities\x18\x02 \x03(\v2\x12.gateway.v1.EntityR\bentities\x12&\n" +
+	"\x05edges\x18\x03 \x03(\v2\x10.gateway.v1.EdgeR\x05edges\x126\n" +
+	"\ventity_tags\x18\x04 \x03(\v2\x15.gateway.v1.EntityTagR\n" +
+	"entityTags\x120\n" +
+	"\tedge_tags\x18\x05 \x03(\v2\x13.gateway.v1.EdgeTagR\bedgeTags\x12\x1c\n" +
+	"\ttruncated\x18\x06 \x01(\bR\ttruncated\"\x82\x01\n" +
+	"\vPathRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x1c\n" +
+	"\trelations\x18\x03 \x03(\tR\trelations\x12\x1b\n" +
+	"\tmax_depth\x18\x04 \x01(\x05R\bmaxDepth\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\"^\n" +
+	"\x04Path\x12.\n" +
+	"\bentities\x18\x01 \x03(\v2\x12.gateway.v1.EntityR\bentities\x12&\n" +
+	"\x05edges\x18\x02 \x03(\v2\x10.gateway.v1.EdgeR\x05edges\"6\n" +
+	"\fPathResponse\x12&\n" +
+	"\x05paths\x18\x01 \x03(\v2\x10.gateway.v1.PathR\x05paths\"(\n" +
+	"\x10SubscribeRequest\x12\x14\n" +
+	"\x05types\x18\x01 \x03(\tR\x05types\"H\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12+\n" +
+	"\x04data\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x04data\"\xd9\x01\n" +
+	"\rIngestRequest\x12,\n" +
+	"\x06entity\x18\x01 \x01(\v2\x12.gateway.v1.EntityH\x00R\x06entity\x12&\n" +
+	"\x04edge\x18\x02 \x01(\v2\x10.gateway.v1.EdgeH\x00R\x04edge\x126\n" +
+	"\n" +
+	"entity_tag\x18\x03 \x01(\v2\x15.gateway.v1.EntityTagH\x00R\tentityTag\x120\n" +
+	"\bedge_tag\x18\x04 \x01(\v2\x13.gateway.v1.EdgeTagH\x00R\aedgeTagB\b\n" +
+	"\x06object\"\xa9\x01\n" +
+	"\x0eIngestResponse\x12\x18\n" +
+	"\acreated\x18\x01 \x01(\x05R\acreated\x12\x18\n" +
+	"\aupdated\x18\x02 \x01(\x05R\aupdated\x12\x18\n" +
+	"\askipped\x18\x03 \x01(\x05R\askipped\x12\x1a\n" +
+	"\brejected\x18\x04 \x01(\x05R\brejected\x12\x16\n" +
+	"\x06errors\x18\x05 \x03(\tR\x06errors\x12\x15\n" +
+	"\x06job_id\x18\x06 \x01(\tR\x05jobId2\xaf\f\n" +
+	"\aGateway\x126\n" +
+	"\fCreateEntity\x12\x12.gateway.v1.Entity\x1a\x12.gateway.v1.Entity\x127\n" +
+	"\tGetEntity\x12\x16.gateway.v1.GetRequest\x1a\x12.gateway.v1.Entity\x12C\n" +
+	"\fUpdateEntity\x12\x1f.gateway.v1.UpdateEntityRequest\x1a\x12.gateway.v1.Entity\x12;\n" +
+	"\vPatchEntity\x12\x18.gateway.v1.PatchRequest\x1a\x12.gateway.v1.Entity\x12=\n" +
+	"\fDeleteEntity\x12\x19.gateway.v1.DeleteRequest\x1a\x12.gateway.v1.Entity\x120\n" +
+	"\n" +
+	"CreateEdge\x12\x10.gateway.v1.Edge\x1a\x10.gateway.v1.Edge\x123\n" +
+	"\aGetEdge\x12\x16.gateway.v1.GetRequest\x1a\x10.gateway.v1.Edge\x12=\n" +
+	"\n" +
+	"UpdateEdge\x12\x1d.gateway.v1.UpdateEdgeRequest\x1a\x10.gateway.v1.Edge\x127\n" +
+	"\tPatchEdge\x12\x18.gateway.v1.PatchRequest\x1a\x10.gateway.v1.Edge\x129\n" +
+	"\n" +
+	"DeleteEdge\x12\x19.gateway.v1.DeleteRequest\x1a\x10.gateway.v1.Edge\x12?\n" +
+	"\x0fCreateEntityTag\x12\x15.gateway.v1.EntityTag\x1a\x15.gateway.v1.EntityTag\x12=\n" +
+	"\fGetEntityTag\x12\x16.gateway.v1.GetRequest\x1a\x15.gateway.v1.EntityTag\x12L\n" +
+	"\x0fUpdateEntityTag\x12\".gateway.v1.UpdateEntityTagRequest\x1a\x15.gateway.v1.EntityTag\x12A\n" +
+	"\x0ePatchEntityTag\x12\x18.gateway.v1.PatchRequest\x1a\x15.gateway.v1.EntityTag\x12C\n" +
+	"\x0fDeleteEntityTag\x12\x19.gateway.v1.DeleteRequest\x1a\x15.gateway.v1.EntityTag\x129\n" +
+	"\rCreateEdgeTag\x12\x13.gateway.v1.EdgeTag\x1a\x13.gateway.v1.EdgeTag\x129\n" +
+	"\n" +
+	"GetEdgeTag\x12\x16.gateway.v1.GetRequest\x1a\x13.gateway.v1.EdgeTag\x12F\n" +
+	"\rUpdateEdgeTag\x12 .gateway.v1.UpdateEdgeTagRequest\x1a\x13.gateway.v1.EdgeTag\x12=\n" +
+	"\fPatchEdgeTag\x12\x18.gateway.v1.PatchRequest\x1a\x13.gateway.v1.EdgeTag\x12?\n" +
+	"\rDeleteEdgeTag\x12\x19.gateway.v1.DeleteRequest\x1a\x13.gateway.v1.EdgeTag\x12E\n" +
+	"\fFindEntities\x12\x1f.gateway.v1.FindEntitiesRequest\x1a\x12.gateway.v1.Entity0\x01\x12:\n" +
+	"\bGetGraph\x12\x18.gateway.v1.GraphRequest\x1a\x14.gateway.v1.Subgraph\x12>\n" +
+	"\tFindPaths\x12\x17.gateway.v1.PathRequest\x1a\x18.gateway.v1.PathResponse\x12>\n" +
+	"\tSubscribe\x12\x1c.gateway.v1.SubscribeRequest\x1a\x11.gateway.v1.Event0\x01\x12A\n" +
+	"\x06Ingest\x12\x19.gateway.v1.IngestRequest\x1a\x1a.gateway.v1.IngestResponse(\x01B*Z(github.com/0ppliger/oam-broker/gatewaypbb\x06proto3"
+
+var (
+	file_gatewaypb_gateway_proto_rawDescOnce sync.Once
+	file_gatewaypb_gateway_proto_rawDescData []byte
+)
+
+func file_gatewaypb_gateway_proto_rawDescGZIP() []byte {
+	file_gatewaypb_gateway_proto_rawDescOnce.Do(func() {
+		file_gatewaypb_gateway_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_gatewaypb_gateway_proto_rawDesc), len(file_gatewaypb_gateway_proto_rawDesc)))
+	})
+	return file_gatewaypb_gateway_proto_rawDescData
+}
+
+var file_gatewaypb_gateway_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_gatewaypb_gateway_proto_goTypes = []any{
+	(*Entity)(nil),                 // 0: gateway.v1.Entity
+	(*Edge)(nil),                   // 1: gateway.v1.Edge
+	(*EntityTag)(nil),              // 2: gateway.v1.EntityTag
+	(*EdgeTag)(nil),                // 3: gateway.v1.EdgeTag
+	(*GetRequest)(nil),             // 4: gateway.v1.GetRequest
+	(*DeleteRequest)(nil),          // 5: gateway.v1.DeleteRequest
+	(*PatchRequest)(nil),           // 6: gateway.v1.PatchRequest
+	(*UpdateEntityRequest)(nil),    // 7: gateway.v1.UpdateEntityRequest
+	(*UpdateEdgeRequest)(nil),      // 8: gateway.v1.UpdateEdgeRequest
+	(*UpdateEntityTagRequest)(nil), // 9: gateway.v1.UpdateEntityTagRequest
+	(*UpdateEdgeTagRequest)(nil),   // 10: gateway.v1.UpdateEdgeTagRequest
+	(*FindEntitiesRequest)(nil),    // 11: gateway.v1.FindEntitiesRequest
+	(*GraphRequest)(nil),           // 12: gateway.v1.GraphRequest
+	(*Subgraph)(nil),               // 13: gateway.v1.Subgraph
+	(*PathRequest)(nil),            // 14: gateway.v1.PathRequest
+	(*Path)(nil),                   // 15: gateway.v1.Path
+	(*PathResponse)(nil),           // 16: gateway.v1.PathResponse
+	(*SubscribeRequest)(nil),       // 17: gateway.v1.SubscribeRequest
+	(*Event)(nil),                  // 18: gateway.v1.Event
+	(*IngestRequest)(nil),          // 19: gateway.v1.IngestRequest
+	(*IngestResponse)(nil),         // 20: gateway.v1.IngestResponse
+	(*timestamppb.Timestamp)(nil),  // 21: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),        // 22: google.protobuf.Struct
+	(*structpb.Value)(nil),         // 23: google.protobuf.Value
+}
+var file_gatewaypb_gateway_proto_depIdxs = []int32{
+	21, // 0: gateway.v1.Entity.created_at:type_name -> google.protobuf.Timestamp
+	21, // 1: gateway.v1.Entity.last_seen:type_name -> google.protobuf.Timestamp
+	22, // 2: gateway.v1.Entity.asset:type_name -> google.protobuf.Struct
+	21, // 3: gateway.v1.Edge.created_at:type_name -> google.protobuf.Timestamp
+	21, // 4: gateway.v1.Edge.last_seen:type_name -> google.protobuf.Timestamp
+	22, // 5: gateway.v1.Edge.relation:type_name -> google.protobuf.Struct
+	21, // 6: gateway.v1.EntityTag.created_at:type_name -> google.protobuf.Timestamp
+	21, // 7: gateway.v1.EntityTag.last_seen:type_name -> google.protobuf.Timestamp
+	22, // 8: gateway.v1.EntityTag.property:type_name -> google.protobuf.Struct
+	21, // 9: gateway.v1.EdgeTag.created_at:type_name -> google.protobuf.Timestamp
+	21, // 10: gateway.v1.EdgeTag.last_seen:type_name -> google.protobuf.Timestamp
+	22, // 11: gateway.v1.EdgeTag.property:type_name -> google.protobuf.Struct
+	23, // 12: gateway.v1.PatchRequest.patch:type_name -> google.protobuf.Value
+	0,  // 13: gateway.v1.UpdateEntityRequest.entity:type_name -> gateway.v1.Entity
+	1,  // 14: gateway.v1.UpdateEdgeRequest.edge:type_name -> gateway.v1.Edge
+	2,  // 15: gateway.v1.UpdateEntityTagRequest.entity_tag:type_name -> gateway.v1.EntityTag
+	3,  // 16: gateway.v1.UpdateEdgeTagRequest.edge_tag:type_name -> gateway.v1.EdgeTag
+	21, // 17: gateway.v1.FindEntitiesRequest.since:type_name -> google.protobuf.Timestamp
+	0,  // 18: gateway.v1.Subgraph.entities:type_name -> gateway.v1.Entity
+	1,  // 19: gateway.v1.Subgraph.edges:type_name -> gateway.v1.Edge
+	2,  // 20: gateway.v1.Subgraph.entity_tags:type_name -> gateway.v1.EntityTag
+	3,  // 21: gateway.v1.Subgraph.edge_tags:type_name -> gateway.v1.EdgeTag
+	0,  // 22: gateway.v1.Path.entities:type_name -> gateway.v1.Entity
+	1,  // 23: gateway.v1.Path.edges:type_name -> gateway.v1.Edge
+	15, // 24: gateway.v1.PathResponse.paths:type_name -> gateway.v1.Path
+	22, // 25: gateway.v1.Event.data:type_name -> google.protobuf.Struct
+	0,  // 26: gateway.v1.IngestRequest.entity:type_name -> gateway.v1.Entity
+	1,  // 27: gateway.v1.IngestRequest.edge:type_name -> gateway.v1.Edge
+	2,  // 28: gateway.v1.IngestRequest.entity_tag:type_name -> gateway.v1.EntityTag
+	3,  // 29: gateway.v1.IngestRequest.edge_tag:type_name -> gateway.v1.EdgeTag
+	0,  // 30: gateway.v1.Gateway.CreateEntity:input_type -> gateway.v1.Entity
+	4,  // 31: gateway.v1.Gateway.GetEntity:input_type -> gateway.v1.GetRequest
+	7,  // 32: gateway.v1.Gateway.UpdateEntity:input_type -> gateway.v1.UpdateEntityRequest
+	6,  // 33: gateway.v1.Gateway.PatchEntity:input_type -> gateway.v1.PatchRequest
+	5,  // 34: gateway.v1.Gateway.DeleteEntity:input_type -> gateway.v1.DeleteRequest
+	1,  // 35: gateway.v1.Gateway.CreateEdge:input_type -> gateway.v1.Edge
+	4,  // 36: gateway.v1.Gateway.GetEdge:input_type -> gateway.v1.GetRequest
+	8,  // 37: gateway.v1.Gateway.UpdateEdge:input_type -> gateway.v1.UpdateEdgeRequest
+	6,  // 38: gateway.v1.Gateway.PatchEdge:input_type -> gateway.v1.PatchRequest
+	5,  // 39: gateway.v1.Gateway.DeleteEdge:input_type -> gateway.v1.DeleteRequest
+	2,  // 40: gateway.v1.Gateway.CreateEntityTag:input_type -> gateway.v1.EntityTag
+	4,  // 41: gateway.v1.Gateway.GetEntityTag:input_type -> gateway.v1.GetRequest
+	9,  // 42: gateway.v1.Gateway.UpdateEntityTag:input_type -> gateway.v1.UpdateEntityTagRequest
+	6,  // 43: gateway.v1.Gateway.PatchEntityTag:input_type -> gateway.v1.PatchRequest
+	5,  // 44: gateway.v1.Gateway.DeleteEntityTag:input_type -> gateway.v1.DeleteRequest
+	3,  // 45: gateway.v1.Gateway.CreateEdgeTag:input_type -> gateway.v1.EdgeTag
+	4,  // 46: gateway.v1.Gateway.GetEdgeTag:input_type -> gateway.v1.GetRequest
+	10, // 47: gateway.v1.Gateway.UpdateEdgeTag:input_type -> gateway.v1.UpdateEdgeTagRequest
+	6,  // 48: gateway.v1.Gateway.PatchEdgeTag:input_type -> gateway.v1.PatchRequest
+	5,  // 49: gateway.v1.Gateway.DeleteEdgeTag:input_type -> gateway.v1.DeleteRequest
+	11, // 50: gateway.v1.Gateway.FindEntities:input_type -> gateway.v1.FindEntitiesRequest
+	12, // 51: gateway.v1.Gateway.GetGraph:input_type -> gateway.v1.GraphRequest
+	14, // 52: gateway.v1.Gateway.FindPaths:input_type -> gateway.v1.PathRequest
+	17, // 53: gateway.v1.Gateway.Subscribe:input_type -> gateway.v1.SubscribeRequest
+	19, // 54: gateway.v1.Gateway.Ingest:input_type -> gateway.v1.IngestRequest
+	0,  // 55: gateway.v1.Gateway.CreateEntity:output_type -> gateway.v1.Entity
+	0,  // 56: gateway.v1.Gateway.GetEntity:output_type -> gateway.v1.Entity
+	0,  // 57: gateway.v1.Gateway.UpdateEntity:output_type -> gateway.v1.Entity
+	0,  // 58: gateway.v1.Gateway.PatchEntity:output_type -> gateway.v1.Entity
+	0,  // 59: gateway.v1.Gateway.DeleteEntity:output_type -> gateway.v1.Entity
+	1,  // 60: gateway.v1.Gateway.CreateEdge:output_type -> gateway.v1.Edge
+	1,  // 61: gateway.v1.Gateway.GetEdge:output_type -> gateway.v1.Edge
+	1,  // 62: gateway.v1.Gateway.UpdateEdge:output_type -> gateway.v1.Edge
+	1,  // 63: gateway.v1.Gateway.PatchEdge:output_type -> gateway.v1.Edge
+	1,  // 64: gateway.v1.Gateway.DeleteEdge:output_type -> gateway.v1.Edge
+	2,  // 65: gateway.v1.Gateway.CreateEntityTag:output_type -> gateway.v1.EntityTag
+	2,  // 66: gateway.v1.Gateway.GetEntityTag:output_type -> gateway.v1.EntityTag
+	2,  // 67: gateway.v1.Gateway.UpdateEntityTag:output_type -> gateway.v1.EntityTag
+	2,  // 68: gateway.v1.Gateway.PatchEntityTag:output_type -> gateway.v1.EntityTag
+	2,  // 69: gateway.v1.Gateway.DeleteEntityTag:output_type -> gateway.v1.EntityTag
+	3,  // 70: gateway.v1.Gateway.CreateEdgeTag:output_type -> gateway.v1.EdgeTag
+	3,  // 71: gateway.v1.Gateway.GetEdgeTag:output_type -> gateway.v1.EdgeTag
+	3,  // 72: gateway.v1.Gateway.UpdateEdgeTag:output_type -> gateway.v1.EdgeTag
+	3,  // 73: gateway.v1.Gateway.PatchEdgeTag:output_type -> gateway.v1.EdgeTag
+	3,  // 74: gateway.v1.Gateway.DeleteEdgeTag:output_type -> gateway.v1.EdgeTag
+	0,  // 75: gateway.v1.Gateway.FindEntities:output_type -> gateway.v1.Entity
+	13, // 76: gateway.v1.Gateway.GetGraph:output_type -> gateway.v1.Subgraph
+	16, // 77: gateway.v1.Gateway.FindPaths:output_type -> gateway.v1.PathResponse
+	18, // 78: gateway.v1.Gateway.Subscribe:output_type -> gateway.v1.Event
+	20, // 79: gateway.v1.Gateway.Ingest:output_type -> gateway.v1.IngestResponse
+	55, // [55:80] is the sub-list for method output_type
+	30, // [30:55] is the sub-list for method input_type
+	30, // [30:30] is the sub-list for extension type_name
+	30, // [30:30] is the sub-list for extension extendee
+	0,  // [0:30] is the sub-list for field type_name
+}
+
+func init() { file_gatewaypb_gateway_proto_init() }
+func file_gatewaypb_gateway_proto_init() {
+	if File_gatewaypb_gateway_proto != nil {
+		return
+	}
+	file_gatewaypb_gateway_proto_msgTypes[19].OneofWrappers = []any{
+		(*IngestRequest_Entity)(nil),
+		(*IngestRequest_Edge)(nil),
+		(*IngestRequest_EntityTag)(nil),
+		(*IngestRequest_EdgeTag)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gatewaypb_gateway_proto_rawDesc), len(file_gatewaypb_gateway_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   21,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gatewaypb_gateway_proto_goTypes,
+		DependencyIndexes: file_gatewaypb_gateway_proto_depIdxs,
+		MessageInfos:      file_gatewaypb_gateway_proto_msgTypes,
+	}.Build()
+	File_gatewaypb_gateway_proto = out.File
+	file_gatewaypb_gateway_proto_goTypes = nil
+	file_gatewaypb_gateway_proto_depIdxs = nil
+}
@@ -0,0 +1,1055 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             v5.29.3
+// source: gatewaypb/gateway.proto
+
+// The gRPC API of the Open Asset Gateway. It mirrors the REST API:
+// assets, relations and properties are the JSON objects of their type,
+// as registered in the gateway, carried as Structs.
+
+package gatewaypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Gateway_CreateEntity_FullMethodName    = "/gateway.v1.Gateway/CreateEntity"
+	Gateway_GetEntity_FullMethodName       = "/gateway.v1.Gateway/GetEntity"
+	Gateway_UpdateEntity_FullMethodName    = "/gateway.v1.Gateway/UpdateEntity"
+	Gateway_PatchEntity_FullMethodName     = "/gateway.v1.Gateway/PatchEntity"
+	Gateway_DeleteEntity_FullMethodName    = "/gateway.v1.Gateway/DeleteEntity"
+	Gateway_CreateEdge_FullMethodName      = "/gateway.v1.Gateway/CreateEdge"
+	Gateway_GetEdge_FullMethodName         = "/gateway.v1.Gateway/GetEdge"
+	Gateway_UpdateEdge_FullMethodName      = "/gateway.v1.Gateway/UpdateEdge"
+	Gateway_PatchEdge_FullMethodName       = "/gateway.v1.Gateway/PatchEdge"
+	Gateway_DeleteEdge_FullMethodName      = "/gateway.v1.Gateway/DeleteEdge"
+	Gateway_CreateEntityTag_FullMethodName = "/gateway.v1.Gateway/CreateEntityTag"
+	Gateway_GetEntityTag_FullMethodName    = "/gateway.v1.Gateway/GetEntityTag"
+	Gateway_UpdateEntityTag_FullMethodName = "/gateway.v1.Gateway/UpdateEntityTag"
+	Gateway_PatchEntityTag_FullMethodName  = "/gateway.v1.Gateway/PatchEntityTag"
+	Gateway_DeleteEntityTag_FullMethodName = "/gateway.v1.Gateway/DeleteEntityTag"
+	Gateway_CreateEdgeTag_FullMethodName   = "/gateway.v1.Gateway/CreateEdgeTag"
+	Gateway_GetEdgeTag_FullMethodName      = "/gateway.v1.Gateway/GetEdgeTag"
+	Gateway_UpdateEdgeTag_FullMethodName   = "/gateway.v1.Gateway/UpdateEdgeTag"
+	Gateway_PatchEdgeTag_FullMethodName    = "/gateway.v1.Gateway/PatchEdgeTag"
+	Gateway_DeleteEdgeTag_FullMethodName   = "/gateway.v1.Gateway/DeleteEdgeTag"
+	Gateway_FindEntities_FullMethodName    = "/gateway.v1.Gateway/FindEntities"
+	Gateway_GetGraph_FullMethodName        = "/gateway.v1.Gateway/GetGraph"
+	Gateway_FindPaths_FullMethodName       = "/gateway.v1.Gateway/FindPaths"
+	Gateway_Subscribe_FullMethodName       = "/gateway.v1.Gateway/Subscribe"
+	Gateway_Ingest_FullMethodName          = "/gateway.v1.Gateway/Ingest"
+)
+
+// GatewayClient is the client API for Gateway service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GatewayClient interface {
+	// Writes return the object as stored, and its ETag in the "etag"
+	// response header.
+	CreateEntity(ctx context.Context, in *Entity, opts ...grpc.CallOption) (*Entity, error)
+	GetEntity(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Entity, error)
+	UpdateEntity(ctx context.Context, in *UpdateEntityRequest, opts ...grpc.CallOption) (*Entity, error)
+	PatchEntity(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Entity, error)
+	DeleteEntity(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*Entity, error)
+	CreateEdge(ctx context.Context, in *Edge, opts ...grpc.CallOption) (*Edge, error)
+	GetEdge(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Edge, error)
+	UpdateEdge(ctx context.Context, in *UpdateEdgeRequest, opts ...grpc.CallOption) (*Edge, error)
+	PatchEdge(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Edge, error)
+	DeleteEdge(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*Edge, error)
+	CreateEntityTag(ctx context.Context, in *EntityTag, opts ...grpc.CallOption) (*EntityTag, error)
+	GetEntityTag(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*EntityTag, error)
+	UpdateEntityTag(ctx context.Context, in *UpdateEntityTagRequest, opts ...grpc.CallOption) (*EntityTag, error)
+	PatchEntityTag(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*EntityTag, error)
+	DeleteEntityTag(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*EntityTag, error)
+	CreateEdgeTag(ctx context.Context, in *EdgeTag, opts ...grpc.CallOption) (*EdgeTag, error)
+	GetEdgeTag(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*EdgeTag, error)
+	UpdateEdgeTag(ctx context.Context, in *UpdateEdgeTagRequest, opts ...grpc.CallOption) (*EdgeTag, error)
+	PatchEdgeTag(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*EdgeTag, error)
+	DeleteEdgeTag(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*EdgeTag, error)
+	FindEntities(ctx context.Context, in *FindEntitiesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Entity], error)
+	GetGraph(ctx context.Context, in *GraphRequest, opts ...grpc.CallOption) (*Subgraph, error)
+	FindPaths(ctx context.Context, in *PathRequest, opts ...grpc.CallOption) (*PathResponse, error)
+	// Subscribe streams the events published on /listen.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	// Ingest writes a stream of objects, deduplicated like an import.
+	// Edges and tags may reference the IDs of objects sent earlier in the
+	// same stream, which are mapped to the IDs they were stored under.
+	// It runs as an "ingest" job, whose ID is sent in the "job-id"
+	// response header, so that it can be followed and cancelled through
+	// /jobs.
+	Ingest(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[IngestRequest, IngestResponse], error)
+}
+
+type gatewayClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGatewayClient(cc grpc.ClientConnInterface) GatewayClient {
+	return &gatewayClient{cc}
+}
+
+func (c *gatewayClient) CreateEntity(ctx context.Context, in *Entity, opts ...grpc.CallOption) (*Entity, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Entity)
+	err := c.cc.Invoke(ctx, Gateway_CreateEntity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) GetEntity(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Entity, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Entity)
+	err := c.cc.Invoke(ctx, Gateway_GetEntity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) UpdateEntity(ctx context.Context, in *UpdateEntityRequest, opts ...grpc.CallOption) (*Entity, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Entity)
+	err := c.cc.Invoke(ctx, Gateway_UpdateEntity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) PatchEntity(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Entity, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Entity)
+	err := c.cc.Invoke(ctx, Gateway_PatchEntity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) DeleteEntity(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*Entity, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Entity)
+	err := c.cc.Invoke(ctx, Gateway_DeleteEntity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) CreateEdge(ctx context.Context, in *Edge, opts ...grpc.CallOption) (*Edge, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Edge)
+	err := c.cc.Invoke(ctx, Gateway_CreateEdge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) GetEdge(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Edge, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Edge)
+	err := c.cc.Invoke(ctx, Gateway_GetEdge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) UpdateEdge(ctx context.Context, in *UpdateEdgeRequest, opts ...grpc.CallOption) (*Edge, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Edge)
+	err := c.cc.Invoke(ctx, Gateway_UpdateEdge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) PatchEdge(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Edge, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Edge)
+	err := c.cc.Invoke(ctx, Gateway_PatchEdge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) DeleteEdge(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*Edge, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Edge)
+	err := c.cc.Invoke(ctx, Gateway_DeleteEdge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) CreateEntityTag(ctx context.Context, in *EntityTag, opts ...grpc.CallOption) (*EntityTag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EntityTag)
+	err := c.cc.Invoke(ctx, Gateway_CreateEntityTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) GetEntityTag(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*EntityTag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EntityTag)
+	err := c.cc.Invoke(ctx, Gateway_GetEntityTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) UpdateEntityTag(ctx context.Context, in *UpdateEntityTagRequest, opts ...grpc.CallOption) (*EntityTag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EntityTag)
+	err := c.cc.Invoke(ctx, Gateway_UpdateEntityTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) PatchEntityTag(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*EntityTag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EntityTag)
+	err := c.cc.Invoke(ctx, Gateway_PatchEntityTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) DeleteEntityTag(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*EntityTag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EntityTag)
+	err := c.cc.Invoke(ctx, Gateway_DeleteEntityTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) CreateEdgeTag(ctx context.Context, in *EdgeTag, opts ...grpc.CallOption) (*EdgeTag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EdgeTag)
+	err := c.cc.Invoke(ctx, Gateway_CreateEdgeTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) GetEdgeTag(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*EdgeTag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EdgeTag)
+	err := c.cc.Invoke(ctx, Gateway_GetEdgeTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) UpdateEdgeTag(ctx context.Context, in *UpdateEdgeTagRequest, opts ...grpc.CallOption) (*EdgeTag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EdgeTag)
+	err := c.cc.Invoke(ctx, Gateway_UpdateEdgeTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) PatchEdgeTag(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*EdgeTag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EdgeTag)
+	err := c.cc.Invoke(ctx, Gateway_PatchEdgeTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) DeleteEdgeTag(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*EdgeTag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EdgeTag)
+	err := c.cc.Invoke(ctx, Gateway_DeleteEdgeTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) FindEntities(ctx context.Context, in *FindEntitiesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Entity], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Gateway_ServiceDesc.Streams[0], Gateway_FindEntities_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FindEntitiesRequest, Entity]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Gateway_FindEntitiesClient = grpc.ServerStreamingClient[Entity]
+
+func (c *gatewayClient) GetGraph(ctx context.Context, in *GraphRequest, opts ...grpc.CallOption) (*Subgraph, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Subgraph)
+	err := c.cc.Invoke(ctx, Gateway_GetGraph_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) FindPaths(ctx context.Context, in *PathRequest, opts ...grpc.CallOption) (*PathResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PathResponse)
+	err := c.cc.Invoke(ctx, Gateway_FindPaths_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Gateway_ServiceDesc.Streams[1], Gateway_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Gateway_SubscribeClient = grpc.ServerStreamingClient[Event]
+
+func (c *gatewayClient) Ingest(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[IngestRequest, IngestResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Gateway_ServiceDesc.Streams[2], Gateway_Ingest_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[IngestRequest, IngestResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Gateway_IngestClient = grpc.ClientStreamingClient[IngestRequest, IngestResponse]
+
+// GatewayServer is the server API for Gateway service.
+// All implementations must embed UnimplementedGatewayServer
+// for forward compatibility.
+type GatewayServer interface {
+	// Writes return the object as stored, and its ETag in the "etag"
+	// response header.
+	CreateEntity(context.Context, *Entity) (*Entity, error)
+	GetEntity(context.Context, *GetRequest) (*Entity, error)
+	UpdateEntity(context.Context, *UpdateEntityRequest) (*Entity, error)
+	PatchEntity(context.Context, *PatchRequest) (*Entity, error)
+	DeleteEntity(context.Context, *DeleteRequest) (*Entity, error)
+	CreateEdge(context.Context, *Edge) (*Edge, error)
+	GetEdge(context.Context, *GetRequest) (*Edge, error)
+	UpdateEdge(context.Context, *UpdateEdgeRequest) (*Edge, error)
+	PatchEdge(context.Context, *PatchRequest) (*Edge, error)
+	DeleteEdge(context.Context, *DeleteRequest) (*Edge, error)
+	CreateEntityTag(context.Context, *EntityTag) (*EntityTag, error)
+	GetEntityTag(context.Context, *GetRequest) (*EntityTag, error)
+	UpdateEntityTag(context.Context, *UpdateEntityTagRequest) (*EntityTag, error)
+	PatchEntityTag(context.Context, *PatchRequest) (*EntityTag, error)
+	DeleteEntityTag(context.Context, *DeleteRequest) (*EntityTag, error)
+	CreateEdgeTag(context.Context, *EdgeTag) (*EdgeTag, error)
+	GetEdgeTag(context.Context, *GetRequest) (*EdgeTag, error)
+	UpdateEdgeTag(context.Context, *UpdateEdgeTagRequest) (*EdgeTag, error)
+	PatchEdgeTag(context.Context, *PatchRequest) (*EdgeTag, error)
+	DeleteEdgeTag(context.Context, *DeleteRequest) (*EdgeTag, error)
+	FindEntities(*FindEntitiesRequest, grpc.ServerStreamingServer[Entity]) error
+	GetGraph(context.Context, *GraphRequest) (*Subgraph, error)
+	FindPaths(context.Context, *PathRequest) (*PathResponse, error)
+	// Subscribe streams the events published on /listen.
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error
+	// Ingest writes a stream of objects, deduplicated like an import.
+	// Edges and tags may reference the IDs of objects sent earlier in the
+	// same stream, which are mapped to the IDs they were stored under.
+	// It runs as an "ingest" job, whose ID is sent in the "job-id"
+	// response header, so that it can be followed and cancelled through
+	// /jobs.
+	Ingest(grpc.ClientStreamingServer[IngestRequest, IngestResponse]) error
+	mustEmbedUnimplementedGatewayServer()
+}
+
+// UnimplementedGatewayServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGatewayServer struct{}
+
+func (UnimplementedGatewayServer) CreateEntity(context.Context, *Entity) (*Entity, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateEntity not implemented")
+}
+func (UnimplementedGatewayServer) GetEntity(context.Context, *GetRequest) (*Entity, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetEntity not implemented")
+}
+func (UnimplementedGatewayServer) UpdateEntity(context.Context, *UpdateEntityRequest) (*Entity, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateEntity not implemented")
+}
+func (UnimplementedGatewayServer) PatchEntity(context.Context, *PatchRequest) (*Entity, error) {
+	return nil, status.Error(codes.Unimplemented, "method PatchEntity not implemented")
+}
+func (UnimplementedGatewayServer) DeleteEntity(context.Context, *DeleteRequest) (*Entity, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteEntity not implemented")
+}
+func (UnimplementedGatewayServer) CreateEdge(context.Context, *Edge) (*Edge, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateEdge not implemented")
+}
+func (UnimplementedGatewayServer) GetEdge(context.Context, *GetRequest) (*Edge, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetEdge not implemented")
+}
+func (UnimplementedGatewayServer) UpdateEdge(context.Context, *UpdateEdgeRequest) (*Edge, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateEdge not implemented")
+}
+func (UnimplementedGatewayServer) PatchEdge(context.Context, *PatchRequest) (*Edge, error) {
+	return nil, status.Error(codes.Unimplemented, "method PatchEdge not implemented")
+}
+func (UnimplementedGatewayServer) DeleteEdge(context.Context, *DeleteRequest) (*Edge, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteEdge not implemented")
+}
+func (UnimplementedGatewayServer) CreateEntityTag(context.Context, *EntityTag) (*EntityTag, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateEntityTag not implemented")
+}
+func (UnimplementedGatewayServer) GetEntityTag(context.Context, *GetRequest) (*EntityTag, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetEntityTag not implemented")
+}
+func (UnimplementedGatewayServer) UpdateEntityTag(context.Context, *UpdateEntityTagRequest) (*EntityTag, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateEntityTag not implemented")
+}
+func (UnimplementedGatewayServer) PatchEntityTag(context.Context, *PatchRequest) (*EntityTag, error) {
+	return nil, status.Error(codes.Unimplemented, "method PatchEntityTag not implemented")
+}
+func (UnimplementedGatewayServer) DeleteEntityTag(context.Context, *DeleteRequest) (*EntityTag, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteEntityTag not implemented")
+}
+func (UnimplementedGatewayServer) CreateEdgeTag(context.Context, *EdgeTag) (*EdgeTag, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateEdgeTag not implemented")
+}
+func (UnimplementedGatewayServer) GetEdgeTag(context.Context, *GetRequest) (*EdgeTag, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetEdgeTag not implemented")
+}
+func (UnimplementedGatewayServer) UpdateEdgeTag(context.Context, *UpdateEdgeTagRequest) (*EdgeTag, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateEdgeTag not implemented")
+}
+func (UnimplementedGatewayServer) PatchEdgeTag(context.Context, *PatchRequest) (*EdgeTag, error) {
+	return nil, status.Error(codes.Unimplemented, "method PatchEdgeTag not implemented")
+}
+func (UnimplementedGatewayServer) DeleteEdgeTag(context.Context, *DeleteRequest) (*EdgeTag, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteEdgeTag not implemented")
+}
+func (UnimplementedGatewayServer) FindEntities(*FindEntitiesRequest, grpc.ServerStreamingServer[Entity]) error {
+	return status.Error(codes.Unimplemented, "method FindEntities not implemented")
+}
+func (UnimplementedGatewayServer) GetGraph(context.Context, *GraphRequest) (*Subgraph, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetGraph not implemented")
+}
+func (UnimplementedGatewayServer) FindPaths(context.Context, *PathRequest) (*PathResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FindPaths not implemented")
+}
+func (UnimplementedGatewayServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Error(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedGatewayServer) Ingest(grpc.ClientStreamingServer[IngestRequest, IngestResponse]) error {
+	return status.Error(codes.Unimplemented, "method Ingest not implemented")
+}
+func (UnimplementedGatewayServer) mustEmbedUnimplementedGatewayServer() {}
+func (UnimplementedGatewayServer) testEmbeddedByValue()                 {}
+
+// UnsafeGatewayServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GatewayServer will
+// result in compilation errors.
+type UnsafeGatewayServer interface {
+	mustEmbedUnimplementedGatewayServer()
+}
+
+func RegisterGatewayServer(s grpc.ServiceRegistrar, srv GatewayServer) {
+	// If the following call panics, it indicates UnimplementedGatewayServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Gateway_ServiceDesc, srv)
+}
+
+func _Gateway_CreateEntity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Entity)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).CreateEntity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_CreateEntity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).CreateEntity(ctx, req.(*Entity))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_GetEntity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).GetEntity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_GetEntity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).GetEntity(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_UpdateEntity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateEntityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).UpdateEntity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_UpdateEntity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).UpdateEntity(ctx, req.(*UpdateEntityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_PatchEntity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).PatchEntity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_PatchEntity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).PatchEntity(ctx, req.(*PatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_DeleteEntity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).DeleteEntity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_DeleteEntity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).DeleteEntity(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_CreateEdge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Edge)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).CreateEdge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_CreateEdge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).CreateEdge(ctx, req.(*Edge))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_GetEdge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).GetEdge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_GetEdge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).GetEdge(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_UpdateEdge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateEdgeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).UpdateEdge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_UpdateEdge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).UpdateEdge(ctx, req.(*UpdateEdgeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_PatchEdge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).PatchEdge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_PatchEdge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).PatchEdge(ctx, req.(*PatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_DeleteEdge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).DeleteEdge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_DeleteEdge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).DeleteEdge(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_CreateEntityTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EntityTag)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).CreateEntityTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_CreateEntityTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).CreateEntityTag(ctx, req.(*EntityTag))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_GetEntityTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).GetEntityTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_GetEntityTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).GetEntityTag(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_UpdateEntityTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateEntityTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).UpdateEntityTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_UpdateEntityTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).UpdateEntityTag(ctx, req.(*UpdateEntityTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_PatchEntityTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).PatchEntityTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_PatchEntityTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).PatchEntityTag(ctx, req.(*PatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_DeleteEntityTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).DeleteEntityTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_DeleteEntityTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).DeleteEntityTag(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_CreateEdgeTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EdgeTag)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).CreateEdgeTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_CreateEdgeTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).CreateEdgeTag(ctx, req.(*EdgeTag))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_GetEdgeTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).GetEdgeTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_GetEdgeTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).GetEdgeTag(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_UpdateEdgeTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateEdgeTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).UpdateEdgeTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_UpdateEdgeTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).UpdateEdgeTag(ctx, req.(*UpdateEdgeTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_PatchEdgeTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).PatchEdgeTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_PatchEdgeTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).PatchEdgeTag(ctx, req.(*PatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_DeleteEdgeTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).DeleteEdgeTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_DeleteEdgeTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).DeleteEdgeTag(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_FindEntities_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FindEntitiesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GatewayServer).FindEntities(m, &grpc.GenericServerStream[FindEntitiesRequest, Entity]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Gateway_FindEntitiesServer = grpc.ServerStreamingServer[Entity]
+
+func _Gateway_GetGraph_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GraphRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).GetGraph(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_GetGraph_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).GetGraph(ctx, req.(*GraphRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_FindPaths_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PathRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).FindPaths(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_FindPaths_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).FindPaths(ctx, req.(*PathRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gateway_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GatewayServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Gateway_SubscribeServer = grpc.ServerStreamingServer[Event]
+
+func _Gateway_Ingest_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GatewayServer).Ingest(&grpc.GenericServerStream[IngestRequest, IngestResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Gateway_IngestServer = grpc.ClientStreamingServer[IngestRequest, IngestResponse]
+
+// Gateway_ServiceDesc is the grpc.ServiceDesc for Gateway service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Gateway_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gateway.v1.Gateway",
+	HandlerType: (*GatewayServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateEntity",
+			Handler:    _Gateway_CreateEntity_Handler,
+		},
+		{
+			MethodName: "GetEntity",
+			Handler:    _Gateway_GetEntity_Handler,
+		},
+		{
+			MethodName: "UpdateEntity",
+			Handler:    _Gateway_UpdateEntity_Handler,
+		},
+		{
+			MethodName: "PatchEntity",
+			Handler:    _Gateway_PatchEntity_Handler,
+		},
+		{
+			MethodName: "DeleteEntity",
+			Handler:    _Gateway_DeleteEntity_Handler,
+		},
+		{
+			MethodName: "CreateEdge",
+			Handler:    _Gateway_CreateEdge_Handler,
+		},
+		{
+			MethodName: "GetEdge",
+			Handler:    _Gateway_GetEdge_Handler,
+		},
+		{
+			MethodName: "UpdateEdge",
+			Handler:    _Gateway_UpdateEdge_Handler,
+		},
+		{
+			MethodName: "PatchEdge",
+			Handler:    _Gateway_PatchEdge_Handler,
+		},
+		{
+			MethodName: "DeleteEdge",
+			Handler:    _Gateway_DeleteEdge_Handler,
+		},
+		{
+			MethodName: "CreateEntityTag",
+			Handler:    _Gateway_CreateEntityTag_Handler,
+		},
+		{
+			MethodName: "GetEntityTag",
+			Handler:    _Gateway_GetEntityTag_Handler,
+		},
+		{
+			MethodName: "UpdateEntityTag",
+			Handler:    _Gateway_UpdateEntityTag_Handler,
+		},
+		{
+			MethodName: "PatchEntityTag",
+			Handler:    _Gateway_PatchEntityTag_Handler,
+		},
+		{
+			MethodName: "DeleteEntityTag",
+			Handler:    _Gateway_DeleteEntityTag_Handler,
+		},
+		{
+			MethodName: "CreateEdgeTag",
+			Handler:    _Gateway_CreateEdgeTag_Handler,
+		},
+		{
+			MethodName: "GetEdgeTag",
+			Handler:    _Gateway_GetEdgeTag_Handler,
+		},
+		{
+			MethodName: "UpdateEdgeTag",
+			Handler:    _Gateway_UpdateEdgeTag_Handler,
+		},
+		{
+			MethodName: "PatchEdgeTag",
+			Handler:    _Gateway_PatchEdgeTag_Handler,
+		},
+		{
+			MethodName: "DeleteEdgeTag",
+			Handler:    _Gateway_DeleteEdgeTag_Handler,
+		},
+		{
+			MethodName: "GetGraph",
+			Handler:    _Gateway_GetGraph_Handler,
+		},
+		{
+			MethodName: "FindPaths",
+			Handler:    _Gateway_FindPaths_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "FindEntities",
+			Handler:       _Gateway_FindEntities_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Gateway_Subscribe_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Ingest",
+			Handler:       _Gateway_Ingest_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "gatewaypb/gateway.proto",
+}
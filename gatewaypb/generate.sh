@@ -0,0 +1,27 @@
+#!/bin/sh
+# Generates gateway.pb.go and gateway_grpc.pb.go from gateway.proto.
+# The versions of protoc and of its plugins are pinned, since they are
+# written in the headers of the generated files.
+set -eu
+
+PROTOC_VERSION=29.3
+PROTOC_GEN_GO_VERSION=v1.36.6
+PROTOC_GEN_GO_GRPC_VERSION=v1.6.2
+
+cd "$(dirname "$0")/.."
+
+if [ "$(protoc --version)" != "libprotoc $PROTOC_VERSION" ]; then
+	echo "generate.sh: protoc $PROTOC_VERSION is required, from https://github.com/protocolbuffers/protobuf/releases/tag/v$PROTOC_VERSION" >&2
+	exit 1
+fi
+
+bin=$(mktemp -d)
+trap 'rm -rf "$bin"' EXIT
+
+GOBIN=$bin go install google.golang.org/protobuf/cmd/protoc-gen-go@$PROTOC_GEN_GO_VERSION
+GOBIN=$bin go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@$PROTOC_GEN_GO_GRPC_VERSION
+
+PATH=$bin:$PATH protoc \
+	--go_out=. --go_opt=paths=source_relative \
+	--go-grpc_out=. --go-grpc_opt=paths=source_relative \
+	gatewaypb/gateway.proto
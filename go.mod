@@ -2,14 +2,23 @@ module github.com/0ppliger/oam-broker
 
 go 1.24.0
 
+// The server and the memory package are built with a fork of asset-db
+// whose repositories take a context and report their last event. It is
+// not published yet: clone it into ../asset-db, next to this module,
+// before building. The replace applies to the whole module, so no
+// package builds without that checkout. The gateway, wire and client
+// packages only use what asset-db v0.23.1 publishes; to build them
+// alone against it, drop the replace with
+// go mod edit -dropreplace=github.com/owasp-amass/asset-db.
 replace github.com/owasp-amass/asset-db => ../asset-db
 
-replace github.com/owasp-amass/open-asset-model => ../open-asset-model
-
 require (
+	github.com/graphql-go/graphql v0.8.1
 	github.com/owasp-amass/asset-db v0.23.1
 	github.com/owasp-amass/open-asset-model v0.15.0
 	github.com/sirupsen/logrus v1.9.4
+	google.golang.org/grpc v1.76.0
+	google.golang.org/protobuf v1.36.6
 )
 
 require (
@@ -28,9 +37,11 @@ require (
 	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
 	github.com/rubenv/sql-migrate v1.8.1 // indirect
 	golang.org/x/exp v0.0.0-20260112195511-716be5621a96 // indirect
+	golang.org/x/net v0.42.0 // indirect
 	golang.org/x/sync v0.19.0 // indirect
 	golang.org/x/sys v0.40.0 // indirect
 	golang.org/x/text v0.33.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250804133106-a7a43d27e69b // indirect
 	modernc.org/libc v1.67.7 // indirect
 	modernc.org/mathutil v1.7.1 // indirect
 	modernc.org/memory v1.11.0 // indirect
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	dbt "github.com/owasp-amass/asset-db/types"
+)
+
+// The repository lists the objects written since a time in one pass, so
+// that the gateway StoreWatcher does not walk it entity by entity.
+// Unlike the lookups, finding nothing is not an error.
+
+func (mem *memRepository) EntitiesSince(ctx context.Context, since time.Time) ([]*dbt.Entity, error) {
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	var out []*dbt.Entity
+	for _, e := range mem.entities {
+		if seen(e.LastSeen, since) {
+			out = append(out, copyEntity(e))
+		}
+	}
+	return out, nil
+}
+
+func (mem *memRepository) EdgesSince(ctx context.Context, since time.Time) ([]*dbt.Edge, error) {
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	var out []*dbt.Edge
+	for _, e := range mem.edges {
+		if seen(e.LastSeen, since) {
+			out = append(out, mem.resolveEdge(e))
+		}
+	}
+	return out, nil
+}
+
+func (mem *memRepository) EntityTagsSince(ctx context.Context, since time.Time) ([]*dbt.EntityTag, error) {
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	var out []*dbt.EntityTag
+	for _, tag := range mem.entity_tags {
+		if seen(tag.LastSeen, since) {
+			out = append(out, mem.resolveEntityTag(tag))
+		}
+	}
+	return out, nil
+}
+
+func (mem *memRepository) EdgeTagsSince(ctx context.Context, since time.Time) ([]*dbt.EdgeTag, error) {
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	var out []*dbt.EdgeTag
+	for _, tag := range mem.edge_tags {
+		if seen(tag.LastSeen, since) {
+			out = append(out, mem.resolveEdgeTag(tag))
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,657 @@
+// Package memory implements the asset-db repository in memory, so that
+// the gateway runs without a database, for tests and demos. Its contents
+// are lost when the process exits.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/owasp-amass/asset-db/events"
+	"github.com/owasp-amass/asset-db/repository"
+	dbt "github.com/owasp-amass/asset-db/types"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+// Memory is the DB type of the repository, and the scheme of its URL.
+const Memory = "memory"
+
+// Like the other repositories, lookups finding nothing fail.
+var (
+	ErrEntityNotFound = errors.New("entity not found")
+	ErrEdgeNotFound   = errors.New("edge not found")
+	ErrTagNotFound    = errors.New("tag not found")
+)
+
+// The edges and tags are stored with the IDs of what they refer to, and
+// resolved on reads, so that they always carry the current entities.
+type edge struct {
+	dbt.Edge
+	from, to string
+}
+
+type entityTag struct {
+	dbt.EntityTag
+	entity string
+}
+
+type edgeTag struct {
+	dbt.EdgeTag
+	edge string
+}
+
+type memRepository struct {
+	mutex       sync.RWMutex
+	entities    map[string]*dbt.Entity
+	edges       map[string]*edge
+	entity_tags map[string]*entityTag
+	edge_tags   map[string]*edgeTag
+	next_id     uint64
+	last_event  events.EventType
+}
+
+var _ repository.Repository = (*memRepository)(nil)
+
+func New() *memRepository {
+	return &memRepository{
+		entities:    make(map[string]*dbt.Entity),
+		edges:       make(map[string]*edge),
+		entity_tags: make(map[string]*entityTag),
+		edge_tags:   make(map[string]*edgeTag),
+	}
+}
+
+func (mem *memRepository) GetDBType() string {
+	return Memory
+}
+
+func (mem *memRepository) Close() error {
+	return nil
+}
+
+// GetLastEvent returns what the last entity write did.
+func (mem *memRepository) GetLastEvent() events.EventType {
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	return mem.last_event
+}
+
+func (mem *memRepository) newID() string {
+	mem.next_id++
+	return fmt.Sprint(mem.next_id)
+}
+
+// sameContent compares assets, relations and properties by their JSON,
+// like the other repositories compare their stored fields.
+func sameContent(a, b any) bool {
+	a_json, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	b_json, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(a_json) == string(b_json)
+}
+
+// seen reports whether an object last seen at last_seen is to be
+// returned by a lookup since the given time.
+func seen(last_seen, since time.Time) bool {
+	return since.IsZero() || !last_seen.Before(since)
+}
+
+func copyEntity(e *dbt.Entity) *dbt.Entity {
+	out := *e
+	return &out
+}
+
+// CreateEntity replaces the entity with the ID of input when it has one.
+// Otherwise an entity with the same asset is refreshed, or a new one is
+// created.
+func (mem *memRepository) CreateEntity(ctx context.Context, input *dbt.Entity) (*dbt.Entity, error) {
+	if input == nil || input.Asset == nil {
+		return nil, errors.New("failed input validation checks")
+	}
+
+	mem.mutex.Lock()
+	defer mem.mutex.Unlock()
+
+	now := time.Now()
+
+	prev, ok := mem.entities[input.ID]
+	if !ok && input.ID == "" {
+		for _, e := range mem.entities {
+			if e.Asset.AssetType() == input.Asset.AssetType() && sameContent(e.Asset, input.Asset) {
+				prev, ok = e, true
+				break
+			}
+		}
+	}
+
+	if ok {
+		updated := *prev
+		updated.Asset = input.Asset
+		updated.LastSeen = now
+		if !input.CreatedAt.IsZero() {
+			updated.CreatedAt = input.CreatedAt
+		}
+		mem.entities[updated.ID] = &updated
+		mem.last_event = events.EntityUpdated
+		return copyEntity(&updated), nil
+	}
+
+	created := &dbt.Entity{
+		ID:        input.ID,
+		CreatedAt: input.CreatedAt,
+		LastSeen:  now,
+		Asset:     input.Asset,
+	}
+	if created.ID == "" {
+		created.ID = mem.newID()
+	}
+	if created.CreatedAt.IsZero() {
+		created.CreatedAt = now
+	}
+	mem.entities[created.ID] = created
+	mem.last_event = events.EntityCreated
+	return copyEntity(created), nil
+}
+
+func (mem *memRepository) CreateAsset(ctx context.Context, asset oam.Asset) (*dbt.Entity, error) {
+	return mem.CreateEntity(ctx, &dbt.Entity{Asset: asset})
+}
+
+func (mem *memRepository) FindEntityById(ctx context.Context, id string) (*dbt.Entity, error) {
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	e, ok := mem.entities[id]
+	if !ok {
+		return nil, ErrEntityNotFound
+	}
+	return copyEntity(e), nil
+}
+
+func (mem *memRepository) FindEntitiesByContent(ctx context.Context, asset oam.Asset, since time.Time) ([]*dbt.Entity, error) {
+	if asset == nil {
+		return nil, errors.New("no asset given")
+	}
+
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	var out []*dbt.Entity
+	for _, e := range mem.entities {
+		if e.Asset.AssetType() == asset.AssetType() && seen(e.LastSeen, since) && sameContent(e.Asset, asset) {
+			out = append(out, copyEntity(e))
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no entities found")
+	}
+	return out, nil
+}
+
+func (mem *memRepository) FindEntitiesByType(ctx context.Context, atype oam.AssetType, since time.Time) ([]*dbt.Entity, error) {
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	var out []*dbt.Entity
+	for _, e := range mem.entities {
+		if e.Asset.AssetType() == atype && seen(e.LastSeen, since) {
+			out = append(out, copyEntity(e))
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no entities of the type found")
+	}
+	return out, nil
+}
+
+// DeleteEntity deletes the entity along with its edges and tags, like a
+// detached delete in a graph database.
+func (mem *memRepository) DeleteEntity(ctx context.Context, id string) error {
+	mem.mutex.Lock()
+	defer mem.mutex.Unlock()
+
+	if _, ok := mem.entities[id]; !ok {
+		return ErrEntityNotFound
+	}
+
+	for edge_id, e := range mem.edges {
+		if e.from == id || e.to == id {
+			mem.deleteEdge(edge_id)
+		}
+	}
+	for tag_id, tag := range mem.entity_tags {
+		if tag.entity == id {
+			delete(mem.entity_tags, tag_id)
+		}
+	}
+	delete(mem.entities, id)
+	mem.last_event = events.EntityDeleted
+	return nil
+}
+
+// resolveEdge returns a copy of e carrying its current entities.
+func (mem *memRepository) resolveEdge(e *edge) *dbt.Edge {
+	out := e.Edge
+	out.FromEntity = copyEntity(mem.entities[e.from])
+	out.ToEntity = copyEntity(mem.entities[e.to])
+	return &out
+}
+
+// CreateEdge replaces the edge with the ID of input when it has one.
+// Otherwise an edge with the same endpoints and relation is refreshed,
+// or a new one is created. The relation must be valid between the types
+// of the entities.
+func (mem *memRepository) CreateEdge(ctx context.Context, input *dbt.Edge) (*dbt.Edge, error) {
+	if input == nil || input.Relation == nil || input.FromEntity == nil || input.ToEntity == nil {
+		return nil, errors.New("failed input validation checks")
+	}
+
+	mem.mutex.Lock()
+	defer mem.mutex.Unlock()
+
+	from, ok := mem.entities[input.FromEntity.ID]
+	if !ok {
+		return nil, fmt.Errorf("from entity: %w", ErrEntityNotFound)
+	}
+	to, ok := mem.entities[input.ToEntity.ID]
+	if !ok {
+		return nil, fmt.Errorf("to entity: %w", ErrEntityNotFound)
+	}
+
+	label := input.Relation.Label()
+	if !oam.ValidRelationship(from.Asset.AssetType(), label, input.Relation.RelationType(), to.Asset.AssetType()) {
+		return nil, fmt.Errorf("%s -%s-> %s is not valid in the taxonomy", from.Asset.AssetType(), label, to.Asset.AssetType())
+	}
+
+	now := time.Now()
+
+	prev, ok := mem.edges[input.ID]
+	if !ok && input.ID == "" {
+		for _, e := range mem.edges {
+			if e.from == from.ID && e.to == to.ID && e.Relation.Label() == label && sameContent(e.Relation, input.Relation) {
+				prev, ok = e, true
+				break
+			}
+		}
+	}
+
+	if ok {
+		updated := *prev
+		updated.Relation = input.Relation
+		updated.from, updated.to = from.ID, to.ID
+		updated.LastSeen = now
+		if !input.CreatedAt.IsZero() {
+			updated.CreatedAt = input.CreatedAt
+		}
+		mem.edges[updated.ID] = &updated
+		return mem.resolveEdge(&updated), nil
+	}
+
+	created := &edge{
+		Edge: dbt.Edge{
+			ID:        input.ID,
+			CreatedAt: input.CreatedAt,
+			LastSeen:  now,
+			Relation:  input.Relation,
+		},
+		from: from.ID,
+		to:   to.ID,
+	}
+	if created.ID == "" {
+		created.ID = mem.newID()
+	}
+	if created.CreatedAt.IsZero() {
+		created.CreatedAt = now
+	}
+	mem.edges[created.ID] = created
+	return mem.resolveEdge(created), nil
+}
+
+func (mem *memRepository) FindEdgeById(ctx context.Context, id string) (*dbt.Edge, error) {
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	e, ok := mem.edges[id]
+	if !ok {
+		return nil, ErrEdgeNotFound
+	}
+	return mem.resolveEdge(e), nil
+}
+
+// findEdges returns the edges for which endpoint returns the ID of the
+// given entity, optionally restricted to some relation labels.
+func (mem *memRepository) findEdges(entity *dbt.Entity, since time.Time, labels []string, endpoint func(*edge) string) ([]*dbt.Edge, error) {
+	if entity == nil {
+		return nil, errors.New("no entity given")
+	}
+
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	var out []*dbt.Edge
+	for _, e := range mem.edges {
+		if endpoint(e) != entity.ID || !seen(e.LastSeen, since) {
+			continue
+		}
+		if len(labels) > 0 && !slices.Contains(labels, e.Relation.Label()) {
+			continue
+		}
+		out = append(out, mem.resolveEdge(e))
+	}
+	if len(out) == 0 {
+		return nil, errors.New("zero edges found")
+	}
+	return out, nil
+}
+
+func (mem *memRepository) IncomingEdges(ctx context.Context, entity *dbt.Entity, since time.Time, labels ...string) ([]*dbt.Edge, error) {
+	return mem.findEdges(entity, since, labels, func(e *edge) string { return e.to })
+}
+
+func (mem *memRepository) OutgoingEdges(ctx context.Context, entity *dbt.Entity, since time.Time, labels ...string) ([]*dbt.Edge, error) {
+	return mem.findEdges(entity, since, labels, func(e *edge) string { return e.from })
+}
+
+// DeleteEdge deletes the edge along with its tags.
+func (mem *memRepository) DeleteEdge(ctx context.Context, id string) error {
+	mem.mutex.Lock()
+	defer mem.mutex.Unlock()
+
+	if _, ok := mem.edges[id]; !ok {
+		return ErrEdgeNotFound
+	}
+	mem.deleteEdge(id)
+	return nil
+}
+
+func (mem *memRepository) deleteEdge(id string) {
+	for tag_id, tag := range mem.edge_tags {
+		if tag.edge == id {
+			delete(mem.edge_tags, tag_id)
+		}
+	}
+	delete(mem.edges, id)
+}
+
+// sameProperty reports whether two tags hold the same property, the way
+// the other repositories deduplicate tags.
+func sameProperty(a, b oam.Property) bool {
+	return a.PropertyType() == b.PropertyType() && a.Name() == b.Name() && a.Value() == b.Value()
+}
+
+func (mem *memRepository) resolveEntityTag(tag *entityTag) *dbt.EntityTag {
+	out := tag.EntityTag
+	out.Entity = copyEntity(mem.entities[tag.entity])
+	return &out
+}
+
+// CreateEntityTag replaces the tag with the ID of input when it has one.
+// Otherwise a tag of the entity with the same property is refreshed, or
+// a new one is created.
+func (mem *memRepository) CreateEntityTag(ctx context.Context, entity *dbt.Entity, input *dbt.EntityTag) (*dbt.EntityTag, error) {
+	if entity == nil || input == nil || input.Property == nil {
+		return nil, errors.New("failed input validation checks")
+	}
+
+	mem.mutex.Lock()
+	defer mem.mutex.Unlock()
+
+	if _, ok := mem.entities[entity.ID]; !ok {
+		return nil, ErrEntityNotFound
+	}
+
+	now := time.Now()
+
+	prev, ok := mem.entity_tags[input.ID]
+	if !ok && input.ID == "" {
+		for _, tag := range mem.entity_tags {
+			if tag.entity == entity.ID && sameProperty(tag.Property, input.Property) {
+				prev, ok = tag, true
+				break
+			}
+		}
+	}
+
+	if ok {
+		updated := *prev
+		updated.Property = input.Property
+		updated.entity = entity.ID
+		updated.LastSeen = now
+		if !input.CreatedAt.IsZero() {
+			updated.CreatedAt = input.CreatedAt
+		}
+		mem.entity_tags[updated.ID] = &updated
+		return mem.resolveEntityTag(&updated), nil
+	}
+
+	created := &entityTag{
+		EntityTag: dbt.EntityTag{
+			ID:        input.ID,
+			CreatedAt: input.CreatedAt,
+			LastSeen:  now,
+			Property:  input.Property,
+		},
+		entity: entity.ID,
+	}
+	if created.ID == "" {
+		created.ID = mem.newID()
+	}
+	if created.CreatedAt.IsZero() {
+		created.CreatedAt = now
+	}
+	mem.entity_tags[created.ID] = created
+	return mem.resolveEntityTag(created), nil
+}
+
+func (mem *memRepository) CreateEntityProperty(ctx context.Context, entity *dbt.Entity, property oam.Property) (*dbt.EntityTag, error) {
+	return mem.CreateEntityTag(ctx, entity, &dbt.EntityTag{Property: property})
+}
+
+func (mem *memRepository) FindEntityTagById(ctx context.Context, id string) (*dbt.EntityTag, error) {
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	tag, ok := mem.entity_tags[id]
+	if !ok {
+		return nil, ErrTagNotFound
+	}
+	return mem.resolveEntityTag(tag), nil
+}
+
+func (mem *memRepository) FindEntityTagsByContent(ctx context.Context, prop oam.Property, since time.Time) ([]*dbt.EntityTag, error) {
+	if prop == nil {
+		return nil, errors.New("no property given")
+	}
+
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	var out []*dbt.EntityTag
+	for _, tag := range mem.entity_tags {
+		if seen(tag.LastSeen, since) && sameProperty(tag.Property, prop) {
+			out = append(out, mem.resolveEntityTag(tag))
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("zero tags found")
+	}
+	return out, nil
+}
+
+func (mem *memRepository) GetEntityTags(ctx context.Context, entity *dbt.Entity, since time.Time, names ...string) ([]*dbt.EntityTag, error) {
+	if entity == nil {
+		return nil, errors.New("no entity given")
+	}
+
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	var out []*dbt.EntityTag
+	for _, tag := range mem.entity_tags {
+		if tag.entity != entity.ID || !seen(tag.LastSeen, since) {
+			continue
+		}
+		if len(names) > 0 && !slices.Contains(names, tag.Property.Name()) {
+			continue
+		}
+		out = append(out, mem.resolveEntityTag(tag))
+	}
+	if len(out) == 0 {
+		return nil, errors.New("zero tags found")
+	}
+	return out, nil
+}
+
+func (mem *memRepository) DeleteEntityTag(ctx context.Context, id string) error {
+	mem.mutex.Lock()
+	defer mem.mutex.Unlock()
+
+	if _, ok := mem.entity_tags[id]; !ok {
+		return ErrTagNotFound
+	}
+	delete(mem.entity_tags, id)
+	return nil
+}
+
+func (mem *memRepository) resolveEdgeTag(tag *edgeTag) *dbt.EdgeTag {
+	out := tag.EdgeTag
+	out.Edge = mem.resolveEdge(mem.edges[tag.edge])
+	return &out
+}
+
+// CreateEdgeTag writes a tag of an edge like CreateEntityTag.
+func (mem *memRepository) CreateEdgeTag(ctx context.Context, e *dbt.Edge, input *dbt.EdgeTag) (*dbt.EdgeTag, error) {
+	if e == nil || input == nil || input.Property == nil {
+		return nil, errors.New("failed input validation checks")
+	}
+
+	mem.mutex.Lock()
+	defer mem.mutex.Unlock()
+
+	if _, ok := mem.edges[e.ID]; !ok {
+		return nil, ErrEdgeNotFound
+	}
+
+	now := time.Now()
+
+	prev, ok := mem.edge_tags[input.ID]
+	if !ok && input.ID == "" {
+		for _, tag := range mem.edge_tags {
+			if tag.edge == e.ID && sameProperty(tag.Property, input.Property) {
+				prev, ok = tag, true
+				break
+			}
+		}
+	}
+
+	if ok {
+		updated := *prev
+		updated.Property = input.Property
+		updated.edge = e.ID
+		updated.LastSeen = now
+		if !input.CreatedAt.IsZero() {
+			updated.CreatedAt = input.CreatedAt
+		}
+		mem.edge_tags[updated.ID] = &updated
+		return mem.resolveEdgeTag(&updated), nil
+	}
+
+	created := &edgeTag{
+		EdgeTag: dbt.EdgeTag{
+			ID:        input.ID,
+			CreatedAt: input.CreatedAt,
+			LastSeen:  now,
+			Property:  input.Property,
+		},
+		edge: e.ID,
+	}
+	if created.ID == "" {
+		created.ID = mem.newID()
+	}
+	if created.CreatedAt.IsZero() {
+		created.CreatedAt = now
+	}
+	mem.edge_tags[created.ID] = created
+	return mem.resolveEdgeTag(created), nil
+}
+
+func (mem *memRepository) CreateEdgeProperty(ctx context.Context, e *dbt.Edge, property oam.Property) (*dbt.EdgeTag, error) {
+	return mem.CreateEdgeTag(ctx, e, &dbt.EdgeTag{Property: property})
+}
+
+func (mem *memRepository) FindEdgeTagById(ctx context.Context, id string) (*dbt.EdgeTag, error) {
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	tag, ok := mem.edge_tags[id]
+	if !ok {
+		return nil, ErrTagNotFound
+	}
+	return mem.resolveEdgeTag(tag), nil
+}
+
+func (mem *memRepository) FindEdgeTagsByContent(ctx context.Context, prop oam.Property, since time.Time) ([]*dbt.EdgeTag, error) {
+	if prop == nil {
+		return nil, errors.New("no property given")
+	}
+
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	var out []*dbt.EdgeTag
+	for _, tag := range mem.edge_tags {
+		if seen(tag.LastSeen, since) && sameProperty(tag.Property, prop) {
+			out = append(out, mem.resolveEdgeTag(tag))
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("zero tags found")
+	}
+	return out, nil
+}
+
+func (mem *memRepository) GetEdgeTags(ctx context.Context, e *dbt.Edge, since time.Time, names ...string) ([]*dbt.EdgeTag, error) {
+	if e == nil {
+		return nil, errors.New("no edge given")
+	}
+
+	mem.mutex.RLock()
+	defer mem.mutex.RUnlock()
+
+	var out []*dbt.EdgeTag
+	for _, tag := range mem.edge_tags {
+		if tag.edge != e.ID || !seen(tag.LastSeen, since) {
+			continue
+		}
+		if len(names) > 0 && !slices.Contains(names, tag.Property.Name()) {
+			continue
+		}
+		out = append(out, mem.resolveEdgeTag(tag))
+	}
+	if len(out) == 0 {
+		return nil, errors.New("zero tags found")
+	}
+	return out, nil
+}
+
+func (mem *memRepository) DeleteEdgeTag(ctx context.Context, id string) error {
+	mem.mutex.Lock()
+	defer mem.mutex.Unlock()
+
+	if _, ok := mem.edge_tags[id]; !ok {
+		return ErrTagNotFound
+	}
+	delete(mem.edge_tags, id)
+	return nil
+}
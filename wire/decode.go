@@ -0,0 +1,166 @@
+package wire
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FieldError reports an invalid field of a decoded object, along with
+// its path, such as relation.header.rr_type.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+var ErrUnknownField = errors.New("unknown field")
+
+// fieldError locates the errors of encoding/json decoding the field
+// at path.
+func fieldError(path string, err error) error {
+	var type_err *json.UnmarshalTypeError
+	if errors.As(err, &type_err) {
+		if type_err.Field != "" {
+			path = joinPath(path, type_err.Field)
+		}
+		err = fmt.Errorf("expected %s, got %s", type_err.Type, type_err.Value)
+	}
+	if path == "" {
+		return err
+	}
+	return &FieldError{Field: path, Err: err}
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// decodeContent decodes the content of an object, held in field, into
+// a new value of T. In strict mode, fields T does not have are rejected.
+func decodeContent(field string, raw json.RawMessage, T reflect.Type, strict bool) (any, error) {
+	if len(raw) == 0 {
+		return nil, &FieldError{Field: field, Err: errors.New("missing")}
+	}
+	if strict {
+		if err := unknownField(field, raw, T); err != nil {
+			return nil, err
+		}
+	}
+
+	content := reflect.New(T)
+	if err := json.Unmarshal(raw, content.Interface()); err != nil {
+		return nil, fieldError(field, err)
+	}
+	return content.Interface(), nil
+}
+
+var (
+	unmarshalerType     = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// unknownField returns an error for the first field of raw, at path,
+// that T does not have. Type mismatches are left to encoding/json.
+func unknownField(path string, raw json.RawMessage, T reflect.Type) error {
+	for T.Kind() == reflect.Pointer {
+		T = T.Elem()
+	}
+	// Types decoding themselves define what they accept.
+	if reflect.PointerTo(T).Implements(unmarshalerType) || reflect.PointerTo(T).Implements(textUnmarshalerType) {
+		return nil
+	}
+
+	switch T.Kind() {
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(raw, &fields) != nil {
+			return nil
+		}
+		known := jsonFields(T)
+		for _, name := range slices.Sorted(maps.Keys(fields)) {
+			value := fields[name]
+			field, ok := known[strings.ToLower(name)]
+			if !ok {
+				return &FieldError{Field: joinPath(path, name), Err: ErrUnknownField}
+			}
+			if err := unknownField(joinPath(path, name), value, field); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			return nil
+		}
+		for i, item := range items {
+			if err := unknownField(joinPath(path, fmt.Sprint(i)), item, T.Elem()); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		var values map[string]json.RawMessage
+		if json.Unmarshal(raw, &values) != nil {
+			return nil
+		}
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			value := values[key]
+			if err := unknownField(joinPath(path, key), value, T.Elem()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonFields maps the lowercased JSON names of the fields of a struct,
+// which encoding/json matches case-insensitively, to their types.
+func jsonFields(T reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < T.NumField(); i++ {
+		field := T.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		// Untagged embedded structs have their fields promoted.
+		embedded := field.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+		if field.Anonymous && name == "" && embedded.Kind() == reflect.Struct {
+			for promoted, promoted_type := range jsonFields(embedded) {
+				if _, ok := fields[promoted]; !ok {
+					fields[promoted] = promoted_type
+				}
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+	return fields
+}
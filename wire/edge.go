@@ -0,0 +1,82 @@
+package wire
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	dbt "github.com/owasp-amass/asset-db/types"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+type Edge struct {
+	ID         string           `json:"id,omitempty"`
+	CreatedAt  time.Time        `json:"created_at,omitzero"`
+	LastSeen   time.Time        `json:"last_seen,omitzero"`
+	Type       oam.RelationType `json:"type"`
+	Relation   oam.Relation     `json:"relation"`
+	FromEntity string           `json:"from_entity"`
+	ToEntity   string           `json:"to_entity"`
+}
+
+func (e Edge) JSON() []byte {
+	json_encoded, _ := json.Marshal(e)
+	return json_encoded
+}
+
+func (e Edge) ToStore(from_entity *dbt.Entity, to_entity *dbt.Entity) *dbt.Edge {
+	return &dbt.Edge{
+		ID:         e.ID,
+		CreatedAt:  e.CreatedAt,
+		LastSeen:   e.LastSeen,
+		Relation:   e.Relation,
+		FromEntity: from_entity,
+		ToEntity:   to_entity,
+	}
+}
+
+func EdgeFromStore(e *dbt.Edge) Edge {
+	return Edge{
+		ID:         e.ID,
+		CreatedAt:  e.CreatedAt,
+		LastSeen:   e.LastSeen,
+		Relation:   e.Relation,
+		Type:       e.Relation.RelationType(),
+		FromEntity: e.FromEntity.ID,
+		ToEntity:   e.ToEntity.ID,
+	}
+}
+
+func (a *Edge) UnmarshalJSON(data []byte) error {
+	return a.Decode(data, false)
+}
+
+// Decode decodes data like UnmarshalJSON. In strict mode, fields the
+// relation does not have are rejected. Errors locate the invalid field.
+func (a *Edge) Decode(data []byte, strict bool) error {
+	type Alias Edge
+	aux := &struct {
+		Relation json.RawMessage `json:"relation"`
+		*Alias
+	}{
+		Alias: (*Alias)(a),
+	}
+
+	if err := json.Unmarshal(data, aux); err != nil {
+		return fieldError("", err)
+	}
+
+	T, ok := RelationTypes[aux.Type]
+	if !ok {
+		return &FieldError{Field: "type", Err: errors.New(fmt.Sprintf("unsupported asset type: %s", aux.Type))}
+	}
+
+	rel, err := decodeContent("relation", aux.Relation, T, strict)
+	if err != nil {
+		return err
+	}
+
+	a.Relation = rel.(oam.Relation)
+	return nil
+}
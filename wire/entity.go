@@ -0,0 +1,76 @@
+package wire
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	dbt "github.com/owasp-amass/asset-db/types"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+type Entity struct {
+	ID        string        `json:"id,omitempty"`
+	CreatedAt time.Time     `json:"created_at,omitzero"`
+	LastSeen  time.Time     `json:"last_seen,omitzero"`
+	Asset     oam.Asset     `json:"asset"`
+	Type      oam.AssetType `json:"type"`
+}
+
+func (e Entity) JSON() []byte {
+	json_encoded, _ := json.Marshal(e)
+	return json_encoded
+}
+
+func (e Entity) ToStore() *dbt.Entity {
+	return &dbt.Entity{
+		ID:        e.ID,
+		CreatedAt: e.CreatedAt,
+		LastSeen:  e.LastSeen,
+		Asset:     e.Asset,
+	}
+}
+
+func EntityFromStore(e *dbt.Entity) Entity {
+	return Entity{
+		ID:        e.ID,
+		CreatedAt: e.CreatedAt,
+		LastSeen:  e.LastSeen,
+		Asset:     e.Asset,
+		Type:      e.Asset.AssetType(),
+	}
+}
+
+func (a *Entity) UnmarshalJSON(data []byte) error {
+	return a.Decode(data, false)
+}
+
+// Decode decodes data like UnmarshalJSON. In strict mode, fields the
+// asset does not have are rejected. Errors locate the invalid field.
+func (a *Entity) Decode(data []byte, strict bool) error {
+	type Alias Entity
+	aux := &struct {
+		Asset json.RawMessage `json:"asset"`
+		*Alias
+	}{
+		Alias: (*Alias)(a),
+	}
+
+	if err := json.Unmarshal(data, aux); err != nil {
+		return fieldError("", err)
+	}
+
+	T, ok := AssetTypes[aux.Type]
+	if !ok {
+		return &FieldError{Field: "type", Err: errors.New(fmt.Sprintf("unsupported asset type: %s", aux.Type))}
+	}
+
+	asset, err := decodeContent("asset", aux.Asset, T, strict)
+	if err != nil {
+		return err
+	}
+
+	a.Asset = asset.(oam.Asset)
+	return nil
+}
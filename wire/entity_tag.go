@@ -0,0 +1,79 @@
+package wire
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	dbt "github.com/owasp-amass/asset-db/types"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+type EntityTag struct {
+	ID        string           `json:"id,omitempty"`
+	CreatedAt time.Time        `json:"created_at,omitzero"`
+	LastSeen  time.Time        `json:"last_seen,omitzero"`
+	Type      oam.PropertyType `json:"type"`
+	Property  oam.Property     `json:"property"`
+	Entity    string           `json:"entity"`
+}
+
+func (e EntityTag) JSON() []byte {
+	json_encoded, _ := json.Marshal(e)
+	return json_encoded
+}
+
+func (e EntityTag) ToStore() *dbt.EntityTag {
+	return &dbt.EntityTag{
+		ID:        e.ID,
+		CreatedAt: e.CreatedAt,
+		LastSeen:  e.LastSeen,
+		Property:  e.Property,
+		Entity:    &dbt.Entity{ID: e.Entity},
+	}
+}
+
+func EntityTagFromStore(e *dbt.EntityTag) EntityTag {
+	return EntityTag{
+		ID:        e.ID,
+		CreatedAt: e.CreatedAt,
+		LastSeen:  e.LastSeen,
+		Property:  e.Property,
+		Type:      e.Property.PropertyType(),
+		Entity:    e.Entity.ID,
+	}
+}
+
+func (a *EntityTag) UnmarshalJSON(data []byte) error {
+	return a.Decode(data, false)
+}
+
+// Decode decodes data like UnmarshalJSON. In strict mode, fields the
+// property does not have are rejected. Errors locate the invalid field.
+func (a *EntityTag) Decode(data []byte, strict bool) error {
+	type Alias EntityTag
+	aux := &struct {
+		Property json.RawMessage `json:"property"`
+		*Alias
+	}{
+		Alias: (*Alias)(a),
+	}
+
+	if err := json.Unmarshal(data, aux); err != nil {
+		return fieldError("", err)
+	}
+
+	T, ok := PropertyTypes[aux.Type]
+	if !ok {
+		return &FieldError{Field: "type", Err: errors.New(fmt.Sprintf("unsupported asset type: %s", aux.Type))}
+	}
+
+	prop, err := decodeContent("property", aux.Property, T, strict)
+	if err != nil {
+		return err
+	}
+
+	a.Property = prop.(oam.Property)
+	return nil
+}
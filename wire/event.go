@@ -0,0 +1,62 @@
+package wire
+
+import (
+	"encoding/json"
+)
+
+// Serializable is implemented by every object the gateway sends.
+type Serializable interface {
+	JSON() []byte
+}
+
+type EventType string
+
+// The entity events keep the names of the asset store events they were
+// first published as, which existing subscribers match on. They are
+// spelled out, for clients not to depend on the asset store.
+const (
+	EntityCreated EventType = "EntityCreated"
+	EntityUpdated EventType = "EntityUpdated"
+	EntityDeleted EventType = "EntityDeleted"
+)
+
+const (
+	EntityTouched    EventType = "entity_touched"
+	EdgeCreated      EventType = "edge_created"
+	EdgeUpdated      EventType = "edge_updated"
+	EdgeTouched      EventType = "edge_touched"
+	EdgeDeleted      EventType = "edge_deleted"
+	EntityTagCreated EventType = "entity_tag_created"
+	EntityTagUpdated EventType = "entity_tag_updated"
+	EntityTagTouched EventType = "entity_tag_touched"
+	EntityTagDeleted EventType = "entity_tag_deleted"
+	EdgeTagCreated   EventType = "edge_tag_created"
+	EdgeTagUpdated   EventType = "edge_tag_updated"
+	EdgeTagTouched   EventType = "edge_tag_touched"
+	EdgeTagDeleted   EventType = "edge_tag_deleted"
+	JobProgress      EventType = "job_progress"
+	JobFinished      EventType = "job_finished"
+)
+
+// Change is a single JSON Patch (RFC 6902) operation turning the
+// previous version of an object into the current one. The data of
+// update events lists them under "changes".
+type Change struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// MarshalJSON leaves out the value of remove operations only, a null
+// value being meaningful to add and replace.
+func (c Change) MarshalJSON() ([]byte, error) {
+	if c.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{c.Op, c.Path})
+	}
+
+	type change Change
+	return json.Marshal(change(c))
+}
@@ -0,0 +1,121 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// decoder decodes an object and returns it along with its content.
+type decoder func(data []byte, strict bool) (Serializable, any, error)
+
+// unmarshal decodes obj through encoding/json, or strictly.
+func unmarshal(data []byte, obj interface{ Decode([]byte, bool) error }, strict bool) error {
+	if strict {
+		return obj.Decode(data, true)
+	}
+	return json.Unmarshal(data, obj)
+}
+
+func decodeEntity(data []byte, strict bool) (Serializable, any, error) {
+	var e Entity
+	err := unmarshal(data, &e, strict)
+	return e, e.Asset, err
+}
+
+func decodeEdge(data []byte, strict bool) (Serializable, any, error) {
+	var e Edge
+	err := unmarshal(data, &e, strict)
+	return e, e.Relation, err
+}
+
+func decodeEntityTag(data []byte, strict bool) (Serializable, any, error) {
+	var e EntityTag
+	err := unmarshal(data, &e, strict)
+	return e, e.Property, err
+}
+
+func decodeEdgeTag(data []byte, strict bool) (Serializable, any, error) {
+	var e EdgeTag
+	err := unmarshal(data, &e, strict)
+	return e, e.Property, err
+}
+
+// seeds returns an object of every type of a registry, with the zero
+// value of its content, followed by malformed objects.
+func seeds[K ~string](registry map[K]reflect.Type, field, extra string) [][]byte {
+	var out [][]byte
+	for name, T := range registry {
+		content, _ := json.Marshal(reflect.New(T).Interface())
+		out = append(out, fmt.Appendf(nil, `{"id":"1","type":%q,%q:%s%s}`, name, field, content, extra))
+	}
+	for name := range registry {
+		out = append(out,
+			fmt.Appendf(nil, `{"type":%q}`, name),
+			fmt.Appendf(nil, `{"type":%q,%q:null}`, name, field),
+			fmt.Appendf(nil, `{"type":%q,%q:[]}`, name, field),
+		)
+		break
+	}
+	return append(out,
+		[]byte(`null`),
+		[]byte(`{}`),
+		[]byte(`[]`),
+		[]byte(`{"type":"Unknown"}`),
+		[]byte(`{"type":null,"`+field+`":{}}`),
+	)
+}
+
+// checkDecoder checks that decoding never panics, that decoded objects
+// have a content, and that encoding them again is stable: decode, JSON()
+// and decode gives back the same JSON. What strict decoding accepts must
+// be accepted leniently too.
+func checkDecoder(t *testing.T, decode decoder, data []byte) {
+	_, _, strict_err := decode(data, true)
+	obj, content, err := decode(data, false)
+	if err != nil {
+		if strict_err == nil {
+			t.Fatalf("%s is only rejected leniently: %v", data, err)
+		}
+		return
+	}
+	if content == nil || reflect.ValueOf(content).IsNil() {
+		t.Fatalf("%s decoded without content", data)
+	}
+
+	encoded := obj.JSON()
+	again, _, err := decode(encoded, true)
+	if err != nil {
+		t.Fatalf("%s does not decode again: %v", encoded, err)
+	}
+	if reencoded := again.JSON(); !bytes.Equal(encoded, reencoded) {
+		t.Fatalf("round trip of %s is unstable:\n%s\n%s", data, encoded, reencoded)
+	}
+}
+
+func fuzzDecoder(f *testing.F, decode decoder, seeds [][]byte) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		checkDecoder(t, decode, data)
+	})
+}
+
+func FuzzEntityUnmarshalJSON(f *testing.F) {
+	fuzzDecoder(f, decodeEntity, seeds(AssetTypes, "asset", ""))
+}
+
+func FuzzEdgeUnmarshalJSON(f *testing.F) {
+	fuzzDecoder(f, decodeEdge, seeds(RelationTypes, "relation", `,"from_entity":"1","to_entity":"2"`))
+}
+
+func FuzzEntityTagUnmarshalJSON(f *testing.F) {
+	fuzzDecoder(f, decodeEntityTag, seeds(PropertyTypes, "property", `,"entity":"1"`))
+}
+
+func FuzzEdgeTagUnmarshalJSON(f *testing.F) {
+	fuzzDecoder(f, decodeEdgeTag, seeds(PropertyTypes, "property", `,"edge":"1"`))
+}
@@ -0,0 +1,93 @@
+package wire
+
+import (
+	"encoding/json"
+)
+
+// Cascade lists an entity along with the edges and tags that disappear
+// when it is deleted.
+type Cascade struct {
+	Entity     Entity      `json:"entity"`
+	Edges      []Edge      `json:"edges"`
+	EntityTags []EntityTag `json:"entity_tags"`
+	EdgeTags   []EdgeTag   `json:"edge_tags"`
+}
+
+func (c Cascade) JSON() []byte {
+	json_encoded, _ := json.Marshal(c)
+	return json_encoded
+}
+
+// Subgraph holds the neighbourhood of an entity. Truncated is set when
+// the limit on entities was reached before the neighbourhood was fully
+// walked.
+type Subgraph struct {
+	Root       string      `json:"root"`
+	Entities   []Entity    `json:"entities"`
+	Edges      []Edge      `json:"edges"`
+	EntityTags []EntityTag `json:"entity_tags,omitempty"`
+	EdgeTags   []EdgeTag   `json:"edge_tags,omitempty"`
+	Truncated  bool        `json:"truncated"`
+}
+
+func (g *Subgraph) JSON() []byte {
+	json_encoded, _ := json.Marshal(g)
+	return json_encoded
+}
+
+// The Subgraph is a GraphWriter of the gateway, so that it can be
+// filled by an export.
+
+func (g *Subgraph) Begin() error {
+	return nil
+}
+
+func (g *Subgraph) Entity(entity Entity, tags []EntityTag) error {
+	g.Entities = append(g.Entities, entity)
+	g.EntityTags = append(g.EntityTags, tags...)
+	return nil
+}
+
+func (g *Subgraph) Edge(edge Edge, tags []EdgeTag) error {
+	g.Edges = append(g.Edges, edge)
+	g.EdgeTags = append(g.EdgeTags, tags...)
+	return nil
+}
+
+func (g *Subgraph) End() error {
+	return nil
+}
+
+// Path is a sequence of entities where Edges[i] links Entities[i] and
+// Entities[i+1]. Edges are followed in both directions: FromEntity and
+// ToEntity tell which way each of them points.
+type Path struct {
+	Entities []Entity `json:"entities"`
+	Edges    []Edge   `json:"edges"`
+}
+
+type PathResult struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Length int    `json:"length"`
+	Paths  []Path `json:"paths"`
+}
+
+func (p PathResult) JSON() []byte {
+	json_encoded, _ := json.Marshal(p)
+	return json_encoded
+}
+
+// Record is a line of the NDJSON format, holding one object of the
+// graph.
+type Record struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+const (
+	EntityRecord    = "entity"
+	EdgeRecord      = "edge"
+	EntityTagRecord = "entity_tag"
+	EdgeTagRecord   = "edge_tag"
+)
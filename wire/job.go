@@ -0,0 +1,68 @@
+package wire
+
+import (
+	"encoding/json"
+	"time"
+
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+type JobState string
+
+const (
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+)
+
+// Job is the state of a long-running operation, as reported by /jobs.
+// Result depends on the kind of the job, and is only set once it is
+// finished.
+type Job struct {
+	ID         string          `json:"id"`
+	Kind       string          `json:"kind"`
+	State      JobState        `json:"state"`
+	Done       int             `json:"done"`
+	Total      int             `json:"total"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	FinishedAt time.Time       `json:"finished_at,omitzero"`
+}
+
+func (j Job) JSON() []byte {
+	json_encoded, _ := json.Marshal(j)
+	return json_encoded
+}
+
+// ImportResult is the result of an import job.
+type ImportResult struct {
+	Created  int      `json:"created"`
+	Updated  int      `json:"updated"`
+	Skipped  int      `json:"skipped"`
+	Rejected int      `json:"rejected"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// PruneFilter selects the objects deleted by a prune job. Filters are
+// combined: an object must match all of those that are set.
+type PruneFilter struct {
+	// Kind is one of "entity", "edge", "entity_tag" or "edge_tag".
+	Kind string `json:"kind"`
+	// AssetTypes restricts the search to entities of these types, or
+	// to edges and tags attached to them. All types when empty.
+	AssetTypes []oam.AssetType `json:"asset_types"`
+	// OlderThanDays matches objects not seen for that many days.
+	OlderThanDays int `json:"older_than_days"`
+	// Source matches entities and edges tagged with a SourceProperty
+	// of that name, and tags which are such a SourceProperty.
+	Source string `json:"source"`
+}
+
+// PruneResult is the result of a prune job.
+type PruneResult struct {
+	Matched int      `json:"matched"`
+	Deleted int      `json:"deleted"`
+	Errors  []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,66 @@
+// Package wire holds the objects exchanged with the Open Asset Gateway,
+// shared by the gateway and its clients. Entities, edges and tags carry
+// the type of their content, so that it is decoded into the matching
+// open-asset-model struct.
+package wire
+
+import (
+	"reflect"
+
+	oam "github.com/owasp-amass/open-asset-model"
+	oam_account "github.com/owasp-amass/open-asset-model/account"
+	oam_cert "github.com/owasp-amass/open-asset-model/certificate"
+	oam_contact "github.com/owasp-amass/open-asset-model/contact"
+	oam_dns "github.com/owasp-amass/open-asset-model/dns"
+	oam_file "github.com/owasp-amass/open-asset-model/file"
+	oam_financial "github.com/owasp-amass/open-asset-model/financial"
+	oam_general "github.com/owasp-amass/open-asset-model/general"
+	oam_net "github.com/owasp-amass/open-asset-model/network"
+	oam_org "github.com/owasp-amass/open-asset-model/org"
+	oam_people "github.com/owasp-amass/open-asset-model/people"
+	oam_pf "github.com/owasp-amass/open-asset-model/platform"
+	oam_reg "github.com/owasp-amass/open-asset-model/registration"
+	oam_url "github.com/owasp-amass/open-asset-model/url"
+)
+
+// AssetTypes maps the type of an entity to the struct of its asset.
+var AssetTypes = map[oam.AssetType]reflect.Type{
+	oam.Account:          reflect.TypeOf(oam_account.Account{}),
+	oam.AutnumRecord:     reflect.TypeOf(oam_reg.AutnumRecord{}),
+	oam.AutonomousSystem: reflect.TypeOf(oam_net.AutonomousSystem{}),
+	oam.ContactRecord:    reflect.TypeOf(oam_contact.ContactRecord{}),
+	oam.DomainRecord:     reflect.TypeOf(oam_reg.DomainRecord{}),
+	oam.File:             reflect.TypeOf(oam_file.File{}),
+	oam.FQDN:             reflect.TypeOf(oam_dns.FQDN{}),
+	oam.FundsTransfer:    reflect.TypeOf(oam_financial.FundsTransfer{}),
+	oam.Identifier:       reflect.TypeOf(oam_general.Identifier{}),
+	oam.IPAddress:        reflect.TypeOf(oam_net.IPAddress{}),
+	oam.IPNetRecord:      reflect.TypeOf(oam_reg.IPNetRecord{}),
+	oam.Location:         reflect.TypeOf(oam_contact.Location{}),
+	oam.Netblock:         reflect.TypeOf(oam_net.Netblock{}),
+	oam.Organization:     reflect.TypeOf(oam_org.Organization{}),
+	oam.Person:           reflect.TypeOf(oam_people.Person{}),
+	oam.Phone:            reflect.TypeOf(oam_contact.Phone{}),
+	oam.Product:          reflect.TypeOf(oam_pf.Product{}),
+	oam.ProductRelease:   reflect.TypeOf(oam_pf.ProductRelease{}),
+	oam.Service:          reflect.TypeOf(oam_pf.Service{}),
+	oam.TLSCertificate:   reflect.TypeOf(oam_cert.TLSCertificate{}),
+	oam.URL:              reflect.TypeOf(oam_url.URL{}),
+}
+
+// RelationTypes maps the type of an edge to the struct of its relation.
+var RelationTypes = map[oam.RelationType]reflect.Type{
+	oam.BasicDNSRelation: reflect.TypeOf(oam_dns.BasicDNSRelation{}),
+	oam.PortRelation:     reflect.TypeOf(oam_general.PortRelation{}),
+	oam.PrefDNSRelation:  reflect.TypeOf(oam_dns.PrefDNSRelation{}),
+	oam.SimpleRelation:   reflect.TypeOf(oam_general.SimpleRelation{}),
+	oam.SRVDNSRelation:   reflect.TypeOf(oam_dns.SRVDNSRelation{}),
+}
+
+// PropertyTypes maps the type of a tag to the struct of its property.
+var PropertyTypes = map[oam.PropertyType]reflect.Type{
+	oam.DNSRecordProperty: reflect.TypeOf(oam_dns.DNSRecordProperty{}),
+	oam.SimpleProperty:    reflect.TypeOf(oam_general.SimpleProperty{}),
+	oam.SourceProperty:    reflect.TypeOf(oam_general.SourceProperty{}),
+	oam.VulnProperty:      reflect.TypeOf(oam_pf.VulnProperty{}),
+}
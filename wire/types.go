@@ -0,0 +1,22 @@
+package wire
+
+import (
+	"encoding/json"
+)
+
+// Schema is a JSON Schema.
+type Schema map[string]any
+
+// TypeCatalog lists the types the gateway accepts with the JSON Schema
+// of their content, keyed by the value of the "type" field of entities,
+// edges and tags.
+type TypeCatalog struct {
+	Assets     map[string]Schema `json:"assets"`
+	Relations  map[string]Schema `json:"relations"`
+	Properties map[string]Schema `json:"properties"`
+}
+
+func (c TypeCatalog) JSON() []byte {
+	json_encoded, _ := json.Marshal(c)
+	return json_encoded
+}